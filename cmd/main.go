@@ -1,6 +1,10 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
+
 	_ "vk-internship/docs"
 	"vk-internship/internal/app"
 )
@@ -11,5 +15,38 @@ import (
 // @in header
 // @name Authorization
 func main() {
-	app.Run()
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML or TOML config file, environment variables take precedence")
+	printConfig := flag.Bool("print-config", false, "print the effective config with secrets redacted and exit")
+	flag.Parse()
+
+	if *printConfig {
+		app.PrintConfig(*configPath)
+		return
+	}
+
+	if args := flag.Args(); len(args) > 0 {
+		switch args[0] {
+		case "migrate":
+			if err := app.Migrate(*configPath, args[1:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		case "admin":
+			if err := app.Admin(*configPath, args[1:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		case "seed":
+			if err := app.Seed(*configPath, args[1:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		default:
+			fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+			os.Exit(2)
+		}
+		return
+	}
+
+	app.Run(*configPath)
 }
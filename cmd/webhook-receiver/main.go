@@ -0,0 +1,78 @@
+// Command webhook-receiver is a local endpoint for testing outgoing webhooks.
+// It verifies signatures and prints every delivery it receives.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"vk-internship/internal/webhook"
+)
+
+func main() {
+	addr := flag.String("addr", ":9090", "listen address")
+	secret := flag.String("secret", "", "subscription secret used to verify signatures")
+	fail := flag.Int("fail", 0, "respond with 500 to the first N deliveries to exercise retries")
+	flag.Parse()
+
+	received := 0
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+
+		received++
+
+		timestamp, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		signature := r.Header.Get(webhook.HeaderSignature)
+
+		status := "not checked"
+		if *secret != "" {
+			status = "invalid"
+			if webhook.Verify(*secret, timestamp, body, signature) {
+				status = "valid"
+			}
+		}
+
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, body, "", "  "); err != nil {
+			pretty.Write(body)
+		}
+
+		fmt.Printf("[%s] #%d %s event=%s event_id=%s delivery=%s signature=%s\n%s\n\n",
+			time.Now().Format(time.TimeOnly),
+			received,
+			r.URL.Path,
+			r.Header.Get(webhook.HeaderEvent),
+			r.Header.Get(webhook.HeaderEventID),
+			r.Header.Get(webhook.HeaderID),
+			status,
+			pretty.String(),
+		)
+
+		if status == "invalid" {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		if received <= *fail {
+			http.Error(w, "simulated failure", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	log.Printf("webhook receiver listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
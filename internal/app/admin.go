@@ -0,0 +1,471 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"vk-internship/internal/cache"
+	"vk-internship/internal/cache/redis"
+	"vk-internship/internal/config"
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+	"vk-internship/internal/database/postgres"
+	"vk-internship/internal/logger"
+	zerologger "vk-internship/internal/logger/zerolog"
+)
+
+const adminUsage = `usage: marketplace [--config path] admin [--output table|json] <command>
+
+commands:
+  users list [--page N] [--page-size N]           list users with their ad count
+  users search <query> [--page N] [--page-size N] find users by part of the username
+  users disable <username>                        forbid the user to log in
+  users enable <username>                         allow a disabled user to log in again
+  users promote <username>                        make the user a moderator
+  users demote <username>                         revoke the moderator role
+  ads reassign <ad-id> <username>                 move an ad to another user
+  ads delete <ad-id>                              delete an ad of any user
+  reviews list <username> [--page N] [--page-size N]
+                                                  list reviews of a seller
+  reviews remove <review-id> <reason>             remove an abusive review from the seller's rating
+  cache flush                                     drop the cached feed
+  cache rebuild                                   load the latest ads into the feed cache`
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// toolStorage is the storage used by commands that run next to the server.
+// The cache is nil if it is unavailable, commands that only refresh it carry
+// on without it.
+type toolStorage struct {
+	log      logger.Logger
+	db       database.Database
+	cache    cache.Cache
+	feedSize int
+}
+
+// openToolStorage connects to the database and the cache. Logs go to stderr,
+// stdout is reserved for the command output.
+func openToolStorage(configPath string) (*toolStorage, error) {
+	if err := config.LoadFile(configPath); err != nil {
+		return nil, err
+	}
+
+	loggercfg, err := config.LoadLoggerConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	storagecfg, err := config.LoadStorageConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := errors.Join(loggercfg.Validate(), storagecfg.Validate()); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	s := &toolStorage{log: zerologger.NewWriter(loggercfg, os.Stderr)}
+
+	switch storagecfg.DBType {
+	case "postgres":
+		postgrescfg, err := config.LoadPostgresConfig()
+		if err != nil {
+			return nil, err
+		}
+		if err := postgrescfg.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid configuration:\n%w", err)
+		}
+
+		pg, err := postgres.New(postgrescfg, s.log)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := preparePostgresSchema(pg, false); err != nil {
+			pg.Close()
+			return nil, err
+		}
+
+		s.db = pg
+
+	default:
+		return nil, fmt.Errorf("database type [%s] is not supported", storagecfg.DBType)
+	}
+
+	switch storagecfg.CacheType {
+	case "redis":
+		rediscfg, err := config.LoadRedisConfig()
+		if err == nil {
+			err = rediscfg.Validate()
+		}
+		if err != nil {
+			s.db.Close()
+			return nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), rediscfg.DialTimeout)
+		defer cancel()
+
+		rc, err := redis.New(ctx, rediscfg, s.log)
+		if err != nil {
+			s.log.Warnf("cache is unavailable", map[string]interface{}{"error": err.Error()})
+			break
+		}
+
+		s.cache = rc
+		s.feedSize = rediscfg.MaxFeedItems
+
+	default:
+		s.db.Close()
+		return nil, fmt.Errorf("cache type [%s] is not supported", storagecfg.CacheType)
+	}
+
+	return s, nil
+}
+
+func (s *toolStorage) Close() {
+	if s.cache != nil {
+		s.cache.Close()
+	}
+	s.db.Close()
+}
+
+// rebuildFeed replaces the cached feed with the latest ads from the database.
+func (s *toolStorage) rebuildFeed(ctx context.Context) (int, error) {
+	if s.cache == nil {
+		return 0, errors.New("cache is unavailable")
+	}
+
+	ads, _, err := s.db.GetAds(ctx, model.AdFilter{SortBy: "created_at", Order: "desc"}, 1, s.feedSize)
+	if err != nil {
+		return 0, err
+	}
+
+	feed := make([]model.Advertisement, 0, len(ads))
+	for _, ad := range ads {
+		feed = append(feed, *ad)
+	}
+
+	return len(feed), s.cache.SetFeed(ctx, feed)
+}
+
+// refreshFeed rebuilds the cached feed after an ad changed behind the
+// server's back, so the feed does not keep showing it.
+func (s *toolStorage) refreshFeed(ctx context.Context) {
+	if _, err := s.rebuildFeed(ctx); err != nil {
+		s.log.Warnf("failed to rebuild feed, it may show stale ads until it expires", map[string]interface{}{"error": err.Error()})
+	}
+}
+
+// dropFeed invalidates the cached feed. New ads start a fresh feed until it
+// is rebuilt.
+func (s *toolStorage) dropFeed(ctx context.Context) {
+	if s.cache == nil {
+		s.log.Warn("cache is unavailable, the feed may show stale ads until it expires")
+		return
+	}
+
+	if err := s.cache.InvalidateFeed(ctx); err != nil {
+		s.log.Warnf("failed to invalidate feed", map[string]interface{}{"error": err.Error()})
+	}
+}
+
+// parseArgs parses flags placed anywhere between the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// Admin runs an admin subcommand against the configured storage.
+func Admin(configPath string, args []string) error {
+	fs := flag.NewFlagSet("admin", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	output := fs.String("output", outputTable, "")
+	fs.StringVar(output, "o", outputTable, "")
+	page := fs.Int("page", 1, "")
+	pageSize := fs.Int("page-size", 50, "")
+
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return fmt.Errorf("%w\n\n%s", err, adminUsage)
+	}
+
+	if *output != outputTable && *output != outputJSON {
+		return fmt.Errorf("unknown output format %q, expected table or json", *output)
+	}
+
+	if len(args) < 2 {
+		return errors.New(adminUsage)
+	}
+
+	command := args[0] + " " + args[1]
+	args = args[2:]
+
+	required := map[string]int{
+		"users list":     0,
+		"users search":   1,
+		"users disable":  1,
+		"users enable":   1,
+		"users promote":  1,
+		"users demote":   1,
+		"ads reassign":   2,
+		"ads delete":     1,
+		"reviews list":   1,
+		"reviews remove": 2,
+		"cache flush":    0,
+		"cache rebuild":  0,
+	}
+
+	n, ok := required[command]
+	if !ok {
+		return fmt.Errorf("unknown admin command %q\n\n%s", command, adminUsage)
+	}
+	if len(args) != n {
+		return fmt.Errorf("%s expects %d argument(s)\n\n%s", command, n, adminUsage)
+	}
+
+	s, err := openToolStorage(configPath)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	w := adminWriter{w: os.Stdout, format: *output}
+
+	switch command {
+	case "users list", "users search":
+		var query string
+		if len(args) > 0 {
+			query = args[0]
+		}
+
+		users, total, err := s.db.ListUsers(ctx, query, *page, *pageSize)
+		if err != nil {
+			return err
+		}
+		return w.users(users, total)
+
+	case "users disable", "users enable":
+		user, err := s.db.SetUserDisabled(ctx, args[0], command == "users disable")
+		if err != nil {
+			return err
+		}
+		return w.users([]*model.User{user}, 1)
+
+	case "users promote", "users demote":
+		user, err := s.db.SetUserModerator(ctx, args[0], command == "users promote")
+		if err != nil {
+			return err
+		}
+		return w.users([]*model.User{user}, 1)
+
+	case "ads reassign":
+		ad, err := s.db.ReassignAd(ctx, args[0], args[1])
+		if err != nil {
+			return err
+		}
+		s.refreshFeed(ctx)
+		return w.ad(ad)
+
+	case "ads delete":
+		ad, err := s.db.DeleteAdByID(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		s.refreshFeed(ctx)
+		return w.ad(ad)
+
+	case "reviews list":
+		seller, err := s.db.GetUserProfile(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		reviews, total, err := s.db.GetUserReviews(ctx, seller.ID, *page, *pageSize)
+		if err != nil {
+			return err
+		}
+		return w.reviews(reviews, total)
+
+	case "reviews remove":
+		review, err := s.db.RemoveReview(ctx, args[0], "", args[1])
+		if err != nil {
+			return err
+		}
+		return w.reviews([]*model.Review{review}, 1)
+
+	case "cache flush":
+		if s.cache == nil {
+			return errors.New("cache is unavailable")
+		}
+		if err := s.cache.InvalidateFeed(ctx); err != nil {
+			return err
+		}
+		return w.feed("flushed", 0)
+
+	default:
+		items, err := s.rebuildFeed(ctx)
+		if err != nil {
+			return err
+		}
+		return w.feed("rebuilt", items)
+	}
+}
+
+type adminUser struct {
+	ID         string     `json:"id"`
+	Username   string     `json:"username"`
+	CreatedAt  time.Time  `json:"created_at"`
+	DisabledAt *time.Time `json:"disabled_at"`
+	Moderator  bool       `json:"moderator"`
+	AdsCount   int        `json:"ads_count"`
+}
+
+type adminAd struct {
+	ID             string    `json:"id"`
+	AuthorID       string    `json:"author_id"`
+	AuthorUsername string    `json:"author_username"`
+	Caption        string    `json:"caption"`
+	Price          float64   `json:"price"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type adminReview struct {
+	ID             string    `json:"id"`
+	AdID           *string   `json:"ad_id"`
+	SellerUsername string    `json:"seller_username"`
+	AuthorUsername string    `json:"author_username"`
+	Rating         int       `json:"rating"`
+	Text           string    `json:"text"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type adminWriter struct {
+	w      io.Writer
+	format string
+}
+
+func (a adminWriter) json(v interface{}) error {
+	enc := json.NewEncoder(a.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (a adminWriter) users(users []*model.User, total int) error {
+	rows := make([]adminUser, 0, len(users))
+	for _, u := range users {
+		rows = append(rows, adminUser{
+			ID:         u.ID,
+			Username:   u.Username,
+			CreatedAt:  u.CreatedAt,
+			DisabledAt: u.DisabledAt,
+			Moderator:  u.Moderator,
+			AdsCount:   u.AdsCount,
+		})
+	}
+
+	if a.format == outputJSON {
+		return a.json(map[string]interface{}{"users": rows, "total": total})
+	}
+
+	tw := tabwriter.NewWriter(a.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tUSERNAME\tCREATED\tDISABLED\tMODERATOR\tADS")
+	for _, u := range rows {
+		disabled := "-"
+		if u.DisabledAt != nil {
+			disabled = u.DisabledAt.Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%t\t%d\n", u.ID, u.Username, u.CreatedAt.Format(time.RFC3339), disabled, u.Moderator, u.AdsCount)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(a.w, "\n%d of %d users\n", len(rows), total)
+	return err
+}
+
+func (a adminWriter) ad(ad *model.Advertisement) error {
+	row := adminAd{
+		ID:             ad.ID,
+		AuthorID:       ad.AuthorID,
+		AuthorUsername: ad.AuthorUsername,
+		Caption:        ad.Caption,
+		Price:          float64(ad.Price) / 100,
+		CreatedAt:      ad.CreatedAt,
+	}
+
+	if a.format == outputJSON {
+		return a.json(row)
+	}
+
+	tw := tabwriter.NewWriter(a.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tAUTHOR\tCAPTION\tPRICE\tCREATED")
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%.2f\t%s\n", row.ID, row.AuthorUsername, row.Caption, row.Price, row.CreatedAt.Format(time.RFC3339))
+	return tw.Flush()
+}
+
+func (a adminWriter) reviews(reviews []*model.Review, total int) error {
+	rows := make([]adminReview, 0, len(reviews))
+	for _, r := range reviews {
+		rows = append(rows, adminReview{
+			ID:             r.ID,
+			AdID:           r.AdID,
+			SellerUsername: r.SellerUsername,
+			AuthorUsername: r.AuthorUsername,
+			Rating:         r.Rating,
+			Text:           r.Body,
+			CreatedAt:      r.CreatedAt,
+		})
+	}
+
+	if a.format == outputJSON {
+		return a.json(map[string]interface{}{"reviews": rows, "total": total})
+	}
+
+	tw := tabwriter.NewWriter(a.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSELLER\tAUTHOR\tRATING\tCREATED\tTEXT")
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n", r.ID, r.SellerUsername, r.AuthorUsername, r.Rating, r.CreatedAt.Format(time.RFC3339), r.Text)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(a.w, "\n%d of %d reviews\n", len(rows), total)
+	return err
+}
+
+func (a adminWriter) feed(action string, items int) error {
+	if a.format == outputJSON {
+		return a.json(map[string]interface{}{"feed": action, "items": items})
+	}
+
+	_, err := fmt.Fprintf(a.w, "feed %s, %d item(s)\n", action, items)
+	return err
+}
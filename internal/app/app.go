@@ -9,39 +9,52 @@ import (
 	"syscall"
 	"time"
 
+	"vk-internship/internal/broker"
 	"vk-internship/internal/cache"
+	"vk-internship/internal/cache/breaker"
 	"vk-internship/internal/config"
 	"vk-internship/internal/database"
+	"vk-internship/internal/health"
+	"vk-internship/internal/idempotency"
+	"vk-internship/internal/importer"
 	"vk-internship/internal/logger"
+	"vk-internship/internal/matcher"
+	"vk-internship/internal/offer"
+	"vk-internship/internal/ratelimit"
 	"vk-internship/internal/server"
+	"vk-internship/internal/stats"
+	"vk-internship/internal/webhook"
 )
 
 type App struct {
-	Server   *server.Server
-	Database database.Database
-	Cache    cache.Cache
-	Logger   logger.Logger
+	Server       *server.Server
+	Database     database.Database
+	Cache        cache.Cache
+	CacheBreaker *breaker.Breaker
+	Logger       logger.Logger
+	Flusher      *stats.Flusher
+	Broker       *broker.Broker
+	Matcher      *matcher.Matcher
+	Webhooks     *webhook.Dispatcher
+	Importer     *importer.Importer
+	Health       *health.Checker
+	RateLimiter  *ratelimit.Limiter
+
+	IdempotencyCleaner *idempotency.Cleaner
+	OfferExpirer       *offer.Expirer
+
+	shutdownTracing func(context.Context) error
 }
 
-func Run() {
+func Run(configPath string) {
 	var app App
 
-	loggercfg, err := config.LoadLoggerConfig()
+	cfg, err := config.Load(configPath)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	servercfg, err := config.LoadServerConfig()
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	storagecfg, err := config.LoadStorageConfig()
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	err = app.registerComponents(loggercfg, servercfg, storagecfg)
+	err = app.registerComponents(cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -51,15 +64,88 @@ func Run() {
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+
+	go func() {
+		current := cfg
+		for range reload {
+			current = app.reload(configPath, current)
+		}
+	}()
+
 	go func() {
 		if err := app.Server.Start(); err != nil && err != http.ErrServerClosed {
 			app.Logger.Error(err, "failed to start server")
 		}
 	}()
 
+	breakerCtx, stopBreaker := context.WithCancel(context.Background())
+	breakerDone := make(chan struct{})
+
+	go func() {
+		app.CacheBreaker.Run(breakerCtx)
+		close(breakerDone)
+	}()
+
+	flusherCtx, stopFlusher := context.WithCancel(context.Background())
+	flusherDone := make(chan struct{})
+
+	go func() {
+		app.Flusher.Run(flusherCtx)
+		close(flusherDone)
+	}()
+
+	brokerCtx, stopBroker := context.WithCancel(context.Background())
+	brokerDone := make(chan struct{})
+
+	go func() {
+		app.Broker.Run(brokerCtx)
+		close(brokerDone)
+	}()
+
+	cleanerCtx, stopCleaner := context.WithCancel(context.Background())
+	cleanerDone := make(chan struct{})
+
+	go func() {
+		app.IdempotencyCleaner.Run(cleanerCtx)
+		close(cleanerDone)
+	}()
+
+	webhooksCtx, stopWebhooks := context.WithCancel(context.Background())
+	webhooksDone := make(chan struct{})
+
+	go func() {
+		app.Webhooks.Run(webhooksCtx)
+		close(webhooksDone)
+	}()
+
+	expirerCtx, stopExpirer := context.WithCancel(context.Background())
+	expirerDone := make(chan struct{})
+
+	go func() {
+		app.OfferExpirer.Run(expirerCtx)
+		close(expirerDone)
+	}()
+
+	importerCtx, stopImporter := context.WithCancel(context.Background())
+	importerDone := make(chan struct{})
+
+	go func() {
+		app.Importer.Run(importerCtx)
+		close(importerDone)
+	}()
+
 	<-done
 	app.Logger.Info("server is shutting down...")
 
+	app.Health.SetNotReady()
+	if cfg.Health.ShutdownDelay > 0 {
+		app.Logger.Infof("waiting for load balancers to notice", map[string]interface{}{"delay": cfg.Health.ShutdownDelay.String()})
+		time.Sleep(cfg.Health.ShutdownDelay)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -67,6 +153,31 @@ func Run() {
 		app.Logger.Error(err, "failed to shutdown server")
 	}
 
+	stopBroker()
+	<-brokerDone
+
+	stopImporter()
+	<-importerDone
+
+	stopWebhooks()
+	<-webhooksDone
+
+	stopExpirer()
+	<-expirerDone
+
+	stopCleaner()
+	<-cleanerDone
+
+	stopFlusher()
+	<-flusherDone
+
+	stopBreaker()
+	<-breakerDone
+
+	if err := app.shutdownTracing(ctx); err != nil {
+		app.Logger.Error(err, "failed to flush traces")
+	}
+
 	app.Database.Close()
 
 	if err := app.Cache.Close(); err != nil {
@@ -76,23 +187,54 @@ func Run() {
 	app.Logger.Info("server stopped gracefully")
 }
 
-func (app *App) registerComponents(loggercfg *config.LoggerConfig, servercfg *config.ServerConfig, storagecfg *config.StorageConfig) error {
-	err := app.registerLogger(loggercfg)
+func (app *App) registerComponents(cfg *config.Config) error {
+	err := app.registerLogger(cfg.Logger)
 	if err != nil {
 		return err
 	}
 
-	err = app.registerDatabase(storagecfg.DBType, app.Logger)
+	err = app.registerTracing(cfg.Tracing, app.Logger)
 	if err != nil {
 		return err
 	}
 
-	err = app.registerCache(storagecfg.CacheType, app.Logger)
+	err = app.registerDatabase(cfg.Storage.DBType, cfg.Postgres, cfg.Storage.AutoMigrate, app.Logger)
 	if err != nil {
 		return err
 	}
 
-	app.registerServer(servercfg, app.Logger)
+	err = app.registerCache(cfg.Storage.CacheType, cfg.Redis, cfg.CacheBreaker, app.Logger)
+	if err != nil {
+		return err
+	}
+
+	app.registerStats(cfg.Stats, app.Logger)
+	app.registerBroker(cfg.Broker, app.Logger)
+	app.registerMatcher(app.Logger)
+	app.registerWebhooks(cfg.Webhook, app.Logger)
+	app.registerHealth(cfg.Health, app.Logger)
+
+	err = app.registerRateLimiter(cfg.RateLimit, app.Logger)
+	if err != nil {
+		return err
+	}
+
+	app.registerIdempotency(cfg.Idempotency, app.Logger)
+	app.registerImporter(cfg.Import, app.Logger)
+	app.registerOffers(cfg.Offer, app.Logger)
+	app.registerServer(cfg.Server, cfg.Stats, cfg.Broker, cfg.Idempotency, cfg.Import, cfg.Offer, cfg.Moderation, cfg.Webhook, app.Logger)
 
 	return nil
 }
+
+// PrintConfig prints the effective configuration with secrets redacted.
+func PrintConfig(configPath string) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := config.Print(os.Stdout, cfg); err != nil {
+		log.Fatal(err)
+	}
+}
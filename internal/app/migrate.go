@@ -0,0 +1,160 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"vk-internship/internal/config"
+	"vk-internship/internal/database/postgres"
+	zerologger "vk-internship/internal/logger/zerolog"
+	"vk-internship/migrations"
+)
+
+const migrateUsage = `usage: marketplace [--config path] migrate <command>
+
+commands:
+  up           apply all pending migrations
+  down [N]     roll back the last N migrations (default 1)
+  goto V       migrate up or down to version V (0 rolls back everything)
+  force V      set version V and clear the dirty flag without running anything
+  status       show the current version and every migration`
+
+func postgresMigrator(pg *postgres.PostgresDB) (*postgres.Migrator, error) {
+	source, err := fs.Sub(migrations.Postgres, "postgres")
+	if err != nil {
+		return nil, err
+	}
+
+	return pg.Migrator(source)
+}
+
+// preparePostgresSchema applies pending migrations if enabled and refuses to
+// continue unless the schema matches the embedded migrations.
+func preparePostgresSchema(pg *postgres.PostgresDB, autoMigrate bool) error {
+	migrator, err := postgresMigrator(pg)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	if autoMigrate {
+		if err := migrator.Up(ctx); err != nil {
+			return fmt.Errorf("auto migration failed: %w", err)
+		}
+	}
+
+	return migrator.Check(ctx)
+}
+
+// Migrate runs a migrate subcommand. Only the logger and Postgres settings
+// are loaded, so it works without the rest of the configuration.
+func Migrate(configPath string, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	if err := config.LoadFile(configPath); err != nil {
+		return err
+	}
+
+	loggercfg, err := config.LoadLoggerConfig()
+	if err != nil {
+		return err
+	}
+
+	postgrescfg, err := config.LoadPostgresConfig()
+	if err != nil {
+		return err
+	}
+
+	if err := errors.Join(loggercfg.Validate(), postgrescfg.Validate()); err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	log := zerologger.New(loggercfg)
+
+	pg, err := postgres.New(postgrescfg, log)
+	if err != nil {
+		return err
+	}
+	defer pg.Close()
+
+	migrator, err := postgresMigrator(pg)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		return migrator.Down(ctx, steps)
+
+	case "goto", "force":
+		if len(args) < 2 {
+			return fmt.Errorf("%s requires a version\n\n%s", args[0], migrateUsage)
+		}
+
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+
+		if args[0] == "force" {
+			return migrator.Force(ctx, version)
+		}
+		return migrator.Goto(ctx, version)
+
+	case "status":
+		return printMigrationStatus(ctx, os.Stdout, migrator)
+
+	default:
+		return fmt.Errorf("unknown migrate command %q\n\n%s", args[0], migrateUsage)
+	}
+}
+
+func printMigrationStatus(ctx context.Context, w io.Writer, migrator *postgres.Migrator) error {
+	version, dirty, statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "version: %d (latest %d)\n", version, migrator.Latest())
+	if dirty {
+		fmt.Fprintf(w, "dirty: migration %d failed, fix it and run `migrate force %d`\n", version, version)
+	}
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tSTATUS")
+	for _, s := range statuses {
+		state := "pending"
+		if s.Applied {
+			state = "applied"
+		}
+		if dirty && s.Version == version {
+			state = "dirty"
+		}
+
+		fmt.Fprintf(tw, "%06d\t%s\t%s\n", s.Version, s.Name, state)
+	}
+
+	return tw.Flush()
+}
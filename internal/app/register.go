@@ -1,19 +1,31 @@
 package app
 
 import (
+	"context"
 	"fmt"
 
+	"vk-internship/internal/broker"
 	"vk-internship/internal/cache"
+	"vk-internship/internal/cache/breaker"
 	"vk-internship/internal/cache/redis"
 	"vk-internship/internal/config"
 	"vk-internship/internal/database"
 	"vk-internship/internal/database/postgres"
+	"vk-internship/internal/health"
+	"vk-internship/internal/idempotency"
+	"vk-internship/internal/importer"
 	"vk-internship/internal/logger"
 	zerologger "vk-internship/internal/logger/zerolog"
+	"vk-internship/internal/matcher"
+	"vk-internship/internal/offer"
+	"vk-internship/internal/ratelimit"
 	"vk-internship/internal/server"
+	"vk-internship/internal/stats"
+	"vk-internship/internal/tracing"
+	"vk-internship/internal/webhook"
 )
 
-func (app *App) registerDatabase(dbType string, log logger.Logger) error {
+func (app *App) registerDatabase(dbType string, postgrescfg *config.PostgresConfig, autoMigrate bool, log logger.Logger) error {
 	var (
 		db  database.Database
 		err error
@@ -21,16 +33,18 @@ func (app *App) registerDatabase(dbType string, log logger.Logger) error {
 
 	switch dbType {
 	case "postgres":
-		cfg, err := config.LoadPostgresConfig()
+		pg, err := postgres.New(postgrescfg, log)
 		if err != nil {
 			return err
 		}
 
-		db, err = postgres.New(cfg, log)
-		if err != nil {
+		if err := preparePostgresSchema(pg, autoMigrate); err != nil {
+			pg.Close()
 			return err
 		}
 
+		db = pg
+
 	default:
 		return fmt.Errorf("database type [%s] is not supported", dbType)
 	}
@@ -39,30 +53,31 @@ func (app *App) registerDatabase(dbType string, log logger.Logger) error {
 	return err
 }
 
-func (app *App) registerCache(cacheType string, log logger.Logger) error {
-	var (
-		cache cache.Cache
-		err   error
-	)
+func (app *App) registerCache(cacheType string, rediscfg *config.RedisConfig, breakercfg *config.CacheBreakerConfig, log logger.Logger) error {
+	var connect breaker.ConnectFunc
 
 	switch cacheType {
 	case "redis":
-		cfg, err := config.LoadRedisConfig()
-		if err != nil {
-			return err
-		}
-
-		cache, err = redis.New(cfg, log)
-		if err != nil {
-			return err
+		connect = func(ctx context.Context) (cache.Cache, error) {
+			return redis.New(ctx, rediscfg, log)
 		}
 
 	default:
 		return fmt.Errorf("cache type [%s] is not supported", cacheType)
 	}
 
-	app.Cache = cache
-	return err
+	cb := breaker.New(breakercfg, connect, log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), breakercfg.ProbeTimeout)
+	defer cancel()
+
+	if err := cb.Connect(ctx); err != nil {
+		log.Warnf("cache is unavailable, starting without it", map[string]interface{}{"error": err.Error()})
+	}
+
+	app.Cache = cb
+	app.CacheBreaker = cb
+	return nil
 }
 
 func (app *App) registerLogger(cfg *config.LoggerConfig) error {
@@ -82,8 +97,69 @@ func (app *App) registerLogger(cfg *config.LoggerConfig) error {
 	return err
 }
 
-func (app *App) registerServer(servercfg *config.ServerConfig, log logger.Logger) {
-	router := server.NewRouter(servercfg, log, app.Database, app.Cache)
+func (app *App) registerServer(servercfg *config.ServerConfig, statscfg *config.StatsConfig, brokercfg *config.BrokerConfig, idempotencycfg *config.IdempotencyConfig, importcfg *config.ImportConfig, offercfg *config.OfferConfig, moderationcfg *config.ModerationConfig, webhookcfg *config.WebhookConfig, log logger.Logger) {
+	router := server.NewRouter(servercfg, statscfg, brokercfg, idempotencycfg, importcfg, offercfg, moderationcfg, webhookcfg, log, app.Database, app.Cache, app.Broker, app.Matcher, app.Health, app.RateLimiter, app.Importer)
 	srv := server.New(servercfg, router, log)
+	srv.RegisterOnShutdown(app.Broker.Close)
 	app.Server = srv
 }
+
+func (app *App) registerStats(statscfg *config.StatsConfig, log logger.Logger) {
+	app.Flusher = stats.NewFlusher(statscfg, app.Cache, app.Database, log)
+}
+
+func (app *App) registerBroker(brokercfg *config.BrokerConfig, log logger.Logger) {
+	app.Broker = broker.New(brokercfg, app.Cache, log)
+}
+
+func (app *App) registerMatcher(log logger.Logger) {
+	app.Matcher = matcher.New(app.Database, log)
+}
+
+func (app *App) registerWebhooks(webhookcfg *config.WebhookConfig, log logger.Logger) {
+	app.Webhooks = webhook.New(webhookcfg, app.Database, log)
+}
+
+func (app *App) registerTracing(tracingcfg *config.TracingConfig, log logger.Logger) error {
+	shutdown, err := tracing.Setup(context.Background(), tracingcfg)
+	if err != nil {
+		return err
+	}
+
+	app.shutdownTracing = shutdown
+	log.Infof("tracing configured", map[string]interface{}{"exporter": tracingcfg.Exporter})
+
+	return nil
+}
+
+func (app *App) registerHealth(healthcfg *config.HealthConfig, log logger.Logger) {
+	app.Health = health.New(healthcfg, app.Database, app.Cache, log)
+}
+
+func (app *App) registerRateLimiter(ratelimitcfg *config.RateLimitConfig, log logger.Logger) error {
+	var store ratelimit.Store
+
+	switch ratelimitcfg.Store {
+	case ratelimit.StoreRedis:
+		store = ratelimit.NewCacheStore(app.Cache)
+	case ratelimit.StoreMemory:
+		store = ratelimit.NewMemoryStore()
+	default:
+		return fmt.Errorf("rate limit store [%s] is not supported", ratelimitcfg.Store)
+	}
+
+	app.RateLimiter = ratelimit.New(ratelimitcfg, store, log)
+	return nil
+}
+
+func (app *App) registerIdempotency(idempotencycfg *config.IdempotencyConfig, log logger.Logger) {
+	app.IdempotencyCleaner = idempotency.NewCleaner(idempotencycfg, app.Database, log)
+}
+
+func (app *App) registerImporter(importcfg *config.ImportConfig, log logger.Logger) {
+	app.Importer = importer.New(importcfg, app.Database, app.Cache, app.Broker, app.Matcher, log)
+}
+
+func (app *App) registerOffers(offercfg *config.OfferConfig, log logger.Logger) {
+	app.OfferExpirer = offer.NewExpirer(offercfg, app.Database, log)
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"sort"
+	"strings"
+
+	"vk-internship/internal/config"
+)
+
+// reloadable reports whether a setting can be applied without a restart.
+func reloadable(key string) bool {
+	switch {
+	case key == "LOGGER_LEVEL", key == "REDIS_TTL":
+		return true
+	case key == "RATE_LIMIT_STORE":
+		return false
+	default:
+		return strings.HasPrefix(key, "RATE_LIMIT_")
+	}
+}
+
+// reload re-reads the configuration on SIGHUP and applies the settings that
+// are safe to change at runtime. It returns the configuration now in effect;
+// an invalid configuration is rejected as a whole.
+func (app *App) reload(configPath string, current *config.Config) *config.Config {
+	next, err := config.Load(configPath)
+	if err != nil {
+		app.Logger.Error(err, "config reload failed, keeping current config")
+		return current
+	}
+
+	var applied, skipped []string
+	for _, key := range config.Changed(current, next) {
+		if reloadable(key) {
+			applied = append(applied, key)
+		} else {
+			skipped = append(skipped, key)
+		}
+	}
+	sort.Strings(applied)
+	sort.Strings(skipped)
+
+	if len(skipped) > 0 {
+		app.Logger.Warnf("config changes require a restart", map[string]interface{}{"keys": skipped})
+	}
+
+	if len(applied) == 0 {
+		app.Logger.Info("config reloaded, nothing to apply")
+		return current
+	}
+
+	effective := *current
+
+	loggercfg := *current.Logger
+	loggercfg.Level = next.Logger.Level
+	if err := app.Logger.SetLevel(loggercfg.Level); err != nil {
+		app.Logger.Error(err, "failed to set log level")
+	}
+	effective.Logger = &loggercfg
+
+	ratelimitcfg := *next.RateLimit
+	ratelimitcfg.Store = current.RateLimit.Store
+	app.RateLimiter.Configure(&ratelimitcfg)
+	effective.RateLimit = &ratelimitcfg
+
+	if current.Redis != nil && next.Redis != nil {
+		rediscfg := *current.Redis
+		rediscfg.TTL = next.Redis.TTL
+		app.Cache.SetFeedTTL(rediscfg.TTL)
+		effective.Redis = &rediscfg
+	}
+
+	app.Logger.Infof("config reloaded", map[string]interface{}{"applied": applied})
+
+	return &effective
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+	"vk-internship/internal/seed"
+)
+
+const seedUsage = `usage: marketplace [--config path] seed [flags]
+
+flags:
+  --users N        number of users to create (default 100)
+  --ads N          number of ads to create (default 1000)
+  --seed N         generator seed, the same seed gives the same data (default 1)
+  --period D       how far back timestamps go (default 720h)
+  --until T        latest timestamp in RFC 3339 (default now)
+  --password P     password of every generated user (default password123)
+  --batch N        rows per insert (default 1000)
+  --warm-cache     load the latest ads into the feed cache afterwards`
+
+// Seed fills the database with generated users and ads.
+func Seed(configPath string, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	users := fs.Int("users", 100, "")
+	ads := fs.Int("ads", 1000, "")
+	seedValue := fs.Uint64("seed", 1, "")
+	period := fs.Duration("period", 30*24*time.Hour, "")
+	until := fs.String("until", "", "")
+	password := fs.String("password", "password123", "")
+	batch := fs.Int("batch", 1000, "")
+	warmCache := fs.Bool("warm-cache", false, "")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return errors.New(seedUsage)
+		}
+		return fmt.Errorf("%w\n\n%s", err, seedUsage)
+	}
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q\n\n%s", fs.Arg(0), seedUsage)
+	}
+
+	end := time.Now()
+	if *until != "" {
+		var err error
+		if end, err = time.Parse(time.RFC3339, *until); err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+
+	switch {
+	case *users < 0 || *ads < 0:
+		return errors.New("--users and --ads must not be negative")
+	case *ads > 0 && *users == 0:
+		return errors.New("ads need authors, --users must be positive")
+	case *batch < 1:
+		return errors.New("--batch must be positive")
+	case *period <= 0:
+		return errors.New("--period must be positive")
+	case len(*password) < 8 || len(*password) > 64:
+		return errors.New("--password must be 8 to 64 characters long")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(*password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	s, err := openToolStorage(configPath)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	started := time.Now()
+	gen := seed.New(*seedValue, end, *period)
+
+	authors := make([]*model.User, 0, *users)
+	for i := 0; i < *users; i++ {
+		authors = append(authors, gen.User(i, string(hash)))
+	}
+
+	for from := 0; from < len(authors); from += *batch {
+		to := min(from+*batch, len(authors))
+		if err := s.db.CreateUsers(ctx, authors[from:to]); err != nil {
+			if errors.Is(err, database.ErrUserExists) {
+				return fmt.Errorf("%w: the database already has users of seed %d, use another --seed", err, *seedValue)
+			}
+			return err
+		}
+
+		s.log.Infof("users created", map[string]interface{}{"count": to, "total": len(authors)})
+	}
+
+	chunk := make([]*model.Advertisement, 0, *batch)
+	for created := 0; created < *ads; {
+		chunk = chunk[:0]
+		for len(chunk) < *batch && created+len(chunk) < *ads {
+			chunk = append(chunk, gen.Ad(authors))
+		}
+
+		if err := s.db.CreateAds(ctx, chunk); err != nil {
+			return err
+		}
+
+		created += len(chunk)
+		s.log.Infof("ads created", map[string]interface{}{"count": created, "total": *ads})
+	}
+
+	if *warmCache {
+		items, err := s.rebuildFeed(ctx)
+		if err != nil {
+			s.log.Warnf("failed to warm the feed cache", map[string]interface{}{"error": err.Error()})
+		} else {
+			s.log.Infof("feed cache warmed", map[string]interface{}{"items": items})
+		}
+	} else if *ads > 0 {
+		s.dropFeed(ctx)
+	}
+
+	fmt.Fprintf(os.Stdout, "created %d users and %d ads with seed %d in %s\n", *users, *ads, *seedValue, time.Since(started).Round(time.Millisecond))
+	return nil
+}
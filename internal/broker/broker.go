@@ -0,0 +1,263 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"vk-internship/internal/cache"
+	"vk-internship/internal/config"
+	"vk-internship/internal/database/model"
+	"vk-internship/internal/logger"
+)
+
+type EventType string
+
+const (
+	AdCreated EventType = "ad.created"
+	AdUpdated EventType = "ad.updated"
+	AdDeleted EventType = "ad.deleted"
+)
+
+type Event struct {
+	ID         uint64               `json:"id"`
+	Type       EventType            `json:"type"`
+	AdID       string               `json:"ad_id"`
+	Ad         *model.Advertisement `json:"ad,omitempty"`
+	Author     *Author              `json:"author,omitempty"`
+	OccurredAt time.Time            `json:"occurred_at"`
+}
+
+// Author is the public part of the ad author's profile. The profile on the
+// ad itself is not serialized, so it travels next to the ad through fan-out.
+type Author struct {
+	DisplayName string `json:"display_name"`
+	AvatarURL   string `json:"avatar_url"`
+	City        string `json:"city"`
+}
+
+// Broker fans ad events out to in-process subscribers. With fan-out enabled
+// events travel through the cache pub/sub channel, so every replica
+// (including the publisher) receives them from there.
+type Broker struct {
+	cache   cache.Cache
+	channel string
+	fanout  bool
+	log     logger.Logger
+
+	historySize int
+	bufferSize  int
+
+	mu          sync.RWMutex
+	closed      bool
+	lastID      uint64
+	history     []Event
+	subscribers map[*Subscription]struct{}
+}
+
+type Subscription struct {
+	Events <-chan Event
+	events chan Event
+}
+
+func New(cfg *config.BrokerConfig, cache cache.Cache, log logger.Logger) *Broker {
+	return &Broker{
+		cache:       cache,
+		channel:     cfg.Channel,
+		fanout:      cfg.Fanout,
+		log:         log.Component("broker"),
+		historySize: cfg.HistorySize,
+		bufferSize:  cfg.SubscriberBuffer,
+		history:     make([]Event, 0, cfg.HistorySize),
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+func (b *Broker) Publish(ctx context.Context, eventType EventType, ad *model.Advertisement) error {
+	event := Event{
+		ID:         b.nextID(),
+		Type:       eventType,
+		AdID:       ad.ID,
+		OccurredAt: time.Now(),
+	}
+
+	if eventType != AdDeleted {
+		event.Author = &Author{
+			DisplayName: ad.Author.DisplayName,
+			AvatarURL:   ad.Author.AvatarURL,
+			City:        ad.Author.City,
+		}
+
+		// subscribers get the same ad with or without fan-out, the phone is
+		// never part of it
+		public := *ad
+		public.Author = event.Author.profile()
+		event.Ad = &public
+	}
+
+	if !b.fanout {
+		b.dispatch(event)
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if err := b.cache.Publish(ctx, b.channel, payload); err != nil {
+		b.log.Warnf("fan-out failed, delivering locally", map[string]interface{}{"error": err.Error()})
+		b.dispatch(event)
+		return err
+	}
+
+	return nil
+}
+
+func (b *Broker) Run(ctx context.Context) {
+	if !b.fanout {
+		return
+	}
+
+	backoff := time.Second
+	for {
+		messages, err := b.cache.Subscribe(ctx, b.channel)
+		if err != nil {
+			b.log.Warnf("failed to subscribe to events", map[string]interface{}{"error": err.Error(), "retry_in": backoff.String()})
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+
+			backoff = min(backoff*2, 30*time.Second)
+			continue
+		}
+
+		backoff = time.Second
+		b.log.Info("subscribed to ad events")
+
+		for payload := range messages {
+			var event Event
+			if err := json.Unmarshal(payload, &event); err != nil {
+				b.log.Warnf("malformed event", map[string]interface{}{"error": err.Error()})
+				continue
+			}
+
+			if event.Ad != nil && event.Author != nil {
+				event.Ad.Author = event.Author.profile()
+			}
+
+			b.dispatch(event)
+		}
+
+		if ctx.Err() != nil {
+			b.log.Info("broker stopped")
+			return
+		}
+
+		b.log.Warn("event subscription closed, resubscribing")
+	}
+}
+
+// Subscribe registers a subscriber and returns events newer than lastID that
+// are still kept in history, so a reconnecting client can catch up.
+func (b *Broker) Subscribe(lastID uint64) (*Subscription, []Event) {
+	events := make(chan Event, b.bufferSize)
+	sub := &Subscription{Events: events, events: events}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var missed []Event
+	if lastID > 0 {
+		for _, event := range b.history {
+			if event.ID > lastID {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	if b.closed {
+		close(events)
+		return sub, missed
+	}
+
+	b.subscribers[sub] = struct{}{}
+
+	return sub, missed
+}
+
+// Close disconnects all subscribers so that long-lived streams do not block
+// the server shutdown.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subscribers {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+func (b *Broker) dispatch(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if event.ID > b.lastID {
+		b.lastID = event.ID
+	}
+
+	if b.historySize > 0 {
+		if len(b.history) == b.historySize {
+			b.history = append(b.history[:0], b.history[1:]...)
+		}
+		b.history = append(b.history, event)
+	}
+
+	for sub := range b.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			b.log.Warn("subscriber is too slow, dropping it")
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// nextID returns a time-based ID that is strictly increasing within the
+// replica and roughly ordered across replicas.
+func (b *Broker) nextID() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := uint64(time.Now().UnixNano())
+	if id <= b.lastID {
+		id = b.lastID + 1
+	}
+	b.lastID = id
+
+	return id
+}
+
+func (a *Author) profile() model.UserProfile {
+	return model.UserProfile{
+		DisplayName: a.DisplayName,
+		AvatarURL:   a.AvatarURL,
+		City:        a.City,
+	}
+}
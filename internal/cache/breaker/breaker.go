@@ -0,0 +1,351 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"vk-internship/internal/cache"
+	"vk-internship/internal/config"
+	"vk-internship/internal/database/model"
+	"vk-internship/internal/logger"
+	"vk-internship/internal/metrics"
+)
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return "open"
+	}
+}
+
+var ErrOpen = errors.New("cache circuit breaker is open")
+
+// ConnectFunc creates the underlying cache. It is called at startup and again
+// on every probe until it succeeds, so the app can start without the cache.
+type ConnectFunc func(ctx context.Context) (cache.Cache, error)
+
+// Breaker wraps a cache.Cache and fails fast while the cache is unhealthy.
+// After FailureThreshold consecutive failures it opens and a background probe
+// checks the cache every Cooldown; the first successful probe closes it again.
+type Breaker struct {
+	connect      ConnectFunc
+	threshold    int
+	cooldown     time.Duration
+	probeTimeout time.Duration
+	log          logger.Logger
+
+	mu       sync.RWMutex
+	cache    cache.Cache
+	state    State
+	failures int
+	// feedTTL overrides the TTL of caches connected later, zero keeps theirs.
+	feedTTL time.Duration
+}
+
+var _ cache.Cache = (*Breaker)(nil)
+
+func New(cfg *config.CacheBreakerConfig, connect ConnectFunc, log logger.Logger) *Breaker {
+	b := &Breaker{
+		connect:      connect,
+		threshold:    cfg.FailureThreshold,
+		cooldown:     cfg.Cooldown,
+		probeTimeout: cfg.ProbeTimeout,
+		log:          log.Component("cache_breaker"),
+		state:        StateOpen,
+	}
+	metrics.CacheBreakerState.Set(float64(StateOpen))
+
+	return b
+}
+
+// Connect makes the initial connection attempt. On failure the breaker stays
+// open and Run keeps retrying in the background.
+func (b *Breaker) Connect(ctx context.Context) error {
+	return b.probe(ctx)
+}
+
+func (b *Breaker) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.cooldown)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if b.State() != StateOpen {
+				continue
+			}
+
+			probeCtx, cancel := context.WithTimeout(ctx, b.probeTimeout)
+			b.probe(probeCtx)
+			cancel()
+		}
+	}
+}
+
+func (b *Breaker) State() State {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.state
+}
+
+func (b *Breaker) probe(ctx context.Context) error {
+	b.setState(StateHalfOpen, nil)
+
+	b.mu.RLock()
+	c := b.cache
+	b.mu.RUnlock()
+
+	var err error
+	if c == nil {
+		c, err = b.connect(ctx)
+		if err == nil {
+			b.mu.Lock()
+			if b.feedTTL > 0 {
+				c.SetFeedTTL(b.feedTTL)
+			}
+			b.cache = c
+			b.mu.Unlock()
+		}
+	} else {
+		err = c.Ping(ctx)
+	}
+
+	if err != nil {
+		b.setState(StateOpen, err)
+		return err
+	}
+
+	b.mu.Lock()
+	b.failures = 0
+	b.mu.Unlock()
+
+	b.setState(StateClosed, nil)
+	return nil
+}
+
+func (b *Breaker) setState(state State, cause error) {
+	b.mu.Lock()
+	prev := b.state
+	b.state = state
+	b.mu.Unlock()
+
+	metrics.CacheBreakerState.Set(float64(state))
+
+	if prev == state || state == StateHalfOpen {
+		return
+	}
+
+	// a failed probe only confirms the breaker is still open
+	if prev == StateHalfOpen && state == StateOpen {
+		b.log.Debugf("cache probe failed", map[string]interface{}{"error": cause.Error()})
+		return
+	}
+
+	fields := map[string]interface{}{"from": prev.String(), "to": state.String()}
+	if cause != nil {
+		fields["error"] = cause.Error()
+	}
+
+	if state == StateOpen {
+		b.log.Warnf("cache circuit breaker opened, serving without cache", fields)
+	} else {
+		b.log.Infof("cache circuit breaker closed, cache is back", fields)
+	}
+}
+
+func (b *Breaker) acquire() (cache.Cache, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.state != StateClosed || b.cache == nil {
+		return nil, ErrOpen
+	}
+
+	return b.cache, nil
+}
+
+func (b *Breaker) record(ctx context.Context, err error) {
+	// the caller giving up is not a sign of an unhealthy cache
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+
+	b.mu.Lock()
+	if err == nil {
+		b.failures = 0
+		b.mu.Unlock()
+		return
+	}
+
+	b.failures++
+	trip := b.state == StateClosed && b.failures >= b.threshold
+	b.mu.Unlock()
+
+	if trip {
+		b.setState(StateOpen, err)
+	}
+}
+
+func (b *Breaker) Ping(ctx context.Context) error {
+	c, err := b.acquire()
+	if err != nil {
+		return err
+	}
+
+	err = c.Ping(ctx)
+	b.record(ctx, err)
+	return err
+}
+
+func (b *Breaker) GetFeed(ctx context.Context) ([]model.Advertisement, error) {
+	c, err := b.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	ads, err := c.GetFeed(ctx)
+	b.record(ctx, err)
+	return ads, err
+}
+
+func (b *Breaker) SetFeed(ctx context.Context, ads []model.Advertisement) error {
+	c, err := b.acquire()
+	if err != nil {
+		return err
+	}
+
+	err = c.SetFeed(ctx, ads)
+	b.record(ctx, err)
+	return err
+}
+
+func (b *Breaker) UpdateFeed(ctx context.Context, ad model.Advertisement) error {
+	c, err := b.acquire()
+	if err != nil {
+		return err
+	}
+
+	err = c.UpdateFeed(ctx, ad)
+	b.record(ctx, err)
+	return err
+}
+
+func (b *Breaker) InvalidateFeed(ctx context.Context) error {
+	c, err := b.acquire()
+	if err != nil {
+		return err
+	}
+
+	err = c.InvalidateFeed(ctx)
+	b.record(ctx, err)
+	return err
+}
+
+// SetFeedTTL works while the breaker is open too, the TTL is applied once the
+// cache is connected.
+func (b *Breaker) SetFeedTTL(ttl time.Duration) {
+	b.mu.Lock()
+	b.feedTTL = ttl
+	c := b.cache
+	b.mu.Unlock()
+
+	if c != nil {
+		c.SetFeedTTL(ttl)
+	}
+}
+
+func (b *Breaker) RegisterView(ctx context.Context, adID, viewerID string, window time.Duration) (bool, error) {
+	c, err := b.acquire()
+	if err != nil {
+		return false, err
+	}
+
+	counted, err := c.RegisterView(ctx, adID, viewerID, window)
+	b.record(ctx, err)
+	return counted, err
+}
+
+func (b *Breaker) PopPendingViews(ctx context.Context) ([]model.AdViewCount, error) {
+	c, err := b.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	views, err := c.PopPendingViews(ctx)
+	b.record(ctx, err)
+	return views, err
+}
+
+func (b *Breaker) PushPendingViews(ctx context.Context, views []model.AdViewCount) error {
+	c, err := b.acquire()
+	if err != nil {
+		return err
+	}
+
+	err = c.PushPendingViews(ctx, views)
+	b.record(ctx, err)
+	return err
+}
+
+func (b *Breaker) TakeToken(ctx context.Context, key string, rate float64, burst int) (bool, float64, error) {
+	c, err := b.acquire()
+	if err != nil {
+		return false, 0, err
+	}
+
+	allowed, tokens, err := c.TakeToken(ctx, key, rate, burst)
+	b.record(ctx, err)
+	return allowed, tokens, err
+}
+
+func (b *Breaker) Publish(ctx context.Context, channel string, payload []byte) error {
+	c, err := b.acquire()
+	if err != nil {
+		return err
+	}
+
+	err = c.Publish(ctx, channel, payload)
+	b.record(ctx, err)
+	return err
+}
+
+func (b *Breaker) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	c, err := b.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := c.Subscribe(ctx, channel)
+	b.record(ctx, err)
+	return messages, err
+}
+
+func (b *Breaker) Close() error {
+	b.mu.Lock()
+	c := b.cache
+	b.cache = nil
+	b.mu.Unlock()
+
+	if c == nil {
+		return nil
+	}
+
+	return c.Close()
+}
@@ -0,0 +1,153 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"vk-internship/internal/cache"
+	"vk-internship/internal/config"
+	"vk-internship/internal/database/model"
+	zerologger "vk-internship/internal/logger/zerolog"
+)
+
+// fakeCache fails every call with err, the methods the test does not use
+// panic through the nil embedded interface.
+type fakeCache struct {
+	cache.Cache
+	err error
+}
+
+func (c *fakeCache) Ping(context.Context) error {
+	return c.err
+}
+
+func (c *fakeCache) GetFeed(context.Context) ([]model.Advertisement, error) {
+	return nil, c.err
+}
+
+func TestBreakerTransitions(t *testing.T) {
+	errCache := errors.New("connection refused")
+
+	tests := []struct {
+		name       string
+		connectErr error
+		// results are returned by the cache to consecutive calls
+		results  []error
+		canceled bool
+		probe    bool
+		probeErr error
+		want     State
+	}{
+		{
+			name:       "failed connect stays open",
+			connectErr: errCache,
+			want:       StateOpen,
+		},
+		{
+			name: "successful connect closes",
+			want: StateClosed,
+		},
+		{
+			name:    "failures below threshold stay closed",
+			results: []error{errCache, errCache},
+			want:    StateClosed,
+		},
+		{
+			name:    "threshold consecutive failures open",
+			results: []error{errCache, errCache, errCache},
+			want:    StateOpen,
+		},
+		{
+			name:    "success resets the failure count",
+			results: []error{errCache, errCache, nil, errCache, errCache},
+			want:    StateClosed,
+		},
+		{
+			name:     "canceled callers are not failures",
+			results:  []error{errCache, errCache, errCache},
+			canceled: true,
+			want:     StateClosed,
+		},
+		{
+			name:    "successful probe closes",
+			results: []error{errCache, errCache, errCache},
+			probe:   true,
+			want:    StateClosed,
+		},
+		{
+			name:     "failed probe stays open",
+			results:  []error{errCache, errCache, errCache},
+			probe:    true,
+			probeErr: errCache,
+			want:     StateOpen,
+		},
+		{
+			name:       "probe connects a cache that was down at startup",
+			connectErr: errCache,
+			probe:      true,
+			want:       StateClosed,
+		},
+	}
+
+	log := zerologger.New(&config.LoggerConfig{Level: "disabled"})
+	cfg := &config.CacheBreakerConfig{FailureThreshold: 3, Cooldown: time.Second, ProbeTimeout: time.Second}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeCache{}
+			connectErr := tt.connectErr
+
+			b := New(cfg, func(context.Context) (cache.Cache, error) {
+				if connectErr != nil {
+					return nil, connectErr
+				}
+				return fake, nil
+			}, log)
+
+			if err := b.Connect(context.Background()); !errors.Is(err, tt.connectErr) {
+				t.Fatalf("Connect() = %v, want %v", err, tt.connectErr)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.canceled {
+				cancel()
+			}
+			for _, err := range tt.results {
+				fake.err = err
+				b.GetFeed(ctx)
+			}
+			cancel()
+
+			if tt.probe {
+				connectErr = nil
+				fake.err = tt.probeErr
+				b.probe(context.Background())
+			}
+
+			if got := b.State(); got != tt.want {
+				t.Errorf("State() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreakerOpenFailsFast(t *testing.T) {
+	log := zerologger.New(&config.LoggerConfig{Level: "disabled"})
+	cfg := &config.CacheBreakerConfig{FailureThreshold: 1, Cooldown: time.Second, ProbeTimeout: time.Second}
+
+	fake := &fakeCache{}
+	b := New(cfg, func(context.Context) (cache.Cache, error) { return fake, nil }, log)
+	if err := b.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() = %v", err)
+	}
+
+	fake.err = errors.New("timeout")
+	b.GetFeed(context.Background())
+
+	fake.err = nil
+	if _, err := b.GetFeed(context.Background()); !errors.Is(err, ErrOpen) {
+		t.Errorf("GetFeed() = %v, want %v", err, ErrOpen)
+	}
+}
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"time"
 
 	"vk-internship/internal/database/model"
 )
@@ -13,6 +14,20 @@ type Cache interface {
 	SetFeed(ctx context.Context, ads []model.Advertisement) error
 	UpdateFeed(ctx context.Context, ad model.Advertisement) error
 	InvalidateFeed(ctx context.Context) error
-	
+	// SetFeedTTL changes the expiration of feed entries written afterwards.
+	SetFeedTTL(ttl time.Duration)
+
+	RegisterView(ctx context.Context, adID, viewerID string, window time.Duration) (bool, error)
+	PopPendingViews(ctx context.Context) ([]model.AdViewCount, error)
+	PushPendingViews(ctx context.Context, views []model.AdViewCount) error
+
+	// TakeToken takes one token from the bucket identified by key, refilling it
+	// at rate tokens per second up to burst. It returns whether the token was
+	// taken and how many tokens are left.
+	TakeToken(ctx context.Context, key string, rate float64, burst int) (bool, float64, error)
+
+	Publish(ctx context.Context, channel string, payload []byte) error
+	Subscribe(ctx context.Context, channel string) (<-chan []byte, error)
+
 	Close() error
 }
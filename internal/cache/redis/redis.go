@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -11,18 +12,19 @@ import (
 	"vk-internship/internal/config"
 	"vk-internship/internal/database/model"
 	"vk-internship/internal/logger"
+	"vk-internship/internal/metrics"
 )
 
 type Redis struct {
 	client       *redis.Client
-	TTL          time.Duration
+	ttl          atomic.Int64
 	maxFeedItems int
 	log          logger.Logger
 }
 
 const feedCacheKey = "feed:latest"
 
-func New(cfg *config.RedisConfig, log logger.Logger) (*Redis, error) {
+func New(ctx context.Context, cfg *config.RedisConfig, log logger.Logger) (*Redis, error) {
 	log.Debug("creating new redis client")
 
 	client := redis.NewClient(&redis.Options{
@@ -36,17 +38,24 @@ func New(cfg *config.RedisConfig, log logger.Logger) (*Redis, error) {
 		WriteTimeout: cfg.Timeout,
 	})
 
+	client.AddHook(tracingHook{})
+
 	r := &Redis{
 		client:       client,
-		TTL:          cfg.TTL,
 		maxFeedItems: cfg.MaxFeedItems,
 		log:          log.Component("redis"),
 	}
+	r.ttl.Store(int64(cfg.TTL))
 
-	if err := r.Ping(context.TODO()); err != nil {
+	if err := r.Ping(ctx); err != nil {
+		client.Close()
 		return nil, err
 	}
 
+	if err := metrics.Registry.Register(newPoolCollector(client)); err != nil {
+		r.log.Warnf("failed to register pool metrics", map[string]interface{}{"error": err.Error()})
+	}
+
 	r.log.Info("connected to redis server")
 
 	return r, nil
@@ -61,6 +70,7 @@ func (r *Redis) GetFeed(ctx context.Context) ([]model.Advertisement, error) {
 	data, err := r.client.Get(ctx, feedCacheKey).Bytes()
 	if err != nil {
 		if err == redis.Nil {
+			metrics.FeedCacheMisses.Inc()
 			r.log.Debug("feed cache is empty")
 			return nil, nil
 		}
@@ -74,6 +84,7 @@ func (r *Redis) GetFeed(ctx context.Context) ([]model.Advertisement, error) {
 		return nil, fmt.Errorf("failed to unmarshal feed: %w", err)
 	}
 
+	metrics.FeedCacheHits.Inc()
 	r.log.Debugf("retrieved feed from cache", map[string]interface{}{"count": len(ads)})
 	return ads, nil
 }
@@ -89,7 +100,7 @@ func (r *Redis) SetFeed(ctx context.Context, ads []model.Advertisement) error {
 		return fmt.Errorf("failed to marshal feed: %w", err)
 	}
 
-	if err := r.client.Set(ctx, feedCacheKey, data, r.TTL).Err(); err != nil {
+	if err := r.client.Set(ctx, feedCacheKey, data, time.Duration(r.ttl.Load())).Err(); err != nil {
 		r.log.Warnf("failed to set feed cache", map[string]interface{}{"error": err.Error()})
 		return fmt.Errorf("failed to set feed: %w", err)
 	}
@@ -124,6 +135,10 @@ func (r *Redis) InvalidateFeed(ctx context.Context) error {
 	return nil
 }
 
+func (r *Redis) SetFeedTTL(ttl time.Duration) {
+	r.ttl.Store(int64(ttl))
+}
+
 func (r *Redis) Close() error {
 	if err := r.client.Close(); err != nil {
 		r.log.Error(err, "failed to close connection")
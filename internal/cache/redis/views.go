@@ -0,0 +1,94 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"vk-internship/internal/database/model"
+)
+
+const (
+	viewKeyPrefix   = "ad:view:"
+	pendingViewsKey = "ad:views:pending"
+)
+
+// popHashScript atomically reads and removes the whole hash so that views
+// registered during a flush end up in the next batch instead of being lost.
+var popHashScript = redis.NewScript(`
+local data = redis.call('HGETALL', KEYS[1])
+redis.call('DEL', KEYS[1])
+return data
+`)
+
+func (r *Redis) RegisterView(ctx context.Context, adID, viewerID string, window time.Duration) (bool, error) {
+	first, err := r.client.SetNX(ctx, viewKeyPrefix+adID+":"+viewerID, 1, window).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to register view: %w", err)
+	}
+
+	if !first {
+		return false, nil
+	}
+
+	field := adID + "|" + time.Now().UTC().Format(time.DateOnly)
+	if err := r.client.HIncrBy(ctx, pendingViewsKey, field, 1).Err(); err != nil {
+		return false, fmt.Errorf("failed to increment pending views: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *Redis) PopPendingViews(ctx context.Context) ([]model.AdViewCount, error) {
+	data, err := popHashScript.Run(ctx, r.client, []string{pendingViewsKey}).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to pop pending views: %w", err)
+	}
+
+	views := make([]model.AdViewCount, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		adID, dayStr, ok := strings.Cut(data[i], "|")
+		if !ok {
+			r.log.Warnf("malformed pending views field", map[string]interface{}{"field": data[i]})
+			continue
+		}
+
+		day, err := time.Parse(time.DateOnly, dayStr)
+		if err != nil {
+			r.log.Warnf("malformed pending views day", map[string]interface{}{"field": data[i]})
+			continue
+		}
+
+		count, err := strconv.ParseInt(data[i+1], 10, 64)
+		if err != nil {
+			r.log.Warnf("malformed pending views count", map[string]interface{}{"field": data[i], "value": data[i+1]})
+			continue
+		}
+
+		views = append(views, model.AdViewCount{AdID: adID, Day: day, Views: count})
+	}
+
+	r.log.Debugf("popped pending views", map[string]interface{}{"count": len(views)})
+	return views, nil
+}
+
+func (r *Redis) PushPendingViews(ctx context.Context, views []model.AdViewCount) error {
+	if len(views) == 0 {
+		return nil
+	}
+
+	pipe := r.client.TxPipeline()
+	for _, v := range views {
+		pipe.HIncrBy(ctx, pendingViewsKey, v.AdID+"|"+v.Day.Format(time.DateOnly), v.Views)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to push pending views: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+type BrokerConfig struct {
+	Fanout            bool          `env:"BROKER_FANOUT" envDefault:"true"`
+	Channel           string        `env:"BROKER_CHANNEL" envDefault:"ads:events"`
+	HistorySize       int           `env:"BROKER_HISTORY_SIZE" envDefault:"1000"`
+	SubscriberBuffer  int           `env:"BROKER_SUBSCRIBER_BUFFER" envDefault:"64"`
+	HeartbeatInterval time.Duration `env:"SSE_HEARTBEAT_INTERVAL" envDefault:"15s"`
+}
+
+func (c *BrokerConfig) Validate() error {
+	var channel error
+	if c.Fanout {
+		channel = notEmpty("BROKER_CHANNEL", c.Channel)
+	}
+
+	return errors.Join(
+		channel,
+		atLeast("BROKER_HISTORY_SIZE", c.HistorySize, 0),
+		atLeast("BROKER_SUBSCRIBER_BUFFER", c.SubscriberBuffer, 1),
+		positive("SSE_HEARTBEAT_INTERVAL", c.HeartbeatInterval),
+	)
+}
+
+func LoadBrokerConfig() (*BrokerConfig, error) {
+	var cfg BrokerConfig
+	if err := parse(&cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
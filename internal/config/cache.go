@@ -1,14 +1,13 @@
 package config
 
 import (
+	"errors"
 	"time"
-
-	"github.com/caarlos0/env/v11"
 )
 
 type RedisConfig struct {
 	Addr         string        `env:"REDIS_ADDR,required"`
-	Password     string        `env:"REDIS_PASSWORD,required"`
+	Password     string        `env:"REDIS_PASSWORD,required" secret:"true"`
 	User         string        `env:"REDIS_USER,required"`
 	DB           int           `env:"REDIS_DB" envDefault:"0"`
 	MaxRetries   int           `env:"REDIS_MAX_RETRIES" envDefault:"3"`
@@ -18,9 +17,43 @@ type RedisConfig struct {
 	MaxFeedItems int           `env:"REDIS_MAX_FEED_ITEMS" envDefault:"10"`
 }
 
+func (c *RedisConfig) Validate() error {
+	return errors.Join(
+		atLeast("REDIS_DB", c.DB, 0),
+		atLeast("REDIS_MAX_RETRIES", c.MaxRetries, -1),
+		positive("REDIS_DIAL_TIMEOUT", c.DialTimeout),
+		positive("REDIS_TIMEOUT", c.Timeout),
+		positive("REDIS_TTL", c.TTL),
+		atLeast("REDIS_MAX_FEED_ITEMS", c.MaxFeedItems, 1),
+	)
+}
+
 func LoadRedisConfig() (*RedisConfig, error) {
 	var cfg RedisConfig
-	if err := env.Parse(&cfg); err != nil {
+	if err := parse(&cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+type CacheBreakerConfig struct {
+	FailureThreshold int           `env:"CACHE_BREAKER_FAILURE_THRESHOLD" envDefault:"5"`
+	Cooldown         time.Duration `env:"CACHE_BREAKER_COOLDOWN" envDefault:"10s"`
+	ProbeTimeout     time.Duration `env:"CACHE_BREAKER_PROBE_TIMEOUT" envDefault:"2s"`
+}
+
+func (c *CacheBreakerConfig) Validate() error {
+	return errors.Join(
+		atLeast("CACHE_BREAKER_FAILURE_THRESHOLD", c.FailureThreshold, 1),
+		positive("CACHE_BREAKER_COOLDOWN", c.Cooldown),
+		positive("CACHE_BREAKER_PROBE_TIMEOUT", c.ProbeTimeout),
+	)
+}
+
+func LoadCacheBreakerConfig() (*CacheBreakerConfig, error) {
+	var cfg CacheBreakerConfig
+	if err := parse(&cfg); err != nil {
 		return nil, err
 	}
 
@@ -1,9 +1,9 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"time"
-
-	"github.com/caarlos0/env/v11"
 )
 
 type ServerConfig struct {
@@ -12,14 +12,50 @@ type ServerConfig struct {
 	WriteTimeout time.Duration `env:"WRITE_TIMEOUT" envDefault:"30s"`
 	IdleTimeout  time.Duration `env:"IDLE_TIMEOUT" envDefault:"60s"`
 
-	JWTSecret string        `env:"JWT_SECRET,required"`
+	JWTSecret string        `env:"JWT_SECRET,required" secret:"true"`
 	JWTTTL    time.Duration `env:"JWT_TTL" envDefault:"24h"`
 	JWTIssuer string        `env:"JWT_ISSUER,required"`
+
+	LegacyDeprecation time.Time `env:"API_LEGACY_DEPRECATION" envDefault:"2026-10-19T00:00:00Z"`
+	LegacySunset      time.Time `env:"API_LEGACY_SUNSET" envDefault:"2027-04-19T00:00:00Z"`
+
+	// TrustedProxies are the IPs and CIDRs whose X-Forwarded-For and
+	// X-Real-IP headers are believed. Empty means clients connect directly.
+	TrustedProxies []string `env:"TRUSTED_PROXIES"`
+}
+
+func (c *ServerConfig) Validate() error {
+	var sunset error
+	if !c.LegacySunset.After(c.LegacyDeprecation) {
+		sunset = fmt.Errorf("API_LEGACY_SUNSET: must be after API_LEGACY_DEPRECATION")
+	}
+
+	return errors.Join(
+		port("PORT", c.Port),
+		positive("READ_TIMEOUT", c.ReadTimeout),
+		positive("WRITE_TIMEOUT", c.WriteTimeout),
+		positive("IDLE_TIMEOUT", c.IdleTimeout),
+		notEmpty("JWT_SECRET", c.JWTSecret),
+		positive("JWT_TTL", c.JWTTTL),
+		notEmpty("JWT_ISSUER", c.JWTIssuer),
+		sunset,
+		ipsOrCIDRs("TRUSTED_PROXIES", c.TrustedProxies),
+	)
 }
 
 type StorageConfig struct {
 	DBType    string `env:"DB_TYPE,required"`
 	CacheType string `env:"CACHE_TYPE,required"`
+	// AutoMigrate applies pending migrations at startup instead of refusing
+	// to start against an outdated schema.
+	AutoMigrate bool `env:"AUTO_MIGRATE" envDefault:"false"`
+}
+
+func (c *StorageConfig) Validate() error {
+	return errors.Join(
+		oneOf("DB_TYPE", c.DBType, "postgres"),
+		oneOf("CACHE_TYPE", c.CacheType, "redis"),
+	)
 }
 
 type LoggerConfig struct {
@@ -28,9 +64,16 @@ type LoggerConfig struct {
 	Pretty bool   `env:"LOGGER_PRETTY" envDefault:"false"`
 }
 
+func (c *LoggerConfig) Validate() error {
+	return errors.Join(
+		oneOf("LOGGER_TYPE", c.Type, "zerolog"),
+		oneOf("LOGGER_LEVEL", c.Level, "trace", "debug", "info", "warn", "error"),
+	)
+}
+
 func LoadServerConfig() (*ServerConfig, error) {
 	var cfg ServerConfig
-	if err := env.Parse(&cfg); err != nil {
+	if err := parse(&cfg); err != nil {
 		return nil, err
 	}
 
@@ -39,7 +82,7 @@ func LoadServerConfig() (*ServerConfig, error) {
 
 func LoadStorageConfig() (*StorageConfig, error) {
 	var cfg StorageConfig
-	if err := env.Parse(&cfg); err != nil {
+	if err := parse(&cfg); err != nil {
 		return nil, err
 	}
 
@@ -48,7 +91,7 @@ func LoadStorageConfig() (*StorageConfig, error) {
 
 func LoadLoggerConfig() (*LoggerConfig, error) {
 	var cfg LoggerConfig
-	if err := env.Parse(&cfg); err != nil {
+	if err := parse(&cfg); err != nil {
 		return nil, err
 	}
 
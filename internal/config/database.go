@@ -1,31 +1,55 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"time"
-
-	"github.com/caarlos0/env/v11"
 )
 
 type PostgresConfig struct {
 	User     string `env:"POSTGRES_USER,required"`
-	Password string `env:"POSTGRES_PASSWORD,required"`
+	Password string `env:"POSTGRES_PASSWORD,required" secret:"true"`
 	Host     string `env:"POSTGRES_HOST,required"`
 	Port     string `env:"POSTGRES_PORT,required"`
 	DBName   string `env:"POSTGRES_DB_NAME,required"`
 	SSLMode  string `env:"POSTGRES_SSL_MODE" envDefault:"disable"`
 
 	PoolMaxConns          int32         `env:"POSTGRES_POOL_MAX_CONNS" envDefault:"4"`
-	PoolMinConns          int32         `env:"POSTGRES_POOL_MAX_CONNS" envDefault:"0"`
+	PoolMinConns          int32         `env:"POSTGRES_POOL_MIN_CONNS" envDefault:"0"`
 	PoolMaxConnLifetime   time.Duration `env:"POSTGRES_POOL_MAX_CONN_LIFETIME" envDefault:"1h"`
 	PoolMaxConnIdleTime   time.Duration `env:"POSTGRES_POOL_MAX_CONN_IDLE_TIME" envDefault:"30m"`
 	PoolHealthCheckPeriod time.Duration `env:"POSTGRES_POOL_HEALTHCHECK_PERIOD" envDefault:"1m"`
 
-	Timeout time.Duration `env:"POSTGRES_TIMEOUT" envDefault:"5s"`
+	// Timeout bounds every database call, StatementTimeout is enforced by the
+	// server for a single statement (0 disables it).
+	Timeout          time.Duration `env:"POSTGRES_TIMEOUT" envDefault:"5s"`
+	StatementTimeout time.Duration `env:"POSTGRES_STATEMENT_TIMEOUT" envDefault:"5s"`
+	ApplicationName  string        `env:"POSTGRES_APPLICATION_NAME" envDefault:"marketplace"`
+}
+
+func (c *PostgresConfig) Validate() error {
+	var minConns error
+	if c.PoolMinConns > c.PoolMaxConns {
+		minConns = fmt.Errorf("POSTGRES_POOL_MIN_CONNS: must not exceed POSTGRES_POOL_MAX_CONNS (%d), got %d", c.PoolMaxConns, c.PoolMinConns)
+	}
+
+	return errors.Join(
+		port("POSTGRES_PORT", c.Port),
+		oneOf("POSTGRES_SSL_MODE", c.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full"),
+		atLeast("POSTGRES_POOL_MAX_CONNS", c.PoolMaxConns, 1),
+		atLeast("POSTGRES_POOL_MIN_CONNS", c.PoolMinConns, 0),
+		minConns,
+		positive("POSTGRES_POOL_MAX_CONN_LIFETIME", c.PoolMaxConnLifetime),
+		positive("POSTGRES_POOL_MAX_CONN_IDLE_TIME", c.PoolMaxConnIdleTime),
+		positive("POSTGRES_POOL_HEALTHCHECK_PERIOD", c.PoolHealthCheckPeriod),
+		positive("POSTGRES_TIMEOUT", c.Timeout),
+		nonNegative("POSTGRES_STATEMENT_TIMEOUT", c.StatementTimeout),
+	)
 }
 
 func LoadPostgresConfig() (*PostgresConfig, error) {
 	var cfg PostgresConfig
-	if err := env.Parse(&cfg); err != nil {
+	if err := parse(&cfg); err != nil {
 		return nil, err
 	}
 
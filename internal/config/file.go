@@ -0,0 +1,143 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/caarlos0/env/v11"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	fileMu     sync.RWMutex
+	fileValues map[string]string
+)
+
+// LoadFile reads a YAML or TOML config file whose values are used for every
+// setting that is not set in the environment. Nested keys are joined with "_"
+// and upper-cased, so `postgres: {pool_max_conns: 8}` sets
+// POSTGRES_POOL_MAX_CONNS. An empty path clears previously loaded values.
+func LoadFile(path string) error {
+	if path == "" {
+		setFileValues(nil)
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	raw := make(map[string]interface{})
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return fmt.Errorf("unsupported config file format %q, use .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	if err := flatten("", raw, values); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	known := knownKeys()
+
+	var unknown []string
+	for key := range values {
+		if _, ok := known[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("invalid config file %s: unknown keys %s", path, strings.Join(unknown, ", "))
+	}
+
+	setFileValues(values)
+	return nil
+}
+
+func setFileValues(values map[string]string) {
+	fileMu.Lock()
+	defer fileMu.Unlock()
+
+	fileValues = values
+}
+
+func flatten(prefix string, raw map[string]interface{}, values map[string]string) error {
+	for k, v := range raw {
+		key := strings.ToUpper(k)
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if err := flatten(key, v, values); err != nil {
+				return err
+			}
+		case []interface{}:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, formatValue(item))
+			}
+			values[key] = strings.Join(items, ",")
+		case nil:
+			return fmt.Errorf("%s has no value", key)
+		default:
+			values[key] = formatValue(v)
+		}
+	}
+
+	return nil
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case time.Duration:
+		return v.String()
+	case []string:
+		return strings.Join(v, ",")
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// environment merges the config file with the process environment, which
+// takes precedence.
+func environment() map[string]string {
+	fileMu.RLock()
+	values := make(map[string]string, len(fileValues))
+	for k, v := range fileValues {
+		values[k] = v
+	}
+	fileMu.RUnlock()
+
+	for k, v := range env.ToMap(os.Environ()) {
+		values[k] = v
+	}
+
+	return values
+}
+
+func parse(cfg interface{}) error {
+	return env.ParseWithOptions(cfg, env.Options{Environment: environment()})
+}
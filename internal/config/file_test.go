@@ -0,0 +1,122 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		env     map[string]string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:    "nested yaml keys are joined",
+			file:    "config.yaml",
+			content: "rate_limit:\n  read_burst: 10\n  read_per_minute: 1.5\n",
+			want:    map[string]string{"RATE_LIMIT_READ_BURST": "10", "RATE_LIMIT_READ_PER_MINUTE": "1.5"},
+		},
+		{
+			name:    "environment takes precedence",
+			file:    "config.yml",
+			content: "logger:\n  level: debug\n  pretty: true\n",
+			env:     map[string]string{"LOGGER_LEVEL": "warn"},
+			want:    map[string]string{"LOGGER_LEVEL": "warn", "LOGGER_PRETTY": "true"},
+		},
+		{
+			name:    "toml tables",
+			file:    "config.toml",
+			content: "[webhook]\nlease = \"5m\"\n",
+			want:    map[string]string{"WEBHOOK_LEASE": "5m"},
+		},
+		{
+			name:    "lists are comma separated",
+			file:    "config.yaml",
+			content: "trusted_proxies: [10.0.0.0/8, 192.0.2.1]\n",
+			want:    map[string]string{"TRUSTED_PROXIES": "10.0.0.0/8,192.0.2.1"},
+		},
+		{
+			name:    "unknown keys are rejected",
+			file:    "config.yaml",
+			content: "rate_limit:\n  reed_burst: 10\n",
+			wantErr: "unknown keys RATE_LIMIT_REED_BURST",
+		},
+		{
+			name:    "empty values are rejected",
+			file:    "config.yaml",
+			content: "logger:\n  level:\n",
+			wantErr: "LOGGER_LEVEL has no value",
+		},
+		{
+			name:    "unsupported format",
+			file:    "config.json",
+			content: "{}",
+			wantErr: "unsupported config file format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(func() { setFileValues(nil) })
+
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			err := LoadFile(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadFile() = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFile() = %v", err)
+			}
+
+			values := environment()
+			for key, want := range tt.want {
+				if got := values[key]; got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadFileFeedsConfig(t *testing.T) {
+	t.Cleanup(func() { setFileValues(nil) })
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("rate_limit:\n  read_burst: 7\n  write_burst: 3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("RATE_LIMIT_WRITE_BURST", "5")
+
+	if err := LoadFile(path); err != nil {
+		t.Fatalf("LoadFile() = %v", err)
+	}
+
+	cfg, err := LoadRateLimitConfig()
+	if err != nil {
+		t.Fatalf("LoadRateLimitConfig() = %v", err)
+	}
+
+	if cfg.ReadBurst != 7 {
+		t.Errorf("ReadBurst = %d, want 7 from the file", cfg.ReadBurst)
+	}
+	if cfg.WriteBurst != 5 {
+		t.Errorf("WriteBurst = %d, want 5 from the environment", cfg.WriteBurst)
+	}
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+type HealthConfig struct {
+	CheckTimeout  time.Duration `env:"HEALTH_CHECK_TIMEOUT" envDefault:"2s"`
+	CacheCritical bool          `env:"HEALTH_CACHE_CRITICAL" envDefault:"false"`
+	ShutdownDelay time.Duration `env:"HEALTH_SHUTDOWN_DELAY" envDefault:"0s"`
+}
+
+func (c *HealthConfig) Validate() error {
+	return errors.Join(
+		positive("HEALTH_CHECK_TIMEOUT", c.CheckTimeout),
+		nonNegative("HEALTH_SHUTDOWN_DELAY", c.ShutdownDelay),
+	)
+}
+
+func LoadHealthConfig() (*HealthConfig, error) {
+	var cfg HealthConfig
+	if err := parse(&cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
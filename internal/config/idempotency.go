@@ -0,0 +1,31 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+type IdempotencyConfig struct {
+	TTL             time.Duration `env:"IDEMPOTENCY_TTL" envDefault:"24h"`
+	LockTimeout     time.Duration `env:"IDEMPOTENCY_LOCK_TIMEOUT" envDefault:"1m"`
+	MaxBodyBytes    int64         `env:"IDEMPOTENCY_MAX_BODY_BYTES" envDefault:"1048576"`
+	CleanupInterval time.Duration `env:"IDEMPOTENCY_CLEANUP_INTERVAL" envDefault:"1h"`
+}
+
+func (c *IdempotencyConfig) Validate() error {
+	return errors.Join(
+		positive("IDEMPOTENCY_TTL", c.TTL),
+		positive("IDEMPOTENCY_LOCK_TIMEOUT", c.LockTimeout),
+		atLeast("IDEMPOTENCY_MAX_BODY_BYTES", c.MaxBodyBytes, 1),
+		positive("IDEMPOTENCY_CLEANUP_INTERVAL", c.CleanupInterval),
+	)
+}
+
+func LoadIdempotencyConfig() (*IdempotencyConfig, error) {
+	var cfg IdempotencyConfig
+	if err := parse(&cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type ImportConfig struct {
+	MaxBytes int64 `env:"IMPORT_MAX_BYTES" envDefault:"10485760"`
+	MaxRows  int   `env:"IMPORT_MAX_ROWS" envDefault:"10000"`
+	// Files with more rows than SyncRows are imported by a background job.
+	SyncRows     int           `env:"IMPORT_SYNC_ROWS" envDefault:"100"`
+	PollInterval time.Duration `env:"IMPORT_POLL_INTERVAL" envDefault:"2s"`
+	Lease        time.Duration `env:"IMPORT_LEASE" envDefault:"5m"`
+	MaxAttempts  int           `env:"IMPORT_MAX_ATTEMPTS" envDefault:"3"`
+}
+
+func (c *ImportConfig) Validate() error {
+	var syncRows error
+	if c.SyncRows > c.MaxRows {
+		syncRows = fmt.Errorf("IMPORT_SYNC_ROWS: must not exceed IMPORT_MAX_ROWS (%d), got %d", c.MaxRows, c.SyncRows)
+	}
+
+	return errors.Join(
+		atLeast("IMPORT_MAX_BYTES", c.MaxBytes, 1),
+		atLeast("IMPORT_MAX_ROWS", c.MaxRows, 1),
+		atLeast("IMPORT_SYNC_ROWS", c.SyncRows, 0),
+		syncRows,
+		positive("IMPORT_POLL_INTERVAL", c.PollInterval),
+		positive("IMPORT_LEASE", c.Lease),
+		atLeast("IMPORT_MAX_ATTEMPTS", c.MaxAttempts, 1),
+	)
+}
+
+func LoadImportConfig() (*ImportConfig, error) {
+	var cfg ImportConfig
+	if err := parse(&cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
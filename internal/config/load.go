@@ -0,0 +1,250 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Config is the whole application configuration. Postgres and Redis are only
+// loaded when selected by DB_TYPE and CACHE_TYPE.
+type Config struct {
+	Logger       *LoggerConfig
+	Server       *ServerConfig
+	Storage      *StorageConfig
+	Postgres     *PostgresConfig
+	Redis        *RedisConfig
+	CacheBreaker *CacheBreakerConfig
+	Stats        *StatsConfig
+	Broker       *BrokerConfig
+	Webhook      *WebhookConfig
+	Tracing      *TracingConfig
+	Health       *HealthConfig
+	RateLimit    *RateLimitConfig
+	Idempotency  *IdempotencyConfig
+	Import       *ImportConfig
+	Offer        *OfferConfig
+	Moderation   *ModerationConfig
+}
+
+const redacted = "[REDACTED]"
+
+// Load reads the optional config file, parses every section from the file and
+// the environment and validates the result. All problems are reported at once.
+func Load(path string) (*Config, error) {
+	if err := LoadFile(path); err != nil {
+		return nil, err
+	}
+
+	var (
+		cfg  Config
+		errs []error
+		err  error
+	)
+
+	if cfg.Logger, err = LoadLoggerConfig(); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Server, err = LoadServerConfig(); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Storage, err = LoadStorageConfig(); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Storage != nil && cfg.Storage.DBType == "postgres" {
+		if cfg.Postgres, err = LoadPostgresConfig(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if cfg.Storage != nil && cfg.Storage.CacheType == "redis" {
+		if cfg.Redis, err = LoadRedisConfig(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if cfg.CacheBreaker, err = LoadCacheBreakerConfig(); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Stats, err = LoadStatsConfig(); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Broker, err = LoadBrokerConfig(); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Webhook, err = LoadWebhookConfig(); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Tracing, err = LoadTracingConfig(); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Health, err = LoadHealthConfig(); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.RateLimit, err = LoadRateLimitConfig(); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Idempotency, err = LoadIdempotencyConfig(); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Import, err = LoadImportConfig(); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Offer, err = LoadOfferConfig(); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Moderation, err = LoadModerationConfig(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	return &cfg, nil
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+
+	for _, section := range c.sections() {
+		if v, ok := section.value.Interface().(interface{ Validate() error }); ok {
+			errs = append(errs, v.Validate())
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Print writes the configuration in env file format with secrets redacted.
+func Print(w io.Writer, cfg *Config) error {
+	for i, section := range cfg.sections() {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "# %s\n", section.name); err != nil {
+			return err
+		}
+
+		for _, e := range section.entries() {
+			value := e.value
+			if e.secret && value != "" {
+				value = redacted
+			}
+
+			if _, err := fmt.Fprintf(w, "%s=%s\n", e.key, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Changed returns the keys whose values differ between two configurations.
+func Changed(prev, next *Config) []string {
+	prevValues := prev.values()
+
+	var changed []string
+	for key, value := range next.values() {
+		if prevValue, ok := prevValues[key]; !ok || prevValue != value {
+			changed = append(changed, key)
+		}
+	}
+
+	return changed
+}
+
+type section struct {
+	name  string
+	value reflect.Value
+}
+
+type entry struct {
+	key    string
+	value  string
+	secret bool
+}
+
+func (c *Config) sections() []section {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+
+	var sections []section
+	for i := 0; i < t.NumField(); i++ {
+		if v.Field(i).IsNil() {
+			continue
+		}
+
+		sections = append(sections, section{name: strings.ToLower(t.Field(i).Name), value: v.Field(i)})
+	}
+
+	return sections
+}
+
+func (s section) entries() []entry {
+	v := s.value.Elem()
+	t := v.Type()
+
+	entries := make([]entry, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		key, _, _ := strings.Cut(t.Field(i).Tag.Get("env"), ",")
+		if key == "" {
+			continue
+		}
+
+		entries = append(entries, entry{
+			key:    key,
+			value:  formatValue(v.Field(i).Interface()),
+			secret: t.Field(i).Tag.Get("secret") == "true",
+		})
+	}
+
+	return entries
+}
+
+func (c *Config) values() map[string]string {
+	values := make(map[string]string)
+	for _, section := range c.sections() {
+		for _, e := range section.entries() {
+			values[e.key] = e.value
+		}
+	}
+
+	return values
+}
+
+func knownKeys() map[string]struct{} {
+	all := Config{
+		Logger:       &LoggerConfig{},
+		Server:       &ServerConfig{},
+		Storage:      &StorageConfig{},
+		Postgres:     &PostgresConfig{},
+		Redis:        &RedisConfig{},
+		CacheBreaker: &CacheBreakerConfig{},
+		Stats:        &StatsConfig{},
+		Broker:       &BrokerConfig{},
+		Webhook:      &WebhookConfig{},
+		Tracing:      &TracingConfig{},
+		Health:       &HealthConfig{},
+		RateLimit:    &RateLimitConfig{},
+		Idempotency:  &IdempotencyConfig{},
+		Import:       &ImportConfig{},
+		Offer:        &OfferConfig{},
+		Moderation:   &ModerationConfig{},
+	}
+
+	keys := make(map[string]struct{})
+	for key := range all.values() {
+		keys[key] = struct{}{}
+	}
+
+	return keys
+}
@@ -0,0 +1,24 @@
+package config
+
+import "errors"
+
+type ModerationConfig struct {
+	// AutoHideReports is how many distinct users have to report an ad before
+	// it is hidden until a moderator decides, 0 never hides ads automatically.
+	AutoHideReports int `env:"MODERATION_AUTO_HIDE_REPORTS" envDefault:"5"`
+}
+
+func (c *ModerationConfig) Validate() error {
+	return errors.Join(
+		atLeast("MODERATION_AUTO_HIDE_REPORTS", c.AutoHideReports, 0),
+	)
+}
+
+func LoadModerationConfig() (*ModerationConfig, error) {
+	var cfg ModerationConfig
+	if err := parse(&cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+type OfferConfig struct {
+	// TTL is how long the other party has to answer an offer or a counter.
+	TTL            time.Duration `env:"OFFER_TTL" envDefault:"72h"`
+	ExpireInterval time.Duration `env:"OFFER_EXPIRE_INTERVAL" envDefault:"1m"`
+}
+
+func (c *OfferConfig) Validate() error {
+	return errors.Join(
+		positive("OFFER_TTL", c.TTL),
+		positive("OFFER_EXPIRE_INTERVAL", c.ExpireInterval),
+	)
+}
+
+func LoadOfferConfig() (*OfferConfig, error) {
+	var cfg OfferConfig
+	if err := parse(&cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
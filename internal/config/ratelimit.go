@@ -0,0 +1,49 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+type RateLimitConfig struct {
+	Enabled bool   `env:"RATE_LIMIT_ENABLED" envDefault:"true"`
+	Store   string `env:"RATE_LIMIT_STORE" envDefault:"redis"`
+
+	ReadPerMinute float64 `env:"RATE_LIMIT_READ_PER_MINUTE" envDefault:"300"`
+	ReadBurst     int     `env:"RATE_LIMIT_READ_BURST" envDefault:"60"`
+
+	WritePerMinute float64 `env:"RATE_LIMIT_WRITE_PER_MINUTE" envDefault:"30"`
+	WriteBurst     int     `env:"RATE_LIMIT_WRITE_BURST" envDefault:"10"`
+
+	AuthPerMinute float64 `env:"RATE_LIMIT_AUTH_PER_MINUTE" envDefault:"10"`
+	AuthBurst     int     `env:"RATE_LIMIT_AUTH_BURST" envDefault:"5"`
+}
+
+func (c *RateLimitConfig) Validate() error {
+	return errors.Join(
+		oneOf("RATE_LIMIT_STORE", c.Store, "redis", "memory"),
+		positiveRate("RATE_LIMIT_READ_PER_MINUTE", c.ReadPerMinute),
+		atLeast("RATE_LIMIT_READ_BURST", c.ReadBurst, 1),
+		positiveRate("RATE_LIMIT_WRITE_PER_MINUTE", c.WritePerMinute),
+		atLeast("RATE_LIMIT_WRITE_BURST", c.WriteBurst, 1),
+		positiveRate("RATE_LIMIT_AUTH_PER_MINUTE", c.AuthPerMinute),
+		atLeast("RATE_LIMIT_AUTH_BURST", c.AuthBurst, 1),
+	)
+}
+
+func positiveRate(key string, perMinute float64) error {
+	if perMinute <= 0 {
+		return fmt.Errorf("%s: must be greater than zero, got %v", key, perMinute)
+	}
+
+	return nil
+}
+
+func LoadRateLimitConfig() (*RateLimitConfig, error) {
+	var cfg RateLimitConfig
+	if err := parse(&cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+type StatsConfig struct {
+	ViewWindow    time.Duration `env:"STATS_VIEW_WINDOW" envDefault:"30m"`
+	FlushInterval time.Duration `env:"STATS_FLUSH_INTERVAL" envDefault:"10s"`
+	MaxRangeDays  int           `env:"STATS_MAX_RANGE_DAYS" envDefault:"366"`
+}
+
+func (c *StatsConfig) Validate() error {
+	return errors.Join(
+		positive("STATS_VIEW_WINDOW", c.ViewWindow),
+		positive("STATS_FLUSH_INTERVAL", c.FlushInterval),
+		atLeast("STATS_MAX_RANGE_DAYS", c.MaxRangeDays, 1),
+	)
+}
+
+func LoadStatsConfig() (*StatsConfig, error) {
+	var cfg StatsConfig
+	if err := parse(&cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"errors"
+)
+
+// TracingConfig configures span export. The OTLP exporter additionally honours
+// the standard OTEL_EXPORTER_OTLP_* variables (endpoint, headers, insecure).
+type TracingConfig struct {
+	Exporter    string  `env:"TRACING_EXPORTER" envDefault:"none"`
+	ServiceName string  `env:"TRACING_SERVICE_NAME" envDefault:"marketplace"`
+	SampleRatio float64 `env:"TRACING_SAMPLE_RATIO" envDefault:"1"`
+	FilePath    string  `env:"TRACING_FILE_PATH" envDefault:"traces.jsonl"`
+}
+
+func (c *TracingConfig) Validate() error {
+	var filePath error
+	if c.Exporter == "file" {
+		filePath = notEmpty("TRACING_FILE_PATH", c.FilePath)
+	}
+
+	return errors.Join(
+		oneOf("TRACING_EXPORTER", c.Exporter, "none", "otlp", "stdout", "file"),
+		notEmpty("TRACING_SERVICE_NAME", c.ServiceName),
+		between("TRACING_SAMPLE_RATIO", c.SampleRatio, 0, 1),
+		filePath,
+	)
+}
+
+func LoadTracingConfig() (*TracingConfig, error) {
+	var cfg TracingConfig
+	if err := parse(&cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
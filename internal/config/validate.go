@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The helpers below return nil when the value is valid, so Validate methods
+// can pass their results straight to errors.Join.
+
+func oneOf(key, value string, allowed ...string) error {
+	for _, a := range allowed {
+		if value == a {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s: %q is not one of %s", key, value, strings.Join(allowed, ", "))
+}
+
+func positive(key string, d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("%s: must be greater than zero, got %s", key, d)
+	}
+
+	return nil
+}
+
+func nonNegative(key string, d time.Duration) error {
+	if d < 0 {
+		return fmt.Errorf("%s: must not be negative, got %s", key, d)
+	}
+
+	return nil
+}
+
+func atLeast[T int | int32 | int64 | float64](key string, value, min T) error {
+	if value < min {
+		return fmt.Errorf("%s: must be at least %v, got %v", key, min, value)
+	}
+
+	return nil
+}
+
+func between(key string, value, min, max float64) error {
+	if value < min || value > max {
+		return fmt.Errorf("%s: must be between %v and %v, got %v", key, min, max, value)
+	}
+
+	return nil
+}
+
+func port(key, value string) error {
+	p, err := strconv.Atoi(value)
+	if err != nil || p < 1 || p > 65535 {
+		return fmt.Errorf("%s: %q is not a valid port", key, value)
+	}
+
+	return nil
+}
+
+func notEmpty(key, value string) error {
+	if value == "" {
+		return fmt.Errorf("%s: must not be empty", key)
+	}
+
+	return nil
+}
+
+func ipsOrCIDRs(key string, values []string) error {
+	for _, value := range values {
+		if _, _, err := net.ParseCIDR(value); err == nil {
+			continue
+		}
+		if net.ParseIP(value) == nil {
+			return fmt.Errorf("%s: %q is not an IP address or CIDR", key, value)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+type WebhookConfig struct {
+	PollInterval time.Duration `env:"WEBHOOK_POLL_INTERVAL" envDefault:"2s"`
+	BatchSize    int           `env:"WEBHOOK_BATCH_SIZE" envDefault:"50"`
+	Timeout      time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"10s"`
+	MaxAttempts  int           `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"8"`
+	BackoffBase  time.Duration `env:"WEBHOOK_BACKOFF_BASE" envDefault:"10s"`
+	BackoffMax   time.Duration `env:"WEBHOOK_BACKOFF_MAX" envDefault:"1h"`
+	Lease        time.Duration `env:"WEBHOOK_LEASE" envDefault:"3m"`
+	Workers      int           `env:"WEBHOOK_WORKERS" envDefault:"4"`
+	// AllowPrivateTargets lets webhooks reach local and private addresses,
+	// for a receiver on the developer's machine only.
+	AllowPrivateTargets bool `env:"WEBHOOK_ALLOW_PRIVATE_TARGETS" envDefault:"false"`
+}
+
+func (c *WebhookConfig) Validate() error {
+	var backoff error
+	if c.BackoffBase > c.BackoffMax {
+		backoff = fmt.Errorf("WEBHOOK_BACKOFF_BASE: must not exceed WEBHOOK_BACKOFF_MAX (%s), got %s", c.BackoffMax, c.BackoffBase)
+	}
+
+	// a batch has to finish within its lease, or another replica claims the
+	// deliveries still being sent
+	var lease error
+	if c.BatchSize >= 1 && c.Workers >= 1 {
+		rounds := int(math.Ceil(float64(c.BatchSize) / float64(c.Workers)))
+		if batch := time.Duration(rounds) * c.Timeout; c.Lease <= batch {
+			lease = fmt.Errorf("WEBHOOK_LEASE: must exceed the longest batch, ceil(WEBHOOK_BATCH_SIZE/WEBHOOK_WORKERS)*WEBHOOK_TIMEOUT (%s), got %s", batch, c.Lease)
+		}
+	}
+
+	return errors.Join(
+		positive("WEBHOOK_POLL_INTERVAL", c.PollInterval),
+		atLeast("WEBHOOK_BATCH_SIZE", c.BatchSize, 1),
+		positive("WEBHOOK_TIMEOUT", c.Timeout),
+		atLeast("WEBHOOK_MAX_ATTEMPTS", c.MaxAttempts, 1),
+		positive("WEBHOOK_BACKOFF_BASE", c.BackoffBase),
+		backoff,
+		positive("WEBHOOK_LEASE", c.Lease),
+		lease,
+		atLeast("WEBHOOK_WORKERS", c.Workers, 1),
+	)
+}
+
+func LoadWebhookConfig() (*WebhookConfig, error) {
+	var cfg WebhookConfig
+	if err := parse(&cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
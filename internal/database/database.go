@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"errors"
+	"time"
 
 	"vk-internship/internal/database/model"
 )
@@ -10,14 +11,109 @@ import (
 type Database interface {
 	Ping(ctx context.Context) error
 
-	CreateUser(user *model.User) (*model.User, error)
-	GetUserByUsername(username string) (*model.User, error)
+	CreateUser(ctx context.Context, user *model.User) (*model.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*model.User, error)
+	GetUserProfile(ctx context.Context, username string) (*model.User, error)
+	UpdateUserProfile(ctx context.Context, userID string, profile model.UserProfile) (*model.User, error)
+	ListUsers(ctx context.Context, query string, page, pageSize int) ([]*model.User, int, error)
+	SetUserDisabled(ctx context.Context, username string, disabled bool) (*model.User, error)
+	// IsUserActive is false for disabled and deleted users.
+	IsUserActive(ctx context.Context, userID string) (bool, error)
+	SetUserModerator(ctx context.Context, username string, moderator bool) (*model.User, error)
+	// IsModerator is false for disabled users.
+	IsModerator(ctx context.Context, userID string) (bool, error)
+	// CreateUsers and CreateAds insert prepared rows in bulk, ids and
+	// timestamps included. No webhook events are produced.
+	CreateUsers(ctx context.Context, users []*model.User) error
+	CreateAds(ctx context.Context, ads []*model.Advertisement) error
 
-	CreateAd(ad *model.Advertisement) (*model.Advertisement, error)
-	GetAds(ctx context.Context, sortBy, order string, minPrice, maxPrice *int, page, pageSize int) ([]*model.Advertisement, int, error)
+	CreateAd(ctx context.Context, ad *model.Advertisement) (*model.Advertisement, error)
+	// GetAds and ExportAds return only active ads unless filter.Statuses
+	// says otherwise.
+	GetAds(ctx context.Context, filter model.AdFilter, page, pageSize int) ([]*model.Advertisement, int, error)
+	// ExportAds calls fn for every ad matching filter, reading them from a
+	// cursor in batches. Returning an error from fn stops the export.
+	ExportAds(ctx context.Context, filter model.AdFilter, fn func(*model.Advertisement) error) error
 	GetAd(ctx context.Context, id string) (*model.Advertisement, error)
 	UpdateAd(ctx context.Context, ad *model.Advertisement) (*model.Advertisement, error)
 	DeleteAd(ctx context.Context, id, authorID string) error
+	DeleteAdByID(ctx context.Context, id string) (*model.Advertisement, error)
+	ReassignAd(ctx context.Context, id, username string) (*model.Advertisement, error)
+
+	AddAdViews(ctx context.Context, views []model.AdViewCount) error
+	GetAdStats(ctx context.Context, adID string, from, to time.Time) ([]*model.AdDailyStats, error)
+
+	StartConversation(ctx context.Context, adID, buyerID, body string) (*model.Conversation, *model.Message, error)
+	SendMessage(ctx context.Context, conversationID, senderID, body string) (*model.Message, error)
+	GetConversations(ctx context.Context, userID string, page, pageSize int) ([]*model.Conversation, int, error)
+	GetMessages(ctx context.Context, conversationID, userID string, page, pageSize int) ([]*model.Message, int, error)
+	MarkConversationRead(ctx context.Context, conversationID, userID string) (int, error)
+	GetUnreadCount(ctx context.Context, userID string) (int, error)
+	BlockUser(ctx context.Context, blockerID, blockedUsername string) error
+	UnblockUser(ctx context.Context, blockerID, blockedUsername string) error
+
+	// CreateReview and RemoveReview keep the seller's rating up to date in the
+	// same transaction. Only a buyer who wrote to the seller about the ad may
+	// review it. RemoveReview records the removal in the moderation trail, an
+	// empty moderatorID stands for an operator.
+	CreateReview(ctx context.Context, review *model.Review) (*model.Review, error)
+	GetUserReviews(ctx context.Context, sellerID string, page, pageSize int) ([]*model.Review, int, error)
+	ReplyToReview(ctx context.Context, id, sellerID, reply string) (*model.Review, error)
+	RemoveReview(ctx context.Context, id, moderatorID, reason string) (*model.Review, error)
+
+	// Offers past ExpiresAt are reported and treated as expired even before
+	// ExpireOffers closes them. Open offers are rejected when their ad is
+	// sold, deleted or reassigned.
+	CreateOffer(ctx context.Context, offer *model.Offer) (*model.Offer, error)
+	GetOffer(ctx context.Context, id, userID string) (*model.Offer, error)
+	GetOffers(ctx context.Context, userID, role, status string, page, pageSize int) ([]*model.Offer, int, error)
+	RespondToOffer(ctx context.Context, id, userID, action string, counterAmount int, expiresAt time.Time) (*model.Offer, error)
+	ExpireOffers(ctx context.Context) (int, error)
+
+	// ReportAd files the report into the open case of the ad and hides the ad
+	// once the case has autoHideReports reports, 0 never hides it. The change
+	// is returned if the ad was hidden.
+	ReportAd(ctx context.Context, report *model.AdReport, autoHideReports int) (*model.AdReport, *model.AdChange, error)
+	GetModerationCases(ctx context.Context, status string, page, pageSize int) ([]*model.ModerationCase, int, error)
+	GetModerationCase(ctx context.Context, id string) (*model.ModerationCase, error)
+	ClaimModerationCase(ctx context.Context, id, moderatorID string) (*model.ModerationCase, error)
+	// ResolveModerationCase applies the resolution to the ad of a case claimed
+	// by the moderator. The change is returned if the ad status changed.
+	ResolveModerationCase(ctx context.Context, id, moderatorID, resolution, note string) (*model.ModerationCase, *model.AdChange, error)
+	GetModerationActions(ctx context.Context, moderatorUsername string, page, pageSize int) ([]*model.ModerationAction, int, error)
+
+	CreateSavedSearch(ctx context.Context, search *model.SavedSearch) (*model.SavedSearch, error)
+	GetSavedSearches(ctx context.Context, userID string) ([]*model.SavedSearch, error)
+	GetSavedSearch(ctx context.Context, id, userID string) (*model.SavedSearch, error)
+	UpdateSavedSearch(ctx context.Context, search *model.SavedSearch) (*model.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, id, userID string) error
+	GetMatchingSavedSearches(ctx context.Context, ad *model.Advertisement) ([]*model.SavedSearch, error)
+
+	CreateNotifications(ctx context.Context, notifications []*model.Notification) error
+	GetNotifications(ctx context.Context, userID string, unreadOnly bool, page, pageSize int) ([]*model.Notification, int, error)
+	GetUnreadNotificationsCount(ctx context.Context, userID string) (int, error)
+	MarkNotificationRead(ctx context.Context, id, userID string) error
+	MarkAllNotificationsRead(ctx context.Context, userID string) (int, error)
+
+	CreateWebhook(ctx context.Context, webhook *model.Webhook) (*model.Webhook, error)
+	GetWebhooks(ctx context.Context, userID string) ([]*model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id, userID string) error
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookDelivery, error)
+	MarkWebhookDelivered(ctx context.Context, id string, statusCode int) error
+	MarkWebhookFailed(ctx context.Context, id string, statusCode *int, lastError string, nextAttemptAt time.Time, dead bool) error
+	GetDeadWebhookDeliveries(ctx context.Context, userID string, page, pageSize int) ([]*model.WebhookDelivery, int, error)
+	RetryWebhookDelivery(ctx context.Context, id, userID string) error
+
+	AcquireIdempotencyKey(ctx context.Context, userID, key, fingerprint string, lock, ttl time.Duration) (*model.IdempotencyRecord, bool, error)
+	CompleteIdempotencyKey(ctx context.Context, userID, key string, status int, headers map[string][]string, body []byte) error
+	ReleaseIdempotencyKey(ctx context.Context, userID, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int, error)
+
+	CreateImportJob(ctx context.Context, job *model.ImportJob) (*model.ImportJob, error)
+	GetImportJob(ctx context.Context, id, userID string) (*model.ImportJob, error)
+	ClaimImportJobs(ctx context.Context, limit int, lease time.Duration, maxAttempts int) ([]string, error)
+	ExecuteImportJob(ctx context.Context, id string) (*model.ImportJob, []*model.Advertisement, error)
+	FailImportJob(ctx context.Context, id, reason string) (*model.ImportJob, error)
 
 	Close()
 }
@@ -27,4 +123,29 @@ var (
 	ErrUserNotFound               = errors.New("user not found")
 	ErrAdNotFound                 = errors.New("advertisement not found")
 	ErrAdNotFoundOrNotOwnedByUser = errors.New("advertisement not found or not owned by user")
+	ErrCannotMessageOwnAd         = errors.New("cannot start conversation on own advertisement")
+	ErrConversationNotFound       = errors.New("conversation not found")
+	ErrUserBlocked                = errors.New("user is blocked")
+	ErrCannotBlockSelf            = errors.New("cannot block yourself")
+	ErrSavedSearchNotFound        = errors.New("saved search not found")
+	ErrNotificationNotFound       = errors.New("notification not found")
+	ErrWebhookNotFound            = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound    = errors.New("webhook delivery not found")
+	ErrImportJobNotFound          = errors.New("import job not found")
+	ErrReviewNotFound             = errors.New("review not found")
+	ErrReviewExists               = errors.New("ad already reviewed")
+	ErrCannotReviewOwnAd          = errors.New("cannot review own advertisement")
+	ErrReviewNotAllowed           = errors.New("only buyers who contacted the seller can review")
+	ErrOfferNotFound              = errors.New("offer not found")
+	ErrOfferExists                = errors.New("open offer already exists")
+	ErrCannotOfferOwnAd           = errors.New("cannot make an offer on own advertisement")
+	ErrOfferExpired               = errors.New("offer expired")
+	ErrOfferActionNotAllowed      = errors.New("offer cannot be answered this way")
+	ErrAdHidden                   = errors.New("advertisement is hidden by moderation")
+	ErrAdAlreadyReported          = errors.New("advertisement already reported")
+	ErrCannotReportOwnAd          = errors.New("cannot report own advertisement")
+	ErrModerationCaseNotFound     = errors.New("moderation case not found")
+	ErrModerationCaseClaimed      = errors.New("moderation case claimed by another moderator")
+	ErrModerationCaseNotClaimed   = errors.New("moderation case not claimed by the moderator")
+	ErrModerationCaseResolved     = errors.New("moderation case already resolved")
 )
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -9,6 +10,54 @@ type User struct {
 	Username  string    `json:"username"`
 	Password  string    `json:"password"`
 	CreatedAt time.Time `json:"created_at"`
+	// DisabledAt is set when an operator disabled the account, disabled
+	// users cannot log in.
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	// AdsCount is filled by ListUsers and GetUserProfile, ActiveAdsCount
+	// only by GetUserProfile.
+	AdsCount       int `json:"ads_count,omitempty"`
+	ActiveAdsCount int `json:"active_ads_count,omitempty"`
+	// Rating is the average of the user's reviews as a seller, nil until
+	// the first review. Filled with ReviewsCount by GetUserProfile.
+	Rating       *float64 `json:"rating,omitempty"`
+	ReviewsCount int      `json:"reviews_count,omitempty"`
+	// Moderator users work the moderation queue. Filled by ListUsers and
+	// the operator commands.
+	Moderator bool `json:"moderator,omitempty"`
+	UserProfile
+}
+
+// UserProfile is the part of a user the user edits themselves. Empty fields
+// are not set.
+type UserProfile struct {
+	DisplayName     string `json:"display_name"`
+	Bio             string `json:"bio"`
+	AvatarURL       string `json:"avatar_url"`
+	City            string `json:"city"`
+	Phone           string `json:"phone"`
+	PhoneVisibility string `json:"phone_visibility"`
+}
+
+// PhoneVisibility says who sees the phone besides its owner.
+const (
+	PhoneVisibilityHidden     = "hidden"
+	PhoneVisibilityRegistered = "registered"
+	PhoneVisibilityPublic     = "public"
+)
+
+// VisiblePhone returns the phone as the user viewerID sees it, "" for
+// anonymous viewers.
+func (p UserProfile) VisiblePhone(ownerID, viewerID string) string {
+	switch {
+	case viewerID != "" && viewerID == ownerID:
+		return p.Phone
+	case p.PhoneVisibility == PhoneVisibilityPublic:
+		return p.Phone
+	case p.PhoneVisibility == PhoneVisibilityRegistered && viewerID != "":
+		return p.Phone
+	default:
+		return ""
+	}
 }
 
 type Advertisement struct {
@@ -19,6 +68,399 @@ type Advertisement struct {
 	Description    string    `json:"description"`
 	ImageURL       string    `json:"image_url"`
 	Price          int       `json:"price"`
+	Status         string    `json:"status"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
+	// Author is filled when the ad is read together with its author. It is
+	// never serialized, the phone must not reach webhooks or the cache.
+	Author UserProfile `json:"-"`
+}
+
+// Only active ads are shown to other users, the rest are visible to their
+// author alone. Hidden ads are taken down by moderation and only a moderator
+// can bring them back.
+const (
+	AdStatusActive   = "active"
+	AdStatusDraft    = "draft"
+	AdStatusInactive = "inactive"
+	AdStatusSold     = "sold"
+	AdStatusHidden   = "hidden"
+)
+
+var AdStatuses = []string{AdStatusActive, AdStatusDraft, AdStatusInactive, AdStatusSold, AdStatusHidden}
+
+// AdStatusEvent returns the event that an ad moving from one status to
+// another is for those who see only active ads, or "" if it is invisible to
+// them. An empty status means the ad does not exist.
+func AdStatusEvent(from, to string) string {
+	switch {
+	case from != AdStatusActive && to == AdStatusActive:
+		return EventAdCreated
+	case from == AdStatusActive && to == AdStatusActive:
+		return EventAdUpdated
+	case from == AdStatusActive:
+		return EventAdDeleted
+	default:
+		return ""
+	}
+}
+
+// AdFilter selects and orders ads. Prices are in kopecks, an empty AuthorID
+// means ads of every author and empty Statuses means active ads only.
+type AdFilter struct {
+	SortBy   string
+	Order    string
+	MinPrice *int
+	MaxPrice *int
+	AuthorID string
+	Statuses []string
+}
+
+// Review is a buyer's review of a seller. AdID and AdCaption are nil once the
+// ad is deleted, the review stays with the seller.
+type Review struct {
+	ID             string     `json:"id"`
+	AdID           *string    `json:"ad_id"`
+	AdCaption      *string    `json:"ad_caption"`
+	SellerID       string     `json:"seller_id"`
+	SellerUsername string     `json:"seller_username"`
+	AuthorID       string     `json:"author_id"`
+	AuthorUsername string     `json:"author_username"`
+	Rating         int        `json:"rating"`
+	Body           string     `json:"body"`
+	Reply          *string    `json:"reply"`
+	RepliedAt      *time.Time `json:"replied_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+const (
+	NotificationReviewCreated = "review.created"
+	NotificationReviewReplied = "review.replied"
+)
+
+// Offer is a buyer's price proposal for an ad. Amounts are in kopecks,
+// CounterAmount is set once the seller counters. AdID and AdCaption are nil
+// once the ad is deleted.
+type Offer struct {
+	ID             string    `json:"id"`
+	AdID           *string   `json:"ad_id"`
+	AdCaption      *string   `json:"ad_caption"`
+	BuyerID        string    `json:"buyer_id"`
+	BuyerUsername  string    `json:"buyer_username"`
+	SellerID       string    `json:"seller_id"`
+	SellerUsername string    `json:"seller_username"`
+	Amount         int       `json:"amount"`
+	CounterAmount  *int      `json:"counter_amount"`
+	Status         string    `json:"status"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// A pending offer waits for the seller, a countered one for the buyer. The
+// other statuses are final.
+const (
+	OfferStatusPending   = "pending"
+	OfferStatusCountered = "countered"
+	OfferStatusAccepted  = "accepted"
+	OfferStatusRejected  = "rejected"
+	OfferStatusExpired   = "expired"
+)
+
+var OfferStatuses = []string{OfferStatusPending, OfferStatusCountered, OfferStatusAccepted, OfferStatusRejected, OfferStatusExpired}
+
+// An offer is listed for the user as the buyer or as the seller.
+const (
+	OfferRoleBuyer  = "buyer"
+	OfferRoleSeller = "seller"
+)
+
+const (
+	OfferActionAccept  = "accept"
+	OfferActionReject  = "reject"
+	OfferActionCounter = "counter"
+)
+
+// NextOfferStatus returns the status an offer moves to when the seller (or
+// the buyer) takes the action, or "" if it is not their turn or the action
+// is not allowed in the status.
+func NextOfferStatus(status, action string, seller bool) string {
+	turn := (status == OfferStatusPending && seller) || (status == OfferStatusCountered && !seller)
+	if !turn {
+		return ""
+	}
+
+	switch action {
+	case OfferActionAccept:
+		return OfferStatusAccepted
+	case OfferActionReject:
+		return OfferStatusRejected
+	case OfferActionCounter:
+		if seller {
+			return OfferStatusCountered
+		}
+	}
+
+	return ""
+}
+
+const (
+	NotificationOfferCreated   = "offer.created"
+	NotificationOfferCountered = "offer.countered"
+	NotificationOfferAccepted  = "offer.accepted"
+	NotificationOfferRejected  = "offer.rejected"
+	NotificationOfferExpired   = "offer.expired"
+)
+
+// AdReport is a user's complaint about an ad, filed into the open moderation
+// case of the ad.
+type AdReport struct {
+	ID               string    `json:"id"`
+	CaseID           string    `json:"case_id"`
+	AdID             *string   `json:"ad_id"`
+	ReporterID       string    `json:"reporter_id"`
+	ReporterUsername string    `json:"reporter_username"`
+	Reason           string    `json:"reason"`
+	Comment          string    `json:"comment"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+const (
+	ReportReasonScam       = "scam"
+	ReportReasonProhibited = "prohibited"
+	ReportReasonSpam       = "spam"
+	ReportReasonOffensive  = "offensive"
+	ReportReasonDuplicate  = "duplicate"
+	ReportReasonOther      = "other"
+)
+
+// ModerationCase collects the reports about an ad until a moderator resolves
+// it, further reports open a new case. The ad fields are nil once the ad is
+// deleted.
+type ModerationCase struct {
+	ID                string         `json:"id"`
+	AdID              *string        `json:"ad_id"`
+	AdCaption         *string        `json:"ad_caption"`
+	AdStatus          *string        `json:"ad_status"`
+	AuthorUsername    *string        `json:"author_username"`
+	Status            string         `json:"status"`
+	ReportsCount      int            `json:"reports_count"`
+	Reasons           map[string]int `json:"reasons"`
+	AutoHidden        bool           `json:"auto_hidden"`
+	ModeratorID       *string        `json:"moderator_id"`
+	ModeratorUsername *string        `json:"moderator_username"`
+	ClaimedAt         *time.Time     `json:"claimed_at"`
+	Resolution        *string        `json:"resolution"`
+	Note              *string        `json:"note"`
+	ResolvedAt        *time.Time     `json:"resolved_at"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	// Reports and Actions are filled by GetModerationCase only.
+	Reports []*AdReport         `json:"reports,omitempty"`
+	Actions []*ModerationAction `json:"actions,omitempty"`
+}
+
+// A case is open until a moderator claims it and resolved once they decide.
+const (
+	ModerationCaseOpen     = "open"
+	ModerationCaseClaimed  = "claimed"
+	ModerationCaseResolved = "resolved"
+)
+
+var ModerationCaseStatuses = []string{ModerationCaseOpen, ModerationCaseClaimed, ModerationCaseResolved}
+
+const (
+	ModerationResolutionHide    = "hide"
+	ModerationResolutionDelete  = "delete"
+	ModerationResolutionDismiss = "dismiss"
+)
+
+// ModerationAction is an entry of the moderation audit trail. ModeratorID is
+// nil for automatic actions and for operators.
+type ModerationAction struct {
+	ID                string    `json:"id"`
+	CaseID            *string   `json:"case_id"`
+	AdID              *string   `json:"ad_id"`
+	ReviewID          *string   `json:"review_id"`
+	ModeratorID       *string   `json:"moderator_id"`
+	ModeratorUsername *string   `json:"moderator_username"`
+	Action            string    `json:"action"`
+	Note              string    `json:"note"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// Besides the resolutions, the trail records these actions.
+const (
+	ModerationActionClaim        = "claim"
+	ModerationActionAutoHide     = "auto_hide"
+	ModerationActionRemoveReview = "remove_review"
+)
+
+// AdChange is an ad whose status a moderation step changed, To is empty if
+// the ad was deleted. The statuses are as for AdStatusEvent.
+type AdChange struct {
+	Ad   *Advertisement
+	From string
+	To   string
+}
+
+// AdDailyStats holds the counters of an ad for one day. Favorites stays 0
+// until ads can be added to favorites.
+type AdDailyStats struct {
+	Day           time.Time `json:"day"`
+	Views         int64     `json:"views"`
+	Favorites     int64     `json:"favorites"`
+	ContactClicks int64     `json:"contact_clicks"`
+}
+
+type AdViewCount struct {
+	AdID  string    `json:"ad_id"`
+	Day   time.Time `json:"day"`
+	Views int64     `json:"views"`
+}
+
+type Conversation struct {
+	ID             string    `json:"id"`
+	AdID           *string   `json:"ad_id"`
+	AdCaption      *string   `json:"ad_caption"`
+	BuyerID        string    `json:"buyer_id"`
+	BuyerUsername  string    `json:"buyer_username"`
+	SellerID       string    `json:"seller_id"`
+	SellerUsername string    `json:"seller_username"`
+	CreatedAt      time.Time `json:"created_at"`
+	LastMessageAt  time.Time `json:"last_message_at"`
+	LastMessage    *Message  `json:"last_message"`
+	UnreadCount    int       `json:"unread_count"`
+}
+
+type Message struct {
+	ID             string     `json:"id"`
+	ConversationID string     `json:"conversation_id"`
+	SenderID       string     `json:"sender_id"`
+	Body           string     `json:"body"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ReadAt         *time.Time `json:"read_at"`
+}
+
+type SavedSearch struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	MinPrice  *int      `json:"min_price"`
+	MaxPrice  *int      `json:"max_price"`
+	SortBy    string    `json:"sort_by"`
+	Order     string    `json:"order"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Notification struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"user_id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+	ReadAt    *time.Time      `json:"read_at"`
+}
+
+const (
+	EventAdCreated = "ad.created"
+	EventAdUpdated = "ad.updated"
+	EventAdDeleted = "ad.deleted"
+)
+
+type Webhook struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookEvent struct {
+	Type       string         `json:"type"`
+	OccurredAt time.Time      `json:"occurred_at"`
+	Ad         *Advertisement `json:"ad"`
+}
+
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscription_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	LastStatusCode *int            `json:"last_status_code"`
+	LastError      *string         `json:"last_error"`
+	CreatedAt      time.Time       `json:"created_at"`
+	URL            string          `json:"url"`
+	Secret         string          `json:"-"`
+}
+
+const (
+	IdempotencyInProgress = "in_progress"
+	IdempotencyCompleted  = "completed"
+)
+
+type IdempotencyRecord struct {
+	UserID          string              `json:"user_id"`
+	Key             string              `json:"key"`
+	Fingerprint     string              `json:"fingerprint"`
+	Status          string              `json:"status"`
+	ResponseStatus  *int                `json:"response_status"`
+	ResponseHeaders map[string][]string `json:"response_headers"`
+	ResponseBody    []byte              `json:"response_body"`
+	CreatedAt       time.Time           `json:"created_at"`
+}
+
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+
+	ImportModeAtomic  = "atomic"
+	ImportModePartial = "partial"
+
+	ImportStatusPending   = "pending"
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+
+	ImportRowPending = "pending"
+	ImportRowCreated = "created"
+	ImportRowInvalid = "invalid"
+	ImportRowSkipped = "skipped"
+)
+
+type ImportJob struct {
+	ID          string      `json:"id"`
+	UserID      string      `json:"user_id"`
+	Format      string      `json:"format"`
+	Mode        string      `json:"mode"`
+	Status      string      `json:"status"`
+	TotalRows   int         `json:"total_rows"`
+	CreatedRows int         `json:"created_rows"`
+	FailedRows  int         `json:"failed_rows"`
+	Rows        []ImportRow `json:"rows"`
+	Error       *string     `json:"error"`
+	CreatedAt   time.Time   `json:"created_at"`
+	FinishedAt  *time.Time  `json:"finished_at"`
+}
+
+// ImportRow is the result of one row of an import file. Valid rows keep the
+// ad to insert until the job has run.
+type ImportRow struct {
+	Line   int                `json:"line"`
+	Status string             `json:"status"`
+	AdID   string             `json:"ad_id,omitempty"`
+	Errors []ImportFieldError `json:"errors,omitempty"`
+	Ad     *Advertisement     `json:"ad,omitempty"`
+}
+
+type ImportFieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
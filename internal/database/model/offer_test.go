@@ -0,0 +1,34 @@
+package model
+
+import "testing"
+
+func TestNextOfferStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		action string
+		seller bool
+		want   string
+	}{
+		{name: "seller accepts pending", status: OfferStatusPending, action: OfferActionAccept, seller: true, want: OfferStatusAccepted},
+		{name: "seller rejects pending", status: OfferStatusPending, action: OfferActionReject, seller: true, want: OfferStatusRejected},
+		{name: "seller counters pending", status: OfferStatusPending, action: OfferActionCounter, seller: true, want: OfferStatusCountered},
+		{name: "buyer cannot answer own pending offer", status: OfferStatusPending, action: OfferActionAccept},
+		{name: "buyer accepts counter", status: OfferStatusCountered, action: OfferActionAccept, want: OfferStatusAccepted},
+		{name: "buyer rejects counter", status: OfferStatusCountered, action: OfferActionReject, want: OfferStatusRejected},
+		{name: "buyer cannot counter", status: OfferStatusCountered, action: OfferActionCounter},
+		{name: "seller waits for buyer after counter", status: OfferStatusCountered, action: OfferActionAccept, seller: true},
+		{name: "accepted is closed", status: OfferStatusAccepted, action: OfferActionReject, seller: true},
+		{name: "rejected is closed", status: OfferStatusRejected, action: OfferActionAccept, seller: true},
+		{name: "expired is closed", status: OfferStatusExpired, action: OfferActionAccept},
+		{name: "unknown action", status: OfferStatusPending, action: "withdraw", seller: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NextOfferStatus(tt.status, tt.action, tt.seller); got != tt.want {
+				t.Errorf("NextOfferStatus(%q, %q, %v) = %q, want %q", tt.status, tt.action, tt.seller, got, tt.want)
+			}
+		})
+	}
+}
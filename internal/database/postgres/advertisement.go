@@ -14,78 +14,169 @@ import (
 	"vk-internship/internal/database/model"
 )
 
-func (p *PostgresDB) CreateAd(ad *model.Advertisement) (*model.Advertisement, error) {
+const exportFetchSize = 500
+
+func (p *PostgresDB) CreateAd(ctx context.Context, ad *model.Advertisement) (*model.Advertisement, error) {
 	const query = `
-		INSERT INTO advertisements (author_id, caption, description, image_url, price)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, author_id, caption, description, image_url, price, created_at
+		WITH inserted AS (
+			INSERT INTO advertisements (author_id, caption, description, image_url, price, status)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, author_id, caption, description, image_url, price, status, created_at, updated_at
+		)
+		SELECT i.id, i.author_id, u.username, u.display_name, u.avatar_url, u.city, u.phone, u.phone_visibility,
+			i.caption, i.description, i.image_url, i.price, i.status, i.created_at, i.updated_at
+		FROM inserted i
+		JOIN users u ON u.id = i.author_id
 	`
 
-	ctx, cancel := context.WithTimeout(context.TODO(), p.timeout)
+	status := ad.Status
+	if status == "" {
+		status = model.AdStatusActive
+	}
+
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	var createdAd model.Advertisement
-	err := p.db.QueryRow(ctx, query,
+	err = tx.QueryRow(ctx, query,
 		ad.AuthorID,
 		ad.Caption,
 		ad.Description,
 		ad.ImageURL,
 		ad.Price,
+		status,
 	).Scan(&createdAd.ID,
 		&createdAd.AuthorID,
+		&createdAd.AuthorUsername,
+		&createdAd.Author.DisplayName,
+		&createdAd.Author.AvatarURL,
+		&createdAd.Author.City,
+		&createdAd.Author.Phone,
+		&createdAd.Author.PhoneVisibility,
 		&createdAd.Caption,
 		&createdAd.Description,
 		&createdAd.ImageURL,
 		&createdAd.Price,
-		&createdAd.CreatedAt)
+		&createdAd.Status,
+		&createdAd.CreatedAt,
+		&createdAd.UpdatedAt)
 
 	if err != nil {
 		return nil, fmt.Errorf("insert ad failed: %w", err)
 	}
 
+	if err := enqueueAdStatusEvent(ctx, tx, "", createdAd.Status, &createdAd); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return &createdAd, nil
 }
 
-func (p *PostgresDB) GetAds(ctx context.Context, sortBy, order string, minPrice, maxPrice *int, page, pageSize int) ([]*model.Advertisement, int, error) {
+func (p *PostgresDB) CreateAds(ctx context.Context, ads []*model.Advertisement) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if len(ads) == 0 {
+		return nil
+	}
+
+	p.log.Debugf("create ads", map[string]interface{}{"count": len(ads)})
+
+	rows := make([][]interface{}, len(ads))
+	for i, ad := range ads {
+		rows[i] = []interface{}{ad.ID, ad.AuthorID, ad.Caption, ad.Description, ad.ImageURL, ad.Price, ad.CreatedAt, ad.UpdatedAt}
+	}
+
+	_, err := p.db.CopyFrom(ctx,
+		pgx.Identifier{"advertisements"},
+		[]string{"id", "author_id", "caption", "description", "image_url", "price", "created_at", "updated_at"},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create ads: %w", err)
+	}
+
+	return nil
+}
+
+// adFilterClauses builds the WHERE and ORDER BY clauses of a filter. Ads are
+// selected as a and their authors as u.
+func adFilterClauses(filter model.AdFilter) (string, string, []interface{}) {
 	var params []interface{}
 	conditions := []string{"1=1"}
 
-	if minPrice != nil {
+	if filter.MinPrice != nil {
 		conditions = append(conditions, fmt.Sprintf("a.price >= $%d", len(params)+1))
-		params = append(params, *minPrice)
+		params = append(params, *filter.MinPrice)
 	}
 
-	if maxPrice != nil {
+	if filter.MaxPrice != nil {
 		conditions = append(conditions, fmt.Sprintf("a.price <= $%d", len(params)+1))
-		params = append(params, *maxPrice)
+		params = append(params, *filter.MaxPrice)
 	}
 
-	whereClause := strings.Join(conditions, " AND ")
+	if filter.AuthorID != "" {
+		conditions = append(conditions, fmt.Sprintf("a.author_id = $%d", len(params)+1))
+		params = append(params, filter.AuthorID)
+	}
+
+	statuses := filter.Statuses
+	if len(statuses) == 0 {
+		statuses = []string{model.AdStatusActive}
+	}
+	conditions = append(conditions, fmt.Sprintf("a.status = ANY($%d)", len(params)+1))
+	params = append(params, statuses)
+
+	sortBy := filter.SortBy
+	validSortFields := map[string]bool{"created_at": true, "price": true}
+	if _, ok := validSortFields[sortBy]; !ok {
+		sortBy = "created_at"
+	}
+	order := strings.ToUpper(filter.Order)
+	if order != "ASC" && order != "DESC" {
+		order = "DESC"
+	}
+
+	return strings.Join(conditions, " AND "), fmt.Sprintf("a.%s %s, a.id %s", sortBy, order, order), params
+}
+
+func (p *PostgresDB) GetAds(ctx context.Context, filter model.AdFilter, page, pageSize int) ([]*model.Advertisement, int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	whereClause, orderClause, params := adFilterClauses(filter)
 
 	query := fmt.Sprintf(`
         SELECT 
             a.id, 
             a.author_id, 
             u.username as author_username, 
+            u.display_name,
+            u.avatar_url,
+            u.city,
+            u.phone,
+            u.phone_visibility,
             a.caption, 
             a.description, 
             a.image_url, 
             a.price, 
+            a.status,
             a.created_at,
             COUNT(*) OVER() AS total_count
         FROM advertisements a
         JOIN users u ON a.author_id = u.id
-        WHERE %s`, whereClause)
-
-	validSortFields := map[string]bool{"created_at": true, "price": true}
-	if _, ok := validSortFields[sortBy]; !ok {
-		sortBy = "created_at"
-	}
-	order = strings.ToUpper(order)
-	if order != "ASC" && order != "DESC" {
-		order = "DESC"
-	}
-	query += fmt.Sprintf(" ORDER BY a.%s %s", sortBy, order)
+        WHERE %s
+        ORDER BY %s`, whereClause, orderClause)
 
 	if page < 1 {
 		page = 1
@@ -119,10 +210,16 @@ func (p *PostgresDB) GetAds(ctx context.Context, sortBy, order string, minPrice,
 			&ad.ID,
 			&ad.AuthorID,
 			&ad.AuthorUsername,
+			&ad.Author.DisplayName,
+			&ad.Author.AvatarURL,
+			&ad.Author.City,
+			&ad.Author.Phone,
+			&ad.Author.PhoneVisibility,
 			&ad.Caption,
 			&ad.Description,
 			&ad.ImageURL,
 			&ad.Price,
+			&ad.Status,
 			&ad.CreatedAt,
 			&totalCount,
 		)
@@ -140,7 +237,115 @@ func (p *PostgresDB) GetAds(ctx context.Context, sortBy, order string, minPrice,
 	return ads, totalCount, nil
 }
 
+// ExportAds reads the ads through a cursor so the whole result is never held
+// in memory. Each FETCH is bounded by the query timeout, not the export.
+func (p *PostgresDB) ExportAds(ctx context.Context, filter model.AdFilter, fn func(*model.Advertisement) error) error {
+	p.log.Debugf("export ads", map[string]interface{}{"author_id": filter.AuthorID})
+
+	whereClause, orderClause, params := adFilterClauses(filter)
+
+	declareQuery := fmt.Sprintf(`
+        DECLARE ads_export NO SCROLL CURSOR FOR
+        SELECT 
+            a.id, 
+            a.author_id, 
+            u.username as author_username, 
+            u.display_name,
+            u.avatar_url,
+            u.city,
+            u.phone,
+            u.phone_visibility,
+            a.caption, 
+            a.description, 
+            a.image_url, 
+            a.price, 
+            a.status,
+            a.created_at,
+            a.updated_at
+        FROM advertisements a
+        JOIN users u ON a.author_id = u.id
+        WHERE %s
+        ORDER BY %s`, whereClause, orderClause)
+
+	fetchQuery := fmt.Sprintf("FETCH %d FROM ads_export", exportFetchSize)
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	declareCtx, cancel := p.withTimeout(ctx)
+	_, err = tx.Exec(declareCtx, declareQuery, params...)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to declare cursor: %w", err)
+	}
+
+	batch := make([]*model.Advertisement, 0, exportFetchSize)
+
+	for {
+		batch = batch[:0]
+
+		fetchCtx, cancel := p.withTimeout(ctx)
+		rows, err := tx.Query(fetchCtx, fetchQuery)
+		if err != nil {
+			cancel()
+			return fmt.Errorf("failed to fetch ads: %w", err)
+		}
+
+		for rows.Next() {
+			var ad model.Advertisement
+			err := rows.Scan(
+				&ad.ID,
+				&ad.AuthorID,
+				&ad.AuthorUsername,
+				&ad.Author.DisplayName,
+				&ad.Author.AvatarURL,
+				&ad.Author.City,
+				&ad.Author.Phone,
+				&ad.Author.PhoneVisibility,
+				&ad.Caption,
+				&ad.Description,
+				&ad.ImageURL,
+				&ad.Price,
+				&ad.Status,
+				&ad.CreatedAt,
+				&ad.UpdatedAt,
+			)
+			if err != nil {
+				rows.Close()
+				cancel()
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+
+			batch = append(batch, &ad)
+		}
+
+		rows.Close()
+		cancel()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("rows iteration error: %w", err)
+		}
+
+		// the batch is handed out only after the fetch is done, a slow
+		// consumer must not hit the query timeout
+		for _, ad := range batch {
+			if err := fn(ad); err != nil {
+				return err
+			}
+		}
+
+		if len(batch) < exportFetchSize {
+			return nil
+		}
+	}
+}
+
 func (p *PostgresDB) GetAd(ctx context.Context, id string) (*model.Advertisement, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	p.log.Debugf("get advertisement", map[string]interface{}{"ad_id": id})
 
 	const query = `
@@ -148,11 +353,18 @@ func (p *PostgresDB) GetAd(ctx context.Context, id string) (*model.Advertisement
             a.id, 
             a.author_id, 
             u.username as author_username, 
+            u.display_name,
+            u.avatar_url,
+            u.city,
+            u.phone,
+            u.phone_visibility,
             a.caption, 
             a.description, 
             a.image_url, 
             a.price, 
-            a.created_at
+            a.status,
+            a.created_at,
+            a.updated_at
         FROM advertisements a
         JOIN users u ON a.author_id = u.id
         WHERE a.id = $1
@@ -163,11 +375,18 @@ func (p *PostgresDB) GetAd(ctx context.Context, id string) (*model.Advertisement
 		&ad.ID,
 		&ad.AuthorID,
 		&ad.AuthorUsername,
+		&ad.Author.DisplayName,
+		&ad.Author.AvatarURL,
+		&ad.Author.City,
+		&ad.Author.Phone,
+		&ad.Author.PhoneVisibility,
 		&ad.Caption,
 		&ad.Description,
 		&ad.ImageURL,
 		&ad.Price,
+		&ad.Status,
 		&ad.CreatedAt,
+		&ad.UpdatedAt,
 	)
 
 	if err != nil {
@@ -183,17 +402,41 @@ func (p *PostgresDB) GetAd(ctx context.Context, id string) (*model.Advertisement
 }
 
 func (p *PostgresDB) DeleteAd(ctx context.Context, id, authorID string) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	p.log.Debugf("delete ad", map[string]interface{}{"ad_id": id, "author_id": authorID})
 
-	const query = `DELETE FROM advertisements WHERE id = $1 AND author_id = $2`
+	const query = `DELETE FROM advertisements WHERE id = $1 AND author_id = $2 RETURNING id, author_id, status`
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// offers lose their ad_id with the ad, so they are rejected first; the
+	// rejection is rolled back if the ad turns out not to be the user's
+	if err := rejectOpenOffers(ctx, tx, id, offerRejectAdDeleted); err != nil {
+		return err
+	}
 
-	result, err := p.db.Exec(ctx, query, id, authorID)
+	var deletedAd model.Advertisement
+	var status string
+	err = tx.QueryRow(ctx, query, id, authorID).Scan(&deletedAd.ID, &deletedAd.AuthorID, &status)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return database.ErrAdNotFoundOrNotOwnedByUser
+		}
 		return fmt.Errorf("failed to delete ad: %w", err)
 	}
 
-	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
-		return database.ErrAdNotFoundOrNotOwnedByUser
+	if err := enqueueAdStatusEvent(ctx, tx, status, "", &deletedAd); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
@@ -201,33 +444,70 @@ func (p *PostgresDB) DeleteAd(ctx context.Context, id, authorID string) error {
 
 func (p *PostgresDB) UpdateAd(ctx context.Context, ad *model.Advertisement) (*model.Advertisement, error) {
 	const query = `
-        UPDATE advertisements
+        UPDATE advertisements a
         SET 
             caption = $1,
             description = $2,
             image_url = $3,
             price = $4,
-						updated_at = $5
-        WHERE id = $6 AND author_id = $7
-        RETURNING id, author_id, caption, description, image_url, price, created_at, updated_at
+            status = $5,
+            updated_at = $6
+        FROM users u
+        WHERE a.id = $7 AND a.author_id = $8 AND u.id = a.author_id
+        RETURNING a.id, a.author_id, u.username, u.display_name, u.avatar_url, u.city, u.phone, u.phone_visibility,
+            a.caption, a.description, a.image_url, a.price, a.status, a.created_at, a.updated_at
     `
 
+	const statusQuery = `SELECT status FROM advertisements WHERE id = $1 AND author_id = $2 FOR UPDATE`
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	if err := tx.QueryRow(ctx, statusQuery, ad.ID, ad.AuthorID).Scan(&status); err != nil {
+		return nil, fmt.Errorf("failed to update ad: %w", err)
+	}
+
+	newStatus := ad.Status
+	if newStatus == "" {
+		newStatus = status
+	}
+
+	// only moderation brings a hidden ad back
+	if status == model.AdStatusHidden && newStatus != model.AdStatusHidden {
+		return nil, database.ErrAdHidden
+	}
+
 	var updatedAd model.Advertisement
-	err := p.db.QueryRow(ctx, query,
+	err = tx.QueryRow(ctx, query,
 		ad.Caption,
 		ad.Description,
 		ad.ImageURL,
 		ad.Price,
+		newStatus,
 		time.Now(),
 		ad.ID,
 		ad.AuthorID,
 	).Scan(
 		&updatedAd.ID,
 		&updatedAd.AuthorID,
+		&updatedAd.AuthorUsername,
+		&updatedAd.Author.DisplayName,
+		&updatedAd.Author.AvatarURL,
+		&updatedAd.Author.City,
+		&updatedAd.Author.Phone,
+		&updatedAd.Author.PhoneVisibility,
 		&updatedAd.Caption,
 		&updatedAd.Description,
 		&updatedAd.ImageURL,
 		&updatedAd.Price,
+		&updatedAd.Status,
 		&updatedAd.CreatedAt,
 		&updatedAd.UpdatedAt,
 	)
@@ -236,5 +516,157 @@ func (p *PostgresDB) UpdateAd(ctx context.Context, ad *model.Advertisement) (*mo
 		return nil, fmt.Errorf("failed to update ad: %w", err)
 	}
 
+	if err := enqueueAdStatusEvent(ctx, tx, status, updatedAd.Status, &updatedAd); err != nil {
+		return nil, err
+	}
+
+	if status != model.AdStatusSold && updatedAd.Status == model.AdStatusSold {
+		if err := rejectOpenOffers(ctx, tx, updatedAd.ID, offerRejectAdSold); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return &updatedAd, nil
 }
+
+// DeleteAdByID deletes an ad regardless of its author, used by operators.
+func (p *PostgresDB) DeleteAdByID(ctx context.Context, id string) (*model.Advertisement, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("delete ad by id", map[string]interface{}{"ad_id": id})
+
+	const query = `
+        DELETE FROM advertisements a
+        USING users u
+        WHERE a.id = $1 AND a.author_id = u.id
+        RETURNING a.id, a.author_id, u.username, a.caption, a.description, a.image_url, a.price, a.status, a.created_at
+    `
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := rejectOpenOffers(ctx, tx, id, offerRejectAdDeleted); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode {
+			return nil, database.ErrAdNotFound
+		}
+		return nil, err
+	}
+
+	var deletedAd model.Advertisement
+	err = tx.QueryRow(ctx, query, id).Scan(
+		&deletedAd.ID,
+		&deletedAd.AuthorID,
+		&deletedAd.AuthorUsername,
+		&deletedAd.Caption,
+		&deletedAd.Description,
+		&deletedAd.ImageURL,
+		&deletedAd.Price,
+		&deletedAd.Status,
+		&deletedAd.CreatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if (errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode) || errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrAdNotFound
+		}
+		return nil, fmt.Errorf("failed to delete ad: %w", err)
+	}
+
+	if err := enqueueAdStatusEvent(ctx, tx, deletedAd.Status, "", &deletedAd); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &deletedAd, nil
+}
+
+// ReassignAd moves an ad to the user with the given username.
+func (p *PostgresDB) ReassignAd(ctx context.Context, id, username string) (*model.Advertisement, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("reassign ad", map[string]interface{}{"ad_id": id, "username": username})
+
+	const userQuery = `SELECT id FROM users WHERE username = $1 AND deleted_at IS NULL`
+
+	const authorQuery = `SELECT author_id FROM advertisements WHERE id = $1 FOR UPDATE`
+
+	const query = `
+        UPDATE advertisements
+        SET author_id = $2, updated_at = NOW()
+        WHERE id = $1
+        RETURNING id, author_id, caption, description, image_url, price, status, created_at, updated_at
+    `
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var authorID string
+	if err := tx.QueryRow(ctx, userQuery, username).Scan(&authorID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	var previousAuthorID string
+	if err := tx.QueryRow(ctx, authorQuery, id).Scan(&previousAuthorID); err != nil {
+		var pgErr *pgconn.PgError
+		if (errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode) || errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrAdNotFound
+		}
+		return nil, fmt.Errorf("failed to get ad: %w", err)
+	}
+
+	// the offers were made to the previous author
+	if previousAuthorID != authorID {
+		if err := rejectOpenOffers(ctx, tx, id, offerRejectAdReassigned); err != nil {
+			return nil, err
+		}
+	}
+
+	ad := model.Advertisement{AuthorUsername: username}
+	err = tx.QueryRow(ctx, query, id, authorID).Scan(
+		&ad.ID,
+		&ad.AuthorID,
+		&ad.Caption,
+		&ad.Description,
+		&ad.ImageURL,
+		&ad.Price,
+		&ad.Status,
+		&ad.CreatedAt,
+		&ad.UpdatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if (errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode) || errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrAdNotFound
+		}
+		return nil, fmt.Errorf("failed to reassign ad: %w", err)
+	}
+
+	if err := enqueueAdStatusEvent(ctx, tx, ad.Status, ad.Status, &ad); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &ad, nil
+}
@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"vk-internship/internal/database/model"
+)
+
+// AcquireIdempotencyKey claims the key for a new request. It returns the
+// stored record and false if the key is already taken by a live request or
+// a completed one. Expired keys and in-progress keys whose lock ran out (the
+// request crashed) with the same fingerprint can be claimed again.
+func (p *PostgresDB) AcquireIdempotencyKey(ctx context.Context, userID, key, fingerprint string, lock, ttl time.Duration) (*model.IdempotencyRecord, bool, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	const acquireQuery = `
+		INSERT INTO idempotency_keys (user_id, key, fingerprint, locked_until, expires_at)
+		VALUES ($1, $2, $3, NOW() + make_interval(secs => $4), NOW() + make_interval(secs => $5))
+		ON CONFLICT (user_id, key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint,
+			status = 'in_progress',
+			response_status = NULL,
+			response_headers = NULL,
+			response_body = NULL,
+			created_at = NOW(),
+			locked_until = EXCLUDED.locked_until,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at < NOW()
+			OR (idempotency_keys.status = 'in_progress'
+				AND idempotency_keys.locked_until < NOW()
+				AND idempotency_keys.fingerprint = EXCLUDED.fingerprint)
+		RETURNING created_at
+	`
+
+	record := model.IdempotencyRecord{
+		UserID:      userID,
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      model.IdempotencyInProgress,
+	}
+
+	err := p.db.QueryRow(ctx, acquireQuery, userID, key, fingerprint, lock.Seconds(), ttl.Seconds()).Scan(&record.CreatedAt)
+	if err == nil {
+		return &record, true, nil
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, fmt.Errorf("failed to acquire idempotency key: %w", err)
+	}
+
+	existing, err := p.getIdempotencyRecord(ctx, userID, key)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return existing, false, nil
+}
+
+func (p *PostgresDB) getIdempotencyRecord(ctx context.Context, userID, key string) (*model.IdempotencyRecord, error) {
+	const query = `
+		SELECT user_id, key, fingerprint, status, response_status, response_headers, response_body, created_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2
+	`
+
+	var (
+		record  model.IdempotencyRecord
+		headers []byte
+	)
+
+	err := p.db.QueryRow(ctx, query, userID, key).Scan(
+		&record.UserID,
+		&record.Key,
+		&record.Fingerprint,
+		&record.Status,
+		&record.ResponseStatus,
+		&headers,
+		&record.ResponseBody,
+		&record.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	if len(headers) > 0 {
+		if err := json.Unmarshal(headers, &record.ResponseHeaders); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal stored headers: %w", err)
+		}
+	}
+
+	return &record, nil
+}
+
+func (p *PostgresDB) CompleteIdempotencyKey(ctx context.Context, userID, key string, status int, headers map[string][]string, body []byte) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	encodedHeaders, err := json.Marshal(headers)
+	if err != nil {
+		return fmt.Errorf("failed to marshal headers: %w", err)
+	}
+
+	const query = `
+		UPDATE idempotency_keys
+		SET status = 'completed', response_status = $3, response_headers = $4::jsonb, response_body = $5
+		WHERE user_id = $1 AND key = $2
+	`
+
+	if _, err := p.db.Exec(ctx, query, userID, key, status, encodedHeaders, body); err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey forgets an in-progress key so that the client can
+// retry a request that failed on our side.
+func (p *PostgresDB) ReleaseIdempotencyKey(ctx context.Context, userID, key string) error {
+	const query = `DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2 AND status = 'in_progress'`
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := p.db.Exec(ctx, query, userID, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PostgresDB) DeleteExpiredIdempotencyKeys(ctx context.Context) (int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	result, err := p.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
@@ -0,0 +1,306 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+)
+
+const importJobColumns = `id, user_id, format, mode, status, total_rows, created_rows, failed_rows, rows, error, created_at, finished_at`
+
+// skipPendingRows marks rows that were never inserted as skipped and drops
+// their ads, used when a job fails.
+const skipPendingRows = `(
+	SELECT COALESCE(jsonb_agg(
+		CASE WHEN r->>'status' = 'pending' THEN (r - 'ad') || '{"status": "skipped"}' ELSE r END
+		ORDER BY ord
+	), '[]')
+	FROM jsonb_array_elements(rows) WITH ORDINALITY AS t(r, ord)
+)`
+
+func scanImportJob(row pgx.Row) (*model.ImportJob, error) {
+	var (
+		job  model.ImportJob
+		rows []byte
+	)
+
+	err := row.Scan(
+		&job.ID,
+		&job.UserID,
+		&job.Format,
+		&job.Mode,
+		&job.Status,
+		&job.TotalRows,
+		&job.CreatedRows,
+		&job.FailedRows,
+		&rows,
+		&job.Error,
+		&job.CreatedAt,
+		&job.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(rows, &job.Rows); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal import rows: %w", err)
+	}
+
+	return &job, nil
+}
+
+// CreateImportJob stores a job. Jobs created as completed or failed are
+// finished right away, pending ones wait for ExecuteImportJob.
+func (p *PostgresDB) CreateImportJob(ctx context.Context, job *model.ImportJob) (*model.ImportJob, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("create import job", map[string]interface{}{"user_id": job.UserID, "rows": job.TotalRows, "status": job.Status})
+
+	rows, err := json.Marshal(job.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal import rows: %w", err)
+	}
+
+	query := `
+		INSERT INTO import_jobs (user_id, format, mode, status, total_rows, created_rows, failed_rows, rows, error, finished_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, CASE WHEN $4 IN ('completed', 'failed') THEN NOW() END)
+		RETURNING ` + importJobColumns
+
+	created, err := scanImportJob(p.db.QueryRow(ctx, query,
+		job.UserID,
+		job.Format,
+		job.Mode,
+		job.Status,
+		job.TotalRows,
+		job.CreatedRows,
+		job.FailedRows,
+		rows,
+		job.Error,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create import job: %w", err)
+	}
+
+	return created, nil
+}
+
+func (p *PostgresDB) GetImportJob(ctx context.Context, id, userID string) (*model.ImportJob, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + importJobColumns + ` FROM import_jobs WHERE id = $1 AND user_id = $2`
+
+	job, err := scanImportJob(p.db.QueryRow(ctx, query, id, userID))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if (errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode) || errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrImportJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get import job: %w", err)
+	}
+
+	return job, nil
+}
+
+// ClaimImportJobs leases pending jobs and jobs whose lease expired. Jobs that
+// were claimed maxAttempts times without finishing are failed instead.
+func (p *PostgresDB) ClaimImportJobs(ctx context.Context, limit int, lease time.Duration, maxAttempts int) ([]string, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	failQuery := `
+		UPDATE import_jobs
+		SET status = 'failed', error = 'import did not finish', rows = ` + skipPendingRows + `,
+			finished_at = NOW(), lease_until = NULL
+		WHERE status = 'running' AND lease_until < NOW() AND attempts >= $1
+	`
+
+	const claimQuery = `
+		UPDATE import_jobs
+		SET status = 'running', lease_until = NOW() + make_interval(secs => $2), attempts = attempts + 1
+		WHERE id IN (
+			SELECT id
+			FROM import_jobs
+			WHERE status = 'pending' OR (status = 'running' AND lease_until < NOW())
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id
+	`
+
+	tag, err := p.db.Exec(ctx, failQuery, maxAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fail stuck import jobs: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		p.log.Warnf("failed stuck import jobs", map[string]interface{}{"count": tag.RowsAffected()})
+	}
+
+	rows, err := p.db.Query(ctx, claimQuery, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim import jobs: %w", err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim import jobs: %w", err)
+	}
+
+	return ids, nil
+}
+
+// ExecuteImportJob inserts the pending rows of a job in one transaction,
+// together with their webhook events, and completes the job. It returns the
+// created ads.
+func (p *PostgresDB) ExecuteImportJob(ctx context.Context, id string) (*model.ImportJob, []*model.Advertisement, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("execute import job", map[string]interface{}{"job_id": id})
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	selectQuery := `SELECT ` + importJobColumns + ` FROM import_jobs WHERE id = $1 AND status IN ('pending', 'running') FOR UPDATE`
+
+	job, err := scanImportJob(tx.QueryRow(ctx, selectQuery, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, database.ErrImportJobNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get import job: %w", err)
+	}
+
+	const authorQuery = `
+		SELECT username, display_name, avatar_url, city, phone, phone_visibility
+		FROM users
+		WHERE id = $1
+	`
+
+	var (
+		username string
+		author   model.UserProfile
+	)
+	if err := tx.QueryRow(ctx, authorQuery, job.UserID).Scan(
+		&username,
+		&author.DisplayName,
+		&author.AvatarURL,
+		&author.City,
+		&author.Phone,
+		&author.PhoneVisibility,
+	); err != nil {
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	const insertQuery = `
+		INSERT INTO advertisements (author_id, caption, description, image_url, price)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	var (
+		ads     []*model.Advertisement
+		pending []int
+		batch   pgx.Batch
+	)
+
+	for i, row := range job.Rows {
+		if row.Status != model.ImportRowPending || row.Ad == nil {
+			continue
+		}
+
+		ad := row.Ad
+		ad.AuthorID = job.UserID
+		ad.AuthorUsername = username
+		ad.Author = author
+		ad.Status = model.AdStatusActive
+		batch.Queue(insertQuery, ad.AuthorID, ad.Caption, ad.Description, ad.ImageURL, ad.Price)
+
+		ads = append(ads, ad)
+		pending = append(pending, i)
+	}
+
+	results := tx.SendBatch(ctx, &batch)
+	for _, ad := range ads {
+		if err := results.QueryRow().Scan(&ad.ID, &ad.CreatedAt, &ad.UpdatedAt); err != nil {
+			results.Close()
+			return nil, nil, fmt.Errorf("failed to insert ad: %w", err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to insert ads: %w", err)
+	}
+
+	var events pgx.Batch
+	for _, ad := range ads {
+		if err := queueWebhookEvent(&events, model.EventAdCreated, ad); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := tx.SendBatch(ctx, &events).Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to enqueue webhook events: %w", err)
+	}
+
+	for n, i := range pending {
+		job.Rows[i].Status = model.ImportRowCreated
+		job.Rows[i].AdID = ads[n].ID
+		job.Rows[i].Ad = nil
+	}
+
+	rows, err := json.Marshal(job.Rows)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal import rows: %w", err)
+	}
+
+	updateQuery := `
+		UPDATE import_jobs
+		SET status = 'completed', created_rows = $2, rows = $3, finished_at = NOW(), lease_until = NULL
+		WHERE id = $1
+		RETURNING ` + importJobColumns
+
+	job, err = scanImportJob(tx.QueryRow(ctx, updateQuery, id, len(ads), rows))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to complete import job: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return job, ads, nil
+}
+
+// FailImportJob finishes an unfinished job without inserting anything.
+func (p *PostgresDB) FailImportJob(ctx context.Context, id, reason string) (*model.ImportJob, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE import_jobs
+		SET status = 'failed', error = $2, rows = ` + skipPendingRows + `, finished_at = NOW(), lease_until = NULL
+		WHERE id = $1 AND status IN ('pending', 'running')
+		RETURNING ` + importJobColumns
+
+	job, err := scanImportJob(p.db.QueryRow(ctx, query, id, reason))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrImportJobNotFound
+		}
+		return nil, fmt.Errorf("failed to fail import job: %w", err)
+	}
+
+	return job, nil
+}
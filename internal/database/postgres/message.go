@@ -0,0 +1,451 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+)
+
+func (p *PostgresDB) StartConversation(ctx context.Context, adID, buyerID, body string) (*model.Conversation, *model.Message, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("start conversation", map[string]interface{}{"ad_id": adID, "buyer_id": buyerID})
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var sellerID string
+	err = tx.QueryRow(ctx, `SELECT author_id FROM advertisements WHERE id = $1 AND status = 'active'`, adID).Scan(&sellerID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if (errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode) || errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, database.ErrAdNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get ad: %w", err)
+	}
+
+	if sellerID == buyerID {
+		return nil, nil, database.ErrCannotMessageOwnAd
+	}
+
+	if err := checkBlocked(ctx, tx, buyerID, sellerID); err != nil {
+		return nil, nil, err
+	}
+
+	const conversationQuery = `
+		INSERT INTO conversations (ad_id, buyer_id, seller_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (ad_id, buyer_id) DO UPDATE SET last_message_at = NOW()
+		RETURNING id, ad_id, buyer_id, seller_id, created_at, last_message_at, (xmax = 0) AS inserted
+	`
+
+	var (
+		conversation model.Conversation
+		inserted     bool
+	)
+
+	err = tx.QueryRow(ctx, conversationQuery, adID, buyerID, sellerID).Scan(
+		&conversation.ID,
+		&conversation.AdID,
+		&conversation.BuyerID,
+		&conversation.SellerID,
+		&conversation.CreatedAt,
+		&conversation.LastMessageAt,
+		&inserted,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create conversation: %w", err)
+	}
+
+	if inserted {
+		const contactClickQuery = `
+			INSERT INTO ad_daily_stats (ad_id, day, contact_clicks)
+			VALUES ($1, (NOW() AT TIME ZONE 'UTC')::date, 1)
+			ON CONFLICT (ad_id, day) DO UPDATE
+			SET contact_clicks = ad_daily_stats.contact_clicks + 1
+		`
+
+		if _, err := tx.Exec(ctx, contactClickQuery, adID); err != nil {
+			return nil, nil, fmt.Errorf("failed to count contact click: %w", err)
+		}
+	}
+
+	message, err := insertMessage(ctx, tx, conversation.ID, buyerID, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	conversation.LastMessage = message
+	return &conversation, message, nil
+}
+
+func (p *PostgresDB) SendMessage(ctx context.Context, conversationID, senderID, body string) (*model.Message, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("send message", map[string]interface{}{"conversation_id": conversationID, "sender_id": senderID})
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	const query = `
+		SELECT CASE WHEN buyer_id = $2 THEN seller_id ELSE buyer_id END
+		FROM conversations
+		WHERE id = $1 AND (buyer_id = $2 OR seller_id = $2)
+		FOR UPDATE
+	`
+
+	var recipientID string
+	if err := tx.QueryRow(ctx, query, conversationID, senderID).Scan(&recipientID); err != nil {
+		var pgErr *pgconn.PgError
+		if (errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode) || errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrConversationNotFound
+		}
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+
+	if err := checkBlocked(ctx, tx, senderID, recipientID); err != nil {
+		return nil, err
+	}
+
+	message, err := insertMessage(ctx, tx, conversationID, senderID, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE conversations SET last_message_at = $1 WHERE id = $2`, message.CreatedAt, conversationID); err != nil {
+		return nil, fmt.Errorf("failed to update conversation: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return message, nil
+}
+
+func (p *PostgresDB) GetConversations(ctx context.Context, userID string, page, pageSize int) ([]*model.Conversation, int, error) {
+	const query = `
+		SELECT
+			c.id,
+			c.ad_id,
+			a.caption,
+			c.buyer_id,
+			b.username,
+			c.seller_id,
+			s.username,
+			c.created_at,
+			c.last_message_at,
+			lm.id,
+			lm.sender_id,
+			lm.body,
+			lm.created_at,
+			lm.read_at,
+			(SELECT COUNT(*) FROM messages m
+			 WHERE m.conversation_id = c.id AND m.sender_id <> $1 AND m.read_at IS NULL) AS unread_count,
+			COUNT(*) OVER() AS total_count
+		FROM conversations c
+		JOIN users b ON b.id = c.buyer_id
+		JOIN users s ON s.id = c.seller_id
+		LEFT JOIN advertisements a ON a.id = c.ad_id
+		LEFT JOIN LATERAL (
+			SELECT id, sender_id, body, created_at, read_at
+			FROM messages
+			WHERE conversation_id = c.id
+			ORDER BY created_at DESC
+			LIMIT 1
+		) lm ON TRUE
+		WHERE c.buyer_id = $1 OR c.seller_id = $1
+		ORDER BY c.last_message_at DESC
+		OFFSET $2 LIMIT $3
+	`
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.db.Query(ctx, query, userID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	var conversations []*model.Conversation
+	totalCount := 0
+
+	for rows.Next() {
+		var (
+			c             model.Conversation
+			lastID        *string
+			lastSenderID  *string
+			lastBody      *string
+			lastCreatedAt *time.Time
+			lastReadAt    *time.Time
+		)
+
+		err := rows.Scan(
+			&c.ID,
+			&c.AdID,
+			&c.AdCaption,
+			&c.BuyerID,
+			&c.BuyerUsername,
+			&c.SellerID,
+			&c.SellerUsername,
+			&c.CreatedAt,
+			&c.LastMessageAt,
+			&lastID,
+			&lastSenderID,
+			&lastBody,
+			&lastCreatedAt,
+			&lastReadAt,
+			&c.UnreadCount,
+			&totalCount,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		if lastID != nil {
+			c.LastMessage = &model.Message{
+				ID:             *lastID,
+				ConversationID: c.ID,
+				SenderID:       *lastSenderID,
+				Body:           *lastBody,
+				CreatedAt:      *lastCreatedAt,
+				ReadAt:         lastReadAt,
+			}
+		}
+
+		conversations = append(conversations, &c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return conversations, totalCount, nil
+}
+
+func (p *PostgresDB) GetMessages(ctx context.Context, conversationID, userID string, page, pageSize int) ([]*model.Message, int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if err := p.checkParticipant(ctx, conversationID, userID); err != nil {
+		return nil, 0, err
+	}
+
+	const query = `
+		SELECT id, conversation_id, sender_id, body, created_at, read_at, COUNT(*) OVER() AS total_count
+		FROM messages
+		WHERE conversation_id = $1
+		ORDER BY created_at DESC
+		OFFSET $2 LIMIT $3
+	`
+
+	rows, err := p.db.Query(ctx, query, conversationID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*model.Message
+	totalCount := 0
+
+	for rows.Next() {
+		var m model.Message
+		if err := rows.Scan(&m.ID, &m.ConversationID, &m.SenderID, &m.Body, &m.CreatedAt, &m.ReadAt, &totalCount); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		messages = append(messages, &m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return messages, totalCount, nil
+}
+
+func (p *PostgresDB) MarkConversationRead(ctx context.Context, conversationID, userID string) (int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if err := p.checkParticipant(ctx, conversationID, userID); err != nil {
+		return 0, err
+	}
+
+	const query = `
+		UPDATE messages
+		SET read_at = NOW()
+		WHERE conversation_id = $1 AND sender_id <> $2 AND read_at IS NULL
+	`
+
+	result, err := p.db.Exec(ctx, query, conversationID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark messages as read: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+func (p *PostgresDB) GetUnreadCount(ctx context.Context, userID string) (int, error) {
+	const query = `
+		SELECT COUNT(*)
+		FROM messages m
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE (c.buyer_id = $1 OR c.seller_id = $1) AND m.sender_id <> $1 AND m.read_at IS NULL
+	`
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var count int
+	if err := p.db.QueryRow(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+
+	return count, nil
+}
+
+func (p *PostgresDB) BlockUser(ctx context.Context, blockerID, blockedUsername string) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("block user", map[string]interface{}{"blocker_id": blockerID, "blocked_username": blockedUsername})
+
+	const query = `
+		INSERT INTO user_blocks (blocker_id, blocked_id)
+		SELECT $1, id FROM users WHERE username = $2 AND deleted_at IS NULL
+		ON CONFLICT DO NOTHING
+		RETURNING blocked_id
+	`
+
+	var blockedID string
+	err := p.db.QueryRow(ctx, query, blockerID, blockedUsername).Scan(&blockedID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == checkViolationCode {
+			return database.ErrCannotBlockSelf
+		}
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return p.userMustExist(ctx, blockedUsername)
+		}
+
+		return fmt.Errorf("failed to block user: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PostgresDB) UnblockUser(ctx context.Context, blockerID, blockedUsername string) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("unblock user", map[string]interface{}{"blocker_id": blockerID, "blocked_username": blockedUsername})
+
+	const query = `
+		DELETE FROM user_blocks
+		WHERE blocker_id = $1 AND blocked_id = (SELECT id FROM users WHERE username = $2)
+	`
+
+	result, err := p.db.Exec(ctx, query, blockerID, blockedUsername)
+	if err != nil {
+		return fmt.Errorf("failed to unblock user: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return p.userMustExist(ctx, blockedUsername)
+	}
+
+	return nil
+}
+
+func (p *PostgresDB) checkParticipant(ctx context.Context, conversationID, userID string) error {
+	const query = `SELECT 1 FROM conversations WHERE id = $1 AND (buyer_id = $2 OR seller_id = $2)`
+
+	var one int
+	if err := p.db.QueryRow(ctx, query, conversationID, userID).Scan(&one); err != nil {
+		var pgErr *pgconn.PgError
+		if (errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode) || errors.Is(err, pgx.ErrNoRows) {
+			return database.ErrConversationNotFound
+		}
+		return fmt.Errorf("failed to get conversation: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PostgresDB) userMustExist(ctx context.Context, username string) error {
+	var one int
+	err := p.db.QueryRow(ctx, `SELECT 1 FROM users WHERE username = $1 AND deleted_at IS NULL`, username).Scan(&one)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return database.ErrUserNotFound
+		}
+		return fmt.Errorf("get user failed: %w", err)
+	}
+
+	return nil
+}
+
+func checkBlocked(ctx context.Context, tx pgx.Tx, senderID, recipientID string) error {
+	const query = `
+		SELECT EXISTS (
+			SELECT 1 FROM user_blocks
+			WHERE (blocker_id = $1 AND blocked_id = $2) OR (blocker_id = $2 AND blocked_id = $1)
+		)
+	`
+
+	var blocked bool
+	if err := tx.QueryRow(ctx, query, senderID, recipientID).Scan(&blocked); err != nil {
+		return fmt.Errorf("failed to check blocks: %w", err)
+	}
+
+	if blocked {
+		return database.ErrUserBlocked
+	}
+
+	return nil
+}
+
+func insertMessage(ctx context.Context, tx pgx.Tx, conversationID, senderID, body string) (*model.Message, error) {
+	const query = `
+		INSERT INTO messages (conversation_id, sender_id, body)
+		VALUES ($1, $2, $3)
+		RETURNING id, conversation_id, sender_id, body, created_at, read_at
+	`
+
+	var m model.Message
+	err := tx.QueryRow(ctx, query, conversationID, senderID, body).Scan(
+		&m.ID,
+		&m.ConversationID,
+		&m.SenderID,
+		&m.Body,
+		&m.CreatedAt,
+		&m.ReadAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert message: %w", err)
+	}
+
+	return &m, nil
+}
@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type poolCollector struct {
+	pool          *pgxpool.Pool
+	minConnsValue int32
+
+	acquiredConns    *prometheus.Desc
+	idleConns        *prometheus.Desc
+	totalConns       *prometheus.Desc
+	maxConns         *prometheus.Desc
+	acquireCount     *prometheus.Desc
+	emptyAcquire     *prometheus.Desc
+	acquireDuration  *prometheus.Desc
+	canceledAcquires *prometheus.Desc
+	minConns         *prometheus.Desc
+	newConns         *prometheus.Desc
+	lifetimeDestroys *prometheus.Desc
+	idleDestroys     *prometheus.Desc
+}
+
+func newPoolCollector(pool *pgxpool.Pool) *poolCollector {
+	minConns := pool.Config().MinConns
+
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("marketplace", "postgres_pool", name), help, nil, nil)
+	}
+
+	return &poolCollector{
+		pool:             pool,
+		acquiredConns:    desc("acquired_conns", "Number of connections currently acquired from the pool."),
+		idleConns:        desc("idle_conns", "Number of idle connections in the pool."),
+		totalConns:       desc("total_conns", "Total number of connections in the pool."),
+		maxConns:         desc("max_conns", "Maximum size of the pool."),
+		acquireCount:     desc("acquires_total", "Number of successful acquires from the pool."),
+		emptyAcquire:     desc("empty_acquires_total", "Number of acquires that had to wait for a connection."),
+		acquireDuration:  desc("acquire_wait_seconds_total", "Total time spent waiting for a connection."),
+		canceledAcquires: desc("canceled_acquires_total", "Number of acquires canceled by context."),
+		minConns:         desc("min_conns", "Minimum size of the pool."),
+		newConns:         desc("new_conns_total", "Number of connections opened by the pool."),
+		lifetimeDestroys: desc("max_lifetime_destroys_total", "Number of connections closed because they reached the max lifetime."),
+		idleDestroys:     desc("max_idle_destroys_total", "Number of connections closed because they reached the max idle time."),
+		minConnsValue:    minConns,
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.totalConns
+	ch <- c.maxConns
+	ch <- c.acquireCount
+	ch <- c.emptyAcquire
+	ch <- c.acquireDuration
+	ch <- c.canceledAcquires
+	ch <- c.minConns
+	ch <- c.newConns
+	ch <- c.lifetimeDestroys
+	ch <- c.idleDestroys
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquire, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquires, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.minConns, prometheus.GaugeValue, float64(c.minConnsValue))
+	ch <- prometheus.MustNewConstMetric(c.newConns, prometheus.CounterValue, float64(stat.NewConnsCount()))
+	ch <- prometheus.MustNewConstMetric(c.lifetimeDestroys, prometheus.CounterValue, float64(stat.MaxLifetimeDestroyCount()))
+	ch <- prometheus.MustNewConstMetric(c.idleDestroys, prometheus.CounterValue, float64(stat.MaxIdleDestroyCount()))
+}
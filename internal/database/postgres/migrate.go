@@ -0,0 +1,420 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"vk-internship/internal/logger"
+)
+
+var (
+	ErrSchemaDirty   = errors.New("database schema is dirty")
+	ErrSchemaVersion = errors.New("database schema version is incompatible")
+	ErrUnknownTarget = errors.New("unknown migration version")
+)
+
+// migrationLockID is the pg_advisory_lock key held while migrating, so
+// replicas starting with AUTO_MIGRATE apply migrations one at a time.
+const migrationLockID = 7_242_515_301
+
+var migrationFileRe = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	up      string
+	down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+// Migrator applies migrations in the golang-migrate file layout and keeps
+// its schema_migrations table, so it can be used on databases migrated by
+// the migrate CLI and the other way round.
+type Migrator struct {
+	db         *pgxpool.Pool
+	migrations []Migration
+	log        logger.Logger
+}
+
+// Migrator reads migrations from the root of source.
+func (p *PostgresDB) Migrator(source fs.FS) (*Migrator, error) {
+	migrations, err := readMigrations(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         p.db,
+		migrations: migrations,
+		log:        p.log.Component("migrate"),
+	}, nil
+}
+
+// readMigrations pairs the up and down files by version and sorts them.
+func readMigrations(source fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFileRe.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.Atoi(match[1])
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", entry.Name())
+		}
+
+		body, err := fs.ReadFile(source, path.Join(".", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+
+		if match[3] == "up" {
+			m.up = string(body)
+		} else {
+			m.down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" {
+			return nil, fmt.Errorf("migration %d has no up file", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Latest returns the version the embedded migrations lead to, 0 if none.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version returns the applied version, 0 if no migration was applied.
+func (m *Migrator) Version(ctx context.Context) (int, bool, error) {
+	conn, err := m.db.Acquire(ctx)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	return m.version(ctx, conn)
+}
+
+func (m *Migrator) Status(ctx context.Context) (int, bool, []MigrationStatus, error) {
+	version, dirty, err := m.Version(ctx)
+	if err != nil {
+		return 0, false, nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		statuses = append(statuses, MigrationStatus{Migration: migration, Applied: migration.Version <= version})
+	}
+
+	return version, dirty, statuses, nil
+}
+
+// Check returns an error unless the database is at exactly the latest
+// version, which is what the queries of this binary expect.
+func (m *Migrator) Check(ctx context.Context) error {
+	version, dirty, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		return fmt.Errorf("%w: migration %d failed, fix the schema manually and run `migrate force %d`", ErrSchemaDirty, version, version)
+	}
+
+	switch latest := m.Latest(); {
+	case version < latest:
+		return fmt.Errorf("%w: database is at version %d, %d is required, run `migrate up` or set AUTO_MIGRATE=true", ErrSchemaVersion, version, latest)
+	case version > latest:
+		return fmt.Errorf("%w: database is at version %d, newer than %d known to this release", ErrSchemaVersion, version, latest)
+	}
+
+	return nil
+}
+
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.Goto(ctx, m.Latest())
+}
+
+// Down rolls back the given number of applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		version, dirty, err := m.version(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		target, err := m.downTarget(version, dirty, steps)
+		if err != nil {
+			return err
+		}
+
+		return m.migrate(ctx, conn, version, target)
+	})
+}
+
+// Goto migrates up or down to version, 0 rolls back every migration.
+func (m *Migrator) Goto(ctx context.Context, version int) error {
+	if err := m.checkTarget(version); err != nil {
+		return err
+	}
+
+	return m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, dirty, err := m.version(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		if err := m.checkCurrent(current, dirty); err != nil {
+			return err
+		}
+
+		return m.migrate(ctx, conn, current, version)
+	})
+}
+
+// checkTarget accepts the known versions and 0, which stands for no
+// migrations at all.
+func (m *Migrator) checkTarget(version int) error {
+	if version != 0 && m.index(version) < 0 {
+		return fmt.Errorf("%w: %d", ErrUnknownTarget, version)
+	}
+
+	return nil
+}
+
+// checkCurrent refuses to migrate a dirty schema or one at a version this
+// release does not know.
+func (m *Migrator) checkCurrent(version int, dirty bool) error {
+	if dirty {
+		return fmt.Errorf("%w: version %d", ErrSchemaDirty, version)
+	}
+	if version > 0 && m.index(version) < 0 {
+		return fmt.Errorf("%w: database is at version %d", ErrUnknownTarget, version)
+	}
+
+	return nil
+}
+
+// downTarget returns the version left after rolling back steps migrations
+// from version.
+func (m *Migrator) downTarget(version int, dirty bool, steps int) (int, error) {
+	if err := m.checkCurrent(version, dirty); err != nil {
+		return 0, err
+	}
+
+	idx := m.index(version)
+	if idx-steps < 0 {
+		return 0, nil
+	}
+
+	return m.migrations[idx-steps].Version, nil
+}
+
+// Force sets the version and clears the dirty flag without running anything,
+// after a failed migration was fixed by hand.
+func (m *Migrator) Force(ctx context.Context, version int) error {
+	if err := m.checkTarget(version); err != nil {
+		return err
+	}
+
+	return m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		return m.setVersion(ctx, conn, version, false)
+	})
+}
+
+func (m *Migrator) migrate(ctx context.Context, conn *pgxpool.Conn, current, target int) error {
+	if current == target {
+		m.log.Infof("schema is up to date", map[string]interface{}{"version": current})
+		return nil
+	}
+
+	if target > current {
+		for _, migration := range m.migrations {
+			if migration.Version <= current || migration.Version > target {
+				continue
+			}
+
+			if err := m.apply(ctx, conn, migration, "up", migration.up, migration.Version); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if migration.Version > current || migration.Version <= target {
+			continue
+		}
+
+		if migration.down == "" {
+			return fmt.Errorf("migration %d has no down file", migration.Version)
+		}
+
+		prev := 0
+		if i > 0 {
+			prev = m.migrations[i-1].Version
+		}
+
+		if err := m.apply(ctx, conn, migration, "down", migration.down, prev); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// apply runs one migration file the way golang-migrate does: the resulting
+// version is stored as dirty first and only cleared once the file succeeded.
+func (m *Migrator) apply(ctx context.Context, conn *pgxpool.Conn, migration Migration, direction, body string, to int) error {
+	m.log.Infof("applying migration", map[string]interface{}{"version": migration.Version, "name": migration.Name, "direction": direction})
+
+	if err := m.setVersion(ctx, conn, to, true); err != nil {
+		return err
+	}
+
+	if _, err := conn.Exec(ctx, body); err != nil {
+		return fmt.Errorf("migration %d %s failed: %w", migration.Version, direction, err)
+	}
+
+	return m.setVersion(ctx, conn, to, false)
+}
+
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	// pool connections carry STATEMENT_TIMEOUT, which would cancel the wait
+	// for the lock held by another replica and any slow migration
+	if _, err := conn.Exec(ctx, `SET statement_timeout = 0`); err != nil {
+		return fmt.Errorf("failed to disable statement timeout: %w", err)
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), `RESET statement_timeout`); err != nil {
+			m.log.Warnf("failed to restore statement timeout", map[string]interface{}{"error": err.Error()})
+			// keep the connection without a timeout out of the pool
+			conn.Conn().Close(context.Background())
+		}
+	}()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("failed to take migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID); err != nil {
+			m.log.Warnf("failed to release migration lock", map[string]interface{}{"error": err.Error()})
+		}
+	}()
+
+	if _, err := conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) version(ctx context.Context, conn *pgxpool.Conn) (int, bool, error) {
+	var exists bool
+	if err := conn.QueryRow(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return 0, false, fmt.Errorf("failed to check schema_migrations: %w", err)
+	}
+	if !exists {
+		return 0, false, nil
+	}
+
+	var (
+		version int64
+		dirty   bool
+	)
+
+	err := conn.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	// golang-migrate stores -1 while rolling back the first migration
+	if version < 0 {
+		version = 0
+	}
+
+	return int(version), dirty, nil
+}
+
+func (m *Migrator) setVersion(ctx context.Context, conn *pgxpool.Conn, version int, dirty bool) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return fmt.Errorf("failed to reset schema version: %w", err)
+	}
+
+	if version > 0 || dirty {
+		stored := int64(version)
+		if version == 0 {
+			stored = -1
+		}
+
+		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, $2)`, stored, dirty); err != nil {
+			return fmt.Errorf("failed to set schema version: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (m *Migrator) index(version int) int {
+	for i, migration := range m.migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestReadMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []Migration
+		wantErr string
+	}{
+		{
+			name: "up and down files are paired and sorted",
+			files: fstest.MapFS{
+				"000002_add_users.up.sql":     {Data: []byte("CREATE TABLE users ();")},
+				"000002_add_users.down.sql":   {Data: []byte("DROP TABLE users;")},
+				"000001_init.up.sql":          {Data: []byte("CREATE TABLE ads ();")},
+				"000001_init.down.sql":        {Data: []byte("DROP TABLE ads;")},
+				"000010_backfill_only.up.sql": {Data: []byte("UPDATE ads SET price = 0;")},
+				"README.md":                   {Data: []byte("not a migration")},
+				"000003_dir.up.sql/file":      {Data: []byte("directories are skipped")},
+			},
+			want: []Migration{
+				{Version: 1, Name: "init", up: "CREATE TABLE ads ();", down: "DROP TABLE ads;"},
+				{Version: 2, Name: "add_users", up: "CREATE TABLE users ();", down: "DROP TABLE users;"},
+				{Version: 10, Name: "backfill_only", up: "UPDATE ads SET price = 0;"},
+			},
+		},
+		{
+			name:  "no migrations",
+			files: fstest.MapFS{},
+		},
+		{
+			name: "down without up",
+			files: fstest.MapFS{
+				"000001_init.up.sql":  {Data: []byte("CREATE TABLE ads ();")},
+				"000002_add.down.sql": {Data: []byte("DROP TABLE users;")},
+			},
+			wantErr: "migration 2 has no up file",
+		},
+		{
+			name: "version 0",
+			files: fstest.MapFS{
+				"000000_init.up.sql": {Data: []byte("CREATE TABLE ads ();")},
+			},
+			wantErr: "invalid migration version in 000000_init.up.sql",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readMigrations(tt.files)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("readMigrations() = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readMigrations() = %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("readMigrations() returned %d migrations, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("migration %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMigratorDownTarget(t *testing.T) {
+	m := &Migrator{migrations: []Migration{{Version: 1}, {Version: 2}, {Version: 5}}}
+
+	tests := []struct {
+		name    string
+		version int
+		dirty   bool
+		steps   int
+		want    int
+		wantErr error
+	}{
+		{name: "one step", version: 5, steps: 1, want: 2},
+		{name: "two steps skip the gap", version: 5, steps: 2, want: 1},
+		{name: "more steps than applied", version: 5, steps: 10, want: 0},
+		{name: "from the first migration", version: 1, steps: 1, want: 0},
+		{name: "nothing applied", version: 0, steps: 1, want: 0},
+		{name: "dirty schema", version: 5, dirty: true, steps: 1, wantErr: ErrSchemaDirty},
+		{name: "unknown applied version", version: 3, steps: 1, wantErr: ErrUnknownTarget},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.downTarget(tt.version, tt.dirty, tt.steps)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("downTarget() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("downTarget() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigratorGotoChecks(t *testing.T) {
+	m := &Migrator{migrations: []Migration{{Version: 1}, {Version: 2}, {Version: 5}}}
+
+	tests := []struct {
+		name    string
+		current int
+		dirty   bool
+		target  int
+		wantErr error
+	}{
+		{name: "up to the latest", current: 0, target: 5},
+		{name: "down to a known version", current: 5, target: 1},
+		{name: "roll back everything", current: 5, target: 0},
+		{name: "unknown target", current: 1, target: 3, wantErr: ErrUnknownTarget},
+		{name: "unknown applied version", current: 4, target: 5, wantErr: ErrUnknownTarget},
+		{name: "dirty schema", current: 2, dirty: true, target: 5, wantErr: ErrSchemaDirty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.checkTarget(tt.target)
+			if err == nil {
+				err = m.checkCurrent(tt.current, tt.dirty)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,589 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+)
+
+const offerRejectAdHidden = "ad_hidden"
+
+const moderationCaseColumns = `c.id, c.ad_id, a.caption, a.status, au.username, c.status, c.reports_count,
+	COALESCE((
+		SELECT jsonb_object_agg(reason, n)
+		FROM (SELECT reason, COUNT(*) AS n FROM ad_reports WHERE case_id = c.id GROUP BY reason) r
+	), '{}'::jsonb),
+	c.auto_hidden, c.moderator_id, m.username, c.claimed_at, c.resolution, c.note, c.resolved_at, c.created_at, c.updated_at`
+
+const moderationCaseTables = `
+	moderation_cases c
+	LEFT JOIN advertisements a ON a.id = c.ad_id
+	LEFT JOIN users au ON au.id = a.author_id
+	LEFT JOIN users m ON m.id = c.moderator_id
+`
+
+const moderationActionColumns = `ma.id, ma.case_id, ma.ad_id, ma.review_id, ma.moderator_id, m.username, ma.action, ma.note, ma.created_at`
+
+func scanModerationCase(row pgx.Row, extra ...interface{}) (*model.ModerationCase, error) {
+	var c model.ModerationCase
+
+	dest := []interface{}{
+		&c.ID,
+		&c.AdID,
+		&c.AdCaption,
+		&c.AdStatus,
+		&c.AuthorUsername,
+		&c.Status,
+		&c.ReportsCount,
+		&c.Reasons,
+		&c.AutoHidden,
+		&c.ModeratorID,
+		&c.ModeratorUsername,
+		&c.ClaimedAt,
+		&c.Resolution,
+		&c.Note,
+		&c.ResolvedAt,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func scanModerationAction(row pgx.Row, extra ...interface{}) (*model.ModerationAction, error) {
+	var a model.ModerationAction
+
+	dest := []interface{}{
+		&a.ID,
+		&a.CaseID,
+		&a.AdID,
+		&a.ReviewID,
+		&a.ModeratorID,
+		&a.ModeratorUsername,
+		&a.Action,
+		&a.Note,
+		&a.CreatedAt,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+func getModerationCase(ctx context.Context, tx pgx.Tx, id string) (*model.ModerationCase, error) {
+	c, err := scanModerationCase(tx.QueryRow(ctx, `SELECT `+moderationCaseColumns+` FROM `+moderationCaseTables+` WHERE c.id = $1`, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation case: %w", err)
+	}
+
+	return c, nil
+}
+
+// insertModerationAction adds an entry to the audit trail in the transaction
+// of the action. An empty moderatorID is stored as NULL.
+func insertModerationAction(ctx context.Context, tx pgx.Tx, action *model.ModerationAction, moderatorID string) error {
+	const query = `
+		INSERT INTO moderation_actions (case_id, ad_id, review_id, moderator_id, action, note)
+		VALUES ($1, $2, $3, NULLIF($4, '')::uuid, $5, $6)
+	`
+
+	_, err := tx.Exec(ctx, query, action.CaseID, action.AdID, action.ReviewID, moderatorID, action.Action, action.Note)
+	if err != nil {
+		return fmt.Errorf("failed to record moderation action: %w", err)
+	}
+
+	return nil
+}
+
+// setAdStatus changes the status of a locked ad, enqueuing the webhook event
+// of the change.
+func setAdStatus(ctx context.Context, tx pgx.Tx, id, from, to string) (*model.Advertisement, error) {
+	const query = `
+		UPDATE advertisements a
+		SET status = $2, updated_at = NOW()
+		FROM users u
+		WHERE a.id = $1 AND u.id = a.author_id
+		RETURNING a.id, a.author_id, u.username, u.display_name, u.avatar_url, u.city, u.phone, u.phone_visibility,
+			a.caption, a.description, a.image_url, a.price, a.status, a.created_at, a.updated_at
+	`
+
+	var ad model.Advertisement
+	err := tx.QueryRow(ctx, query, id, to).Scan(
+		&ad.ID,
+		&ad.AuthorID,
+		&ad.AuthorUsername,
+		&ad.Author.DisplayName,
+		&ad.Author.AvatarURL,
+		&ad.Author.City,
+		&ad.Author.Phone,
+		&ad.Author.PhoneVisibility,
+		&ad.Caption,
+		&ad.Description,
+		&ad.ImageURL,
+		&ad.Price,
+		&ad.Status,
+		&ad.CreatedAt,
+		&ad.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update ad status: %w", err)
+	}
+
+	if err := enqueueAdStatusEvent(ctx, tx, from, to, &ad); err != nil {
+		return nil, err
+	}
+
+	return &ad, nil
+}
+
+func (p *PostgresDB) ReportAd(ctx context.Context, report *model.AdReport, autoHideReports int) (*model.AdReport, *model.AdChange, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("report ad", map[string]interface{}{"ad_id": *report.AdID, "reporter_id": report.ReporterID, "reason": report.Reason})
+
+	const adQuery = `SELECT author_id FROM advertisements WHERE id = $1 AND status = 'active' FOR UPDATE`
+
+	const caseQuery = `
+		INSERT INTO moderation_cases (ad_id)
+		VALUES ($1)
+		ON CONFLICT (ad_id) WHERE status <> 'resolved' DO UPDATE SET updated_at = NOW()
+		RETURNING id
+	`
+
+	const reportQuery = `
+		INSERT INTO ad_reports (case_id, ad_id, reporter_id, reason, comment)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	const countQuery = `
+		UPDATE moderation_cases
+		SET reports_count = reports_count + 1, updated_at = NOW()
+		WHERE id = $1
+		RETURNING reports_count
+	`
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var authorID string
+	if err := tx.QueryRow(ctx, adQuery, report.AdID).Scan(&authorID); err != nil {
+		var pgErr *pgconn.PgError
+		if (errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode) || errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, database.ErrAdNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get ad: %w", err)
+	}
+
+	if authorID == report.ReporterID {
+		return nil, nil, database.ErrCannotReportOwnAd
+	}
+
+	created := *report
+	if err := tx.QueryRow(ctx, caseQuery, report.AdID).Scan(&created.CaseID); err != nil {
+		return nil, nil, fmt.Errorf("failed to open moderation case: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, reportQuery, created.CaseID, report.AdID, report.ReporterID, report.Reason, report.Comment).Scan(&created.ID, &created.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return nil, nil, database.ErrAdAlreadyReported
+		}
+		return nil, nil, fmt.Errorf("failed to create report: %w", err)
+	}
+
+	var reports int
+	if err := tx.QueryRow(ctx, countQuery, created.CaseID).Scan(&reports); err != nil {
+		return nil, nil, fmt.Errorf("failed to count reports: %w", err)
+	}
+
+	var change *model.AdChange
+	if autoHideReports > 0 && reports >= autoHideReports {
+		ad, err := setAdStatus(ctx, tx, *report.AdID, model.AdStatusActive, model.AdStatusHidden)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if _, err := tx.Exec(ctx, `UPDATE moderation_cases SET auto_hidden = TRUE WHERE id = $1`, created.CaseID); err != nil {
+			return nil, nil, fmt.Errorf("failed to update moderation case: %w", err)
+		}
+
+		err = insertModerationAction(ctx, tx, &model.ModerationAction{
+			CaseID: &created.CaseID,
+			AdID:   report.AdID,
+			Action: model.ModerationActionAutoHide,
+			Note:   fmt.Sprintf("%d reports", reports),
+		}, "")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		change = &model.AdChange{Ad: ad, From: model.AdStatusActive, To: model.AdStatusHidden}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &created, change, nil
+}
+
+// GetModerationCases returns the cases in the status, or the unresolved ones
+// for an empty status, the most reported first.
+func (p *PostgresDB) GetModerationCases(ctx context.Context, status string, page, pageSize int) ([]*model.ModerationCase, int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + moderationCaseColumns + `, COUNT(*) OVER() AS total_count
+		FROM ` + moderationCaseTables + `
+		WHERE ($1 = '' AND c.status <> 'resolved') OR c.status = $1
+		ORDER BY c.reports_count DESC, c.created_at, c.id
+		OFFSET $2 LIMIT $3
+	`
+
+	rows, err := p.db.Query(ctx, query, status, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get moderation cases: %w", err)
+	}
+	defer rows.Close()
+
+	var cases []*model.ModerationCase
+	totalCount := 0
+
+	for rows.Next() {
+		c, err := scanModerationCase(rows, &totalCount)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan moderation case: %w", err)
+		}
+		cases = append(cases, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return cases, totalCount, nil
+}
+
+func (p *PostgresDB) GetModerationCase(ctx context.Context, id string) (*model.ModerationCase, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	const reportsQuery = `
+		SELECT r.id, r.case_id, r.ad_id, r.reporter_id, u.username, r.reason, r.comment, r.created_at
+		FROM ad_reports r
+		JOIN users u ON u.id = r.reporter_id
+		WHERE r.case_id = $1
+		ORDER BY r.created_at
+	`
+
+	const actionsQuery = `
+		SELECT ` + moderationActionColumns + `
+		FROM moderation_actions ma
+		LEFT JOIN users m ON m.id = ma.moderator_id
+		WHERE ma.case_id = $1
+		ORDER BY ma.created_at
+	`
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	c, err := getModerationCase(ctx, tx, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if (errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode) || errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrModerationCaseNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, reportsQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reports: %w", err)
+	}
+
+	c.Reports, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.AdReport, error) {
+		var r model.AdReport
+		err := row.Scan(&r.ID, &r.CaseID, &r.AdID, &r.ReporterID, &r.ReporterUsername, &r.Reason, &r.Comment, &r.CreatedAt)
+		return &r, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan report: %w", err)
+	}
+
+	rows, err = tx.Query(ctx, actionsQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation actions: %w", err)
+	}
+
+	c.Actions, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.ModerationAction, error) {
+		return scanModerationAction(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan moderation action: %w", err)
+	}
+
+	return c, nil
+}
+
+// ClaimModerationCase assigns an open case to the moderator. Claiming a case
+// the moderator already holds is a no-op.
+func (p *PostgresDB) ClaimModerationCase(ctx context.Context, id, moderatorID string) (*model.ModerationCase, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("claim moderation case", map[string]interface{}{"case_id": id, "moderator_id": moderatorID})
+
+	const claimQuery = `
+		UPDATE moderation_cases
+		SET status = 'claimed', moderator_id = $2, claimed_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	status, holderID, adID, err := lockModerationCase(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case status == model.ModerationCaseResolved:
+		return nil, database.ErrModerationCaseResolved
+	case status == model.ModerationCaseClaimed && holderID != nil && *holderID != moderatorID:
+		return nil, database.ErrModerationCaseClaimed
+	case status == model.ModerationCaseOpen:
+		if _, err := tx.Exec(ctx, claimQuery, id, moderatorID); err != nil {
+			return nil, fmt.Errorf("failed to claim moderation case: %w", err)
+		}
+
+		err := insertModerationAction(ctx, tx, &model.ModerationAction{CaseID: &id, AdID: adID, Action: model.ModerationActionClaim}, moderatorID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c, err := getModerationCase(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return c, nil
+}
+
+func (p *PostgresDB) ResolveModerationCase(ctx context.Context, id, moderatorID, resolution, note string) (*model.ModerationCase, *model.AdChange, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("resolve moderation case", map[string]interface{}{"case_id": id, "moderator_id": moderatorID, "resolution": resolution})
+
+	const resolveQuery = `
+		UPDATE moderation_cases
+		SET status = 'resolved', resolution = $2, note = NULLIF($3, ''), resolved_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`
+
+	const adQuery = `SELECT status FROM advertisements WHERE id = $1 FOR UPDATE`
+
+	const deleteQuery = `
+		DELETE FROM advertisements a
+		USING users u
+		WHERE a.id = $1 AND a.author_id = u.id
+		RETURNING a.id, a.author_id, u.username, a.caption, a.description, a.image_url, a.price, a.status, a.created_at, a.updated_at
+	`
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	status, holderID, adID, err := lockModerationCase(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if status == model.ModerationCaseResolved {
+		return nil, nil, database.ErrModerationCaseResolved
+	}
+	if status != model.ModerationCaseClaimed || holderID == nil || *holderID != moderatorID {
+		return nil, nil, database.ErrModerationCaseNotClaimed
+	}
+
+	var autoHidden bool
+	if err := tx.QueryRow(ctx, `SELECT auto_hidden FROM moderation_cases WHERE id = $1`, id).Scan(&autoHidden); err != nil {
+		return nil, nil, fmt.Errorf("failed to get moderation case: %w", err)
+	}
+
+	// the ad may be gone already, then only the decision is recorded
+	var change *model.AdChange
+	actionAdID := adID
+	if adID != nil {
+		var from string
+		if err := tx.QueryRow(ctx, adQuery, *adID).Scan(&from); err != nil {
+			return nil, nil, fmt.Errorf("failed to get ad: %w", err)
+		}
+
+		switch {
+		case resolution == model.ModerationResolutionHide:
+			// an auto-hidden ad kept its offers in case the reports are
+			// dismissed, the decision to hide it closes them
+			if err := rejectOpenOffers(ctx, tx, *adID, offerRejectAdHidden); err != nil {
+				return nil, nil, err
+			}
+
+			if from != model.AdStatusHidden {
+				ad, err := setAdStatus(ctx, tx, *adID, from, model.AdStatusHidden)
+				if err != nil {
+					return nil, nil, err
+				}
+				change = &model.AdChange{Ad: ad, From: from, To: model.AdStatusHidden}
+			}
+
+		case resolution == model.ModerationResolutionDelete:
+			if err := rejectOpenOffers(ctx, tx, *adID, offerRejectAdDeleted); err != nil {
+				return nil, nil, err
+			}
+
+			var ad model.Advertisement
+			err := tx.QueryRow(ctx, deleteQuery, *adID).Scan(
+				&ad.ID,
+				&ad.AuthorID,
+				&ad.AuthorUsername,
+				&ad.Caption,
+				&ad.Description,
+				&ad.ImageURL,
+				&ad.Price,
+				&ad.Status,
+				&ad.CreatedAt,
+				&ad.UpdatedAt,
+			)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to delete ad: %w", err)
+			}
+
+			if err := enqueueAdStatusEvent(ctx, tx, from, "", &ad); err != nil {
+				return nil, nil, err
+			}
+			change = &model.AdChange{Ad: &ad, From: from}
+			actionAdID = nil
+
+		case resolution == model.ModerationResolutionDismiss && autoHidden && from == model.AdStatusHidden:
+			// the reports were unfounded, the ad goes back where it was
+			ad, err := setAdStatus(ctx, tx, *adID, from, model.AdStatusActive)
+			if err != nil {
+				return nil, nil, err
+			}
+			change = &model.AdChange{Ad: ad, From: from, To: model.AdStatusActive}
+		}
+	}
+
+	if _, err := tx.Exec(ctx, resolveQuery, id, resolution, note); err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve moderation case: %w", err)
+	}
+
+	err = insertModerationAction(ctx, tx, &model.ModerationAction{CaseID: &id, AdID: actionAdID, Action: resolution, Note: note}, moderatorID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c, err := getModerationCase(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return c, change, nil
+}
+
+// lockModerationCase locks a case and returns its status, the moderator
+// holding it and its ad.
+func lockModerationCase(ctx context.Context, tx pgx.Tx, id string) (string, *string, *string, error) {
+	const query = `SELECT status, moderator_id, ad_id FROM moderation_cases WHERE id = $1 FOR UPDATE`
+
+	var (
+		status   string
+		holderID *string
+		adID     *string
+	)
+	if err := tx.QueryRow(ctx, query, id).Scan(&status, &holderID, &adID); err != nil {
+		var pgErr *pgconn.PgError
+		if (errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode) || errors.Is(err, pgx.ErrNoRows) {
+			return "", nil, nil, database.ErrModerationCaseNotFound
+		}
+		return "", nil, nil, fmt.Errorf("failed to get moderation case: %w", err)
+	}
+
+	return status, holderID, adID, nil
+}
+
+// GetModerationActions returns the audit trail, newest first, optionally of
+// one moderator only.
+func (p *PostgresDB) GetModerationActions(ctx context.Context, moderatorUsername string, page, pageSize int) ([]*model.ModerationAction, int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + moderationActionColumns + `, COUNT(*) OVER() AS total_count
+		FROM moderation_actions ma
+		LEFT JOIN users m ON m.id = ma.moderator_id
+		WHERE $1 = '' OR m.username = $1
+		ORDER BY ma.created_at DESC, ma.id
+		OFFSET $2 LIMIT $3
+	`
+
+	rows, err := p.db.Query(ctx, query, moderatorUsername, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get moderation actions: %w", err)
+	}
+	defer rows.Close()
+
+	var actions []*model.ModerationAction
+	totalCount := 0
+
+	for rows.Next() {
+		action, err := scanModerationAction(rows, &totalCount)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan moderation action: %w", err)
+		}
+		actions = append(actions, action)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return actions, totalCount, nil
+}
@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+)
+
+func (p *PostgresDB) CreateNotifications(ctx context.Context, notifications []*model.Notification) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	p.log.Debugf("create notifications", map[string]interface{}{"count": len(notifications)})
+
+	rows := make([][]interface{}, len(notifications))
+	for i, n := range notifications {
+		rows[i] = []interface{}{n.UserID, n.Type, n.Payload}
+	}
+
+	_, err := p.db.CopyFrom(ctx,
+		pgx.Identifier{"notifications"},
+		[]string{"user_id", "type", "payload"},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create notifications: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PostgresDB) GetNotifications(ctx context.Context, userID string, unreadOnly bool, page, pageSize int) ([]*model.Notification, int, error) {
+	const query = `
+		SELECT id, user_id, type, payload, created_at, read_at, COUNT(*) OVER() AS total_count
+		FROM notifications
+		WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		ORDER BY created_at DESC
+		OFFSET $3 LIMIT $4
+	`
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.db.Query(ctx, query, userID, unreadOnly, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*model.Notification
+	totalCount := 0
+
+	for rows.Next() {
+		var n model.Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Payload, &n.CreatedAt, &n.ReadAt, &totalCount); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		notifications = append(notifications, &n)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return notifications, totalCount, nil
+}
+
+func (p *PostgresDB) GetUnreadNotificationsCount(ctx context.Context, userID string) (int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var count int
+	err := p.db.QueryRow(ctx, `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+
+	return count, nil
+}
+
+func (p *PostgresDB) MarkNotificationRead(ctx context.Context, id, userID string) error {
+	const query = `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND user_id = $2
+	`
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	result, err := p.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode {
+			return database.ErrNotificationNotFound
+		}
+		return fmt.Errorf("failed to mark notification as read: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return database.ErrNotificationNotFound
+	}
+
+	return nil
+}
+
+func (p *PostgresDB) MarkAllNotificationsRead(ctx context.Context, userID string) (int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	result, err := p.db.Exec(ctx, `UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications as read: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// insertNotification creates a notification in the transaction of the change
+// it is about.
+func insertNotification(ctx context.Context, tx pgx.Tx, userID, notificationType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification payload: %w", err)
+	}
+
+	const query = `INSERT INTO notifications (user_id, type, payload) VALUES ($1, $2, $3)`
+
+	if _, err := tx.Exec(ctx, query, userID, notificationType, data); err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,432 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+)
+
+// expireOffersBatch is how many offers ExpireOffers closes at once, the rest
+// are left for the next run.
+const expireOffersBatch = 500
+
+// Reasons sent to buyers whose open offers are rejected by a change of the ad.
+const (
+	offerRejectAdSold       = "ad_sold"
+	offerRejectAdDeleted    = "ad_deleted"
+	offerRejectAdReassigned = "ad_reassigned"
+)
+
+// offerStatus reports open offers past their expiry as expired, whether or
+// not ExpireOffers has closed them yet.
+const offerStatus = `CASE WHEN o.status IN ('pending', 'countered') AND o.expires_at <= NOW() THEN 'expired' ELSE o.status END`
+
+const offerColumns = `o.id, o.ad_id, a.caption, o.buyer_id, b.username, o.seller_id, s.username, o.amount, o.counter_amount, ` +
+	offerStatus + `, o.expires_at, o.created_at, o.updated_at`
+
+const offerTables = `
+	offers o
+	JOIN users b ON b.id = o.buyer_id
+	JOIN users s ON s.id = o.seller_id
+	LEFT JOIN advertisements a ON a.id = o.ad_id
+`
+
+func scanOffer(row pgx.Row, extra ...interface{}) (*model.Offer, error) {
+	var offer model.Offer
+
+	dest := []interface{}{
+		&offer.ID,
+		&offer.AdID,
+		&offer.AdCaption,
+		&offer.BuyerID,
+		&offer.BuyerUsername,
+		&offer.SellerID,
+		&offer.SellerUsername,
+		&offer.Amount,
+		&offer.CounterAmount,
+		&offer.Status,
+		&offer.ExpiresAt,
+		&offer.CreatedAt,
+		&offer.UpdatedAt,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	return &offer, nil
+}
+
+func getOffer(ctx context.Context, tx pgx.Tx, id string) (*model.Offer, error) {
+	offer, err := scanOffer(tx.QueryRow(ctx, `SELECT `+offerColumns+` FROM `+offerTables+` WHERE o.id = $1`, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get offer: %w", err)
+	}
+
+	return offer, nil
+}
+
+// offerPayload is the notification about an offer, Username is the other
+// party. Amounts are in rubles.
+type offerPayload struct {
+	OfferID       string   `json:"offer_id"`
+	AdID          *string  `json:"ad_id"`
+	AdCaption     *string  `json:"ad_caption"`
+	Username      string   `json:"username"`
+	Amount        float64  `json:"amount"`
+	CounterAmount *float64 `json:"counter_amount,omitempty"`
+	Reason        string   `json:"reason,omitempty"`
+}
+
+func newOfferPayload(offer *model.Offer, username, reason string) offerPayload {
+	payload := offerPayload{
+		OfferID:   offer.ID,
+		AdID:      offer.AdID,
+		AdCaption: offer.AdCaption,
+		Username:  username,
+		Amount:    float64(offer.Amount) / 100,
+		Reason:    reason,
+	}
+
+	if offer.CounterAmount != nil {
+		counter := float64(*offer.CounterAmount) / 100
+		payload.CounterAmount = &counter
+	}
+
+	return payload
+}
+
+func (p *PostgresDB) CreateOffer(ctx context.Context, offer *model.Offer) (*model.Offer, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("create offer", map[string]interface{}{"ad_id": offer.AdID, "buyer_id": offer.BuyerID})
+
+	// the ad is locked against being sold or deleted until the offer is in
+	const adQuery = `SELECT author_id FROM advertisements WHERE id = $1 AND status = 'active' FOR SHARE`
+
+	const expireQuery = `
+		UPDATE offers
+		SET status = 'expired', updated_at = NOW()
+		WHERE ad_id = $1 AND buyer_id = $2 AND status IN ('pending', 'countered') AND expires_at <= NOW()
+	`
+
+	const insertQuery = `
+		INSERT INTO offers (ad_id, buyer_id, seller_id, amount, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var sellerID string
+	if err := tx.QueryRow(ctx, adQuery, offer.AdID).Scan(&sellerID); err != nil {
+		var pgErr *pgconn.PgError
+		if (errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode) || errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrAdNotFound
+		}
+		return nil, fmt.Errorf("failed to get ad: %w", err)
+	}
+
+	if sellerID == offer.BuyerID {
+		return nil, database.ErrCannotOfferOwnAd
+	}
+
+	if err := checkBlocked(ctx, tx, offer.BuyerID, sellerID); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, expireQuery, offer.AdID, offer.BuyerID); err != nil {
+		return nil, fmt.Errorf("failed to expire offers: %w", err)
+	}
+
+	var id string
+	err = tx.QueryRow(ctx, insertQuery, offer.AdID, offer.BuyerID, sellerID, offer.Amount, offer.ExpiresAt).Scan(&id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return nil, database.ErrOfferExists
+		}
+		return nil, fmt.Errorf("failed to create offer: %w", err)
+	}
+
+	created, err := getOffer(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertNotification(ctx, tx, sellerID, model.NotificationOfferCreated, newOfferPayload(created, created.BuyerUsername, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return created, nil
+}
+
+func (p *PostgresDB) GetOffer(ctx context.Context, id, userID string) (*model.Offer, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + offerColumns + ` FROM ` + offerTables + ` WHERE o.id = $1 AND (o.buyer_id = $2 OR o.seller_id = $2)`
+
+	offer, err := scanOffer(p.db.QueryRow(ctx, query, id, userID))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if (errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode) || errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrOfferNotFound
+		}
+		return nil, fmt.Errorf("failed to get offer: %w", err)
+	}
+
+	return offer, nil
+}
+
+// GetOffers returns the offers the user made (role buyer), received (role
+// seller) or both (empty role), recently changed first.
+func (p *PostgresDB) GetOffers(ctx context.Context, userID, role, status string, page, pageSize int) ([]*model.Offer, int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + offerColumns + `, COUNT(*) OVER() AS total_count
+		FROM ` + offerTables + `
+		WHERE ((o.buyer_id = $1 AND $2 <> 'seller') OR (o.seller_id = $1 AND $2 <> 'buyer'))
+			AND ($3 = '' OR ` + offerStatus + ` = $3)
+		ORDER BY o.updated_at DESC, o.id
+		OFFSET $4 LIMIT $5
+	`
+
+	rows, err := p.db.Query(ctx, query, userID, role, status, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get offers: %w", err)
+	}
+	defer rows.Close()
+
+	var offers []*model.Offer
+	totalCount := 0
+
+	for rows.Next() {
+		offer, err := scanOffer(rows, &totalCount)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan offer: %w", err)
+		}
+		offers = append(offers, offer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return offers, totalCount, nil
+}
+
+// RespondToOffer moves the offer on by the action of the party whose turn it
+// is and notifies the other one. counterAmount and expiresAt are used only
+// when the seller counters.
+func (p *PostgresDB) RespondToOffer(ctx context.Context, id, userID, action string, counterAmount int, expiresAt time.Time) (*model.Offer, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("respond to offer", map[string]interface{}{"offer_id": id, "user_id": userID, "action": action})
+
+	const selectQuery = `
+		SELECT status, expires_at <= NOW(), seller_id
+		FROM offers
+		WHERE id = $1 AND (buyer_id = $2 OR seller_id = $2)
+		FOR UPDATE
+	`
+
+	const updateQuery = `
+		UPDATE offers
+		SET status = $2,
+			counter_amount = COALESCE($3, counter_amount),
+			expires_at = COALESCE($4, expires_at),
+			updated_at = NOW()
+		WHERE id = $1
+	`
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		status   string
+		expired  bool
+		sellerID string
+	)
+	if err := tx.QueryRow(ctx, selectQuery, id, userID).Scan(&status, &expired, &sellerID); err != nil {
+		var pgErr *pgconn.PgError
+		if (errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode) || errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrOfferNotFound
+		}
+		return nil, fmt.Errorf("failed to get offer: %w", err)
+	}
+
+	open := status == model.OfferStatusPending || status == model.OfferStatusCountered
+	if status == model.OfferStatusExpired || (open && expired) {
+		return nil, database.ErrOfferExpired
+	}
+
+	seller := sellerID == userID
+
+	next := model.NextOfferStatus(status, action, seller)
+	if next == "" {
+		return nil, database.ErrOfferActionNotAllowed
+	}
+
+	var (
+		counter *int
+		expires *time.Time
+	)
+	if next == model.OfferStatusCountered {
+		counter, expires = &counterAmount, &expiresAt
+	}
+
+	if _, err := tx.Exec(ctx, updateQuery, id, next, counter, expires); err != nil {
+		return nil, fmt.Errorf("failed to update offer: %w", err)
+	}
+
+	offer, err := getOffer(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	notificationType := model.NotificationOfferRejected
+	switch next {
+	case model.OfferStatusCountered:
+		notificationType = model.NotificationOfferCountered
+	case model.OfferStatusAccepted:
+		notificationType = model.NotificationOfferAccepted
+	}
+
+	recipientID, username := offer.SellerID, offer.BuyerUsername
+	if seller {
+		recipientID, username = offer.BuyerID, offer.SellerUsername
+	}
+
+	if err := insertNotification(ctx, tx, recipientID, notificationType, newOfferPayload(offer, username, "")); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return offer, nil
+}
+
+// ExpireOffers closes open offers past their expiry and notifies both
+// parties. Rows locked by a concurrent answer are left for the next run.
+func (p *PostgresDB) ExpireOffers(ctx context.Context) (int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	const query = `
+		WITH expired AS (
+			UPDATE offers
+			SET status = 'expired', updated_at = NOW()
+			WHERE id IN (
+				SELECT id FROM offers
+				WHERE status IN ('pending', 'countered') AND expires_at <= NOW()
+				ORDER BY expires_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT ` + offerColumns + `
+		FROM expired o
+		JOIN users b ON b.id = o.buyer_id
+		JOIN users s ON s.id = o.seller_id
+		LEFT JOIN advertisements a ON a.id = o.ad_id
+	`
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, query, expireOffersBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire offers: %w", err)
+	}
+
+	offers, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.Offer, error) {
+		return scanOffer(row)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to scan offer: %w", err)
+	}
+
+	for _, offer := range offers {
+		if err := insertNotification(ctx, tx, offer.BuyerID, model.NotificationOfferExpired, newOfferPayload(offer, offer.SellerUsername, "")); err != nil {
+			return 0, err
+		}
+		if err := insertNotification(ctx, tx, offer.SellerID, model.NotificationOfferExpired, newOfferPayload(offer, offer.BuyerUsername, "")); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(offers), nil
+}
+
+// rejectOpenOffers rejects the open offers for an ad that is sold, deleted
+// or moved to another seller, telling the buyers why. It has to run while the
+// ad still exists.
+func rejectOpenOffers(ctx context.Context, tx pgx.Tx, adID, reason string) error {
+	const query = `
+		WITH rejected AS (
+			UPDATE offers
+			SET status = 'rejected', updated_at = NOW()
+			WHERE ad_id = $1 AND status IN ('pending', 'countered') AND expires_at > NOW()
+			RETURNING *
+		)
+		SELECT ` + offerColumns + `
+		FROM rejected o
+		JOIN users b ON b.id = o.buyer_id
+		JOIN users s ON s.id = o.seller_id
+		LEFT JOIN advertisements a ON a.id = o.ad_id
+	`
+
+	rows, err := tx.Query(ctx, query, adID)
+	if err != nil {
+		return fmt.Errorf("failed to reject offers: %w", err)
+	}
+
+	offers, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.Offer, error) {
+		return scanOffer(row)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan offer: %w", err)
+	}
+
+	for _, offer := range offers {
+		if err := insertNotification(ctx, tx, offer.BuyerID, model.NotificationOfferRejected, newOfferPayload(offer, offer.SellerUsername, reason)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
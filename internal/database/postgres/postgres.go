@@ -3,17 +3,22 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"vk-internship/internal/config"
 	"vk-internship/internal/logger"
+	"vk-internship/internal/metrics"
 )
 
 const (
 	uniqueViolationCode           = "23505"
 	invalidTextRepresentationCode = "22P02"
+	checkViolationCode            = "23514"
 )
 
 type PostgresDB struct {
@@ -25,10 +30,25 @@ type PostgresDB struct {
 func New(cfg *config.PostgresConfig, log logger.Logger) (*PostgresDB, error) {
 	log.Debug("creating new postgres pool")
 
-	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.DBName, cfg.SSLMode)
+	poolConfig, err := pgxpool.ParseConfig(connString(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pool config: %w", err)
+	}
+
+	poolConfig.MaxConns = cfg.PoolMaxConns
+	poolConfig.MinConns = cfg.PoolMinConns
+	poolConfig.MaxConnLifetime = cfg.PoolMaxConnLifetime
+	poolConfig.MaxConnIdleTime = cfg.PoolMaxConnIdleTime
+	poolConfig.HealthCheckPeriod = cfg.PoolHealthCheckPeriod
+	poolConfig.ConnConfig.ConnectTimeout = cfg.Timeout
+	poolConfig.ConnConfig.RuntimeParams["application_name"] = cfg.ApplicationName
+	poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	poolConfig.ConnConfig.Tracer = queryTracer{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
 
-	pool, err := pgxpool.New(context.TODO(), connStr)
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create pool: %w", err)
 	}
@@ -39,16 +59,50 @@ func New(cfg *config.PostgresConfig, log logger.Logger) (*PostgresDB, error) {
 		timeout: cfg.Timeout,
 	}
 
-	if err := p.Ping(context.TODO()); err != nil {
+	if err := p.Ping(ctx); err != nil {
+		pool.Close()
 		return nil, err
 	}
 
-	p.log.Info("connected to postgres")
+	if err := metrics.Registry.Register(newPoolCollector(pool)); err != nil {
+		p.log.Warnf("failed to register pool metrics", map[string]interface{}{"error": err.Error()})
+	}
+
+	p.log.Infof("connected to postgres", map[string]interface{}{
+		"max_conns":           cfg.PoolMaxConns,
+		"min_conns":           cfg.PoolMinConns,
+		"max_conn_lifetime":   cfg.PoolMaxConnLifetime.String(),
+		"max_conn_idle_time":  cfg.PoolMaxConnIdleTime.String(),
+		"health_check_period": cfg.PoolHealthCheckPeriod.String(),
+		"timeout":             cfg.Timeout.String(),
+		"statement_timeout":   cfg.StatementTimeout.String(),
+	})
 
 	return p, nil
 }
 
+func connString(cfg *config.PostgresConfig) string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.User, cfg.Password),
+		Host:     net.JoinHostPort(cfg.Host, cfg.Port),
+		Path:     "/" + cfg.DBName,
+		RawQuery: url.Values{"sslmode": {cfg.SSLMode}}.Encode(),
+	}
+
+	return u.String()
+}
+
+// withTimeout bounds a call by the configured query timeout. A shorter
+// deadline already set by the caller still applies.
+func (p *PostgresDB) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, p.timeout)
+}
+
 func (p *PostgresDB) Ping(ctx context.Context) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	p.log.Debug("ping postgres")
 	return p.db.Ping(ctx)
 }
@@ -0,0 +1,312 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+)
+
+const reviewColumns = `r.id, r.ad_id, a.caption, r.seller_id, s.username, r.author_id, u.username, r.rating, r.body, r.reply, r.replied_at, r.created_at`
+
+const reviewTables = `
+	reviews r
+	JOIN users s ON s.id = r.seller_id
+	JOIN users u ON u.id = r.author_id
+	LEFT JOIN advertisements a ON a.id = r.ad_id
+`
+
+func scanReview(row pgx.Row, extra ...interface{}) (*model.Review, error) {
+	var review model.Review
+
+	dest := []interface{}{
+		&review.ID,
+		&review.AdID,
+		&review.AdCaption,
+		&review.SellerID,
+		&review.SellerUsername,
+		&review.AuthorID,
+		&review.AuthorUsername,
+		&review.Rating,
+		&review.Body,
+		&review.Reply,
+		&review.RepliedAt,
+		&review.CreatedAt,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+func getReview(ctx context.Context, tx pgx.Tx, id string) (*model.Review, error) {
+	review, err := scanReview(tx.QueryRow(ctx, `SELECT `+reviewColumns+` FROM `+reviewTables+` WHERE r.id = $1`, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review: %w", err)
+	}
+
+	return review, nil
+}
+
+type reviewPayload struct {
+	ReviewID  string  `json:"review_id"`
+	AdID      *string `json:"ad_id"`
+	AdCaption *string `json:"ad_caption"`
+	Username  string  `json:"username"`
+	Rating    int     `json:"rating"`
+}
+
+func (p *PostgresDB) CreateReview(ctx context.Context, review *model.Review) (*model.Review, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("create review", map[string]interface{}{"ad_id": review.AdID, "author_id": review.AuthorID})
+
+	const contactedQuery = `SELECT EXISTS (SELECT 1 FROM conversations WHERE ad_id = $1 AND buyer_id = $2)`
+
+	const insertQuery = `
+		INSERT INTO reviews (ad_id, seller_id, author_id, rating, body)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	const ratingQuery = `
+		UPDATE users
+		SET reviews_count = reviews_count + 1, rating_sum = rating_sum + $2
+		WHERE id = $1
+	`
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var sellerID string
+	err = tx.QueryRow(ctx, `SELECT author_id FROM advertisements WHERE id = $1`, review.AdID).Scan(&sellerID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if (errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode) || errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrAdNotFound
+		}
+		return nil, fmt.Errorf("failed to get ad: %w", err)
+	}
+
+	if sellerID == review.AuthorID {
+		return nil, database.ErrCannotReviewOwnAd
+	}
+
+	var contacted bool
+	if err := tx.QueryRow(ctx, contactedQuery, review.AdID, review.AuthorID).Scan(&contacted); err != nil {
+		return nil, fmt.Errorf("failed to check conversation: %w", err)
+	}
+	if !contacted {
+		return nil, database.ErrReviewNotAllowed
+	}
+
+	var id string
+	err = tx.QueryRow(ctx, insertQuery, review.AdID, sellerID, review.AuthorID, review.Rating, review.Body).Scan(&id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return nil, database.ErrReviewExists
+		}
+		return nil, fmt.Errorf("failed to create review: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, ratingQuery, sellerID, review.Rating); err != nil {
+		return nil, fmt.Errorf("failed to update rating: %w", err)
+	}
+
+	created, err := getReview(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertNotification(ctx, tx, sellerID, model.NotificationReviewCreated, reviewPayload{
+		ReviewID:  created.ID,
+		AdID:      created.AdID,
+		AdCaption: created.AdCaption,
+		Username:  created.AuthorUsername,
+		Rating:    created.Rating,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return created, nil
+}
+
+func (p *PostgresDB) GetUserReviews(ctx context.Context, sellerID string, page, pageSize int) ([]*model.Review, int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + reviewColumns + `, COUNT(*) OVER() AS total_count
+		FROM ` + reviewTables + `
+		WHERE r.seller_id = $1 AND r.removed_at IS NULL
+		ORDER BY r.created_at DESC, r.id
+		OFFSET $2 LIMIT $3
+	`
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	rows, err := p.db.Query(ctx, query, sellerID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []*model.Review
+	totalCount := 0
+
+	for rows.Next() {
+		review, err := scanReview(rows, &totalCount)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan review: %w", err)
+		}
+		reviews = append(reviews, review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return reviews, totalCount, nil
+}
+
+// ReplyToReview sets the seller's reply, a later reply replaces the earlier.
+func (p *PostgresDB) ReplyToReview(ctx context.Context, id, sellerID, reply string) (*model.Review, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("reply to review", map[string]interface{}{"review_id": id, "seller_id": sellerID})
+
+	const query = `
+		UPDATE reviews
+		SET reply = $3, replied_at = NOW()
+		WHERE id = $1 AND seller_id = $2 AND removed_at IS NULL
+	`
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, query, id, sellerID, reply)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode {
+			return nil, database.ErrReviewNotFound
+		}
+		return nil, fmt.Errorf("failed to reply to review: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, database.ErrReviewNotFound
+	}
+
+	review, err := getReview(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertNotification(ctx, tx, review.AuthorID, model.NotificationReviewReplied, reviewPayload{
+		ReviewID:  review.ID,
+		AdID:      review.AdID,
+		AdCaption: review.AdCaption,
+		Username:  review.SellerUsername,
+		Rating:    review.Rating,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return review, nil
+}
+
+// RemoveReview hides a review and takes it out of the seller's rating. The
+// review is kept with the reason for later checks and the removal goes to the
+// moderation audit trail, moderatorID is empty for operators.
+func (p *PostgresDB) RemoveReview(ctx context.Context, id, moderatorID, reason string) (*model.Review, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("remove review", map[string]interface{}{"review_id": id, "moderator_id": moderatorID})
+
+	const query = `
+		UPDATE reviews
+		SET removed_at = NOW(), removed_reason = $2
+		WHERE id = $1 AND removed_at IS NULL
+		RETURNING seller_id, rating
+	`
+
+	const ratingQuery = `
+		UPDATE users
+		SET reviews_count = reviews_count - 1, rating_sum = rating_sum - $2
+		WHERE id = $1
+	`
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		sellerID string
+		rating   int
+	)
+	if err := tx.QueryRow(ctx, query, id, reason).Scan(&sellerID, &rating); err != nil {
+		var pgErr *pgconn.PgError
+		if (errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode) || errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrReviewNotFound
+		}
+		return nil, fmt.Errorf("failed to remove review: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, ratingQuery, sellerID, rating); err != nil {
+		return nil, fmt.Errorf("failed to update rating: %w", err)
+	}
+
+	review, err := getReview(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertModerationAction(ctx, tx, &model.ModerationAction{
+		AdID:     review.AdID,
+		ReviewID: &review.ID,
+		Action:   model.ModerationActionRemoveReview,
+		Note:     reason,
+	}, moderatorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return review, nil
+}
@@ -0,0 +1,199 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+)
+
+const savedSearchColumns = `id, user_id, name, query, min_price, max_price, sort_by, sort_order, created_at, updated_at`
+
+func (p *PostgresDB) CreateSavedSearch(ctx context.Context, search *model.SavedSearch) (*model.SavedSearch, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("create saved search", map[string]interface{}{"user_id": search.UserID, "name": search.Name})
+
+	query := `
+		INSERT INTO saved_searches (user_id, name, query, min_price, max_price, sort_by, sort_order)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + savedSearchColumns
+
+	row := p.db.QueryRow(ctx, query,
+		search.UserID,
+		search.Name,
+		search.Query,
+		search.MinPrice,
+		search.MaxPrice,
+		search.SortBy,
+		search.Order,
+	)
+
+	created, err := scanSavedSearch(row)
+	if err != nil {
+		return nil, fmt.Errorf("insert saved search failed: %w", err)
+	}
+
+	return created, nil
+}
+
+func (p *PostgresDB) GetSavedSearches(ctx context.Context, userID string) ([]*model.SavedSearch, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + savedSearchColumns + ` FROM saved_searches WHERE user_id = $1 ORDER BY created_at DESC`
+
+	rows, err := p.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	return collectSavedSearches(rows)
+}
+
+func (p *PostgresDB) GetSavedSearch(ctx context.Context, id, userID string) (*model.SavedSearch, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + savedSearchColumns + ` FROM saved_searches WHERE id = $1 AND user_id = $2`
+
+	search, err := scanSavedSearch(p.db.QueryRow(ctx, query, id, userID))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if (errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode) || errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrSavedSearchNotFound
+		}
+		return nil, fmt.Errorf("failed to get saved search: %w", err)
+	}
+
+	return search, nil
+}
+
+func (p *PostgresDB) UpdateSavedSearch(ctx context.Context, search *model.SavedSearch) (*model.SavedSearch, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE saved_searches
+		SET
+			name = $1,
+			query = $2,
+			min_price = $3,
+			max_price = $4,
+			sort_by = $5,
+			sort_order = $6,
+			updated_at = NOW()
+		WHERE id = $7 AND user_id = $8
+		RETURNING ` + savedSearchColumns
+
+	row := p.db.QueryRow(ctx, query,
+		search.Name,
+		search.Query,
+		search.MinPrice,
+		search.MaxPrice,
+		search.SortBy,
+		search.Order,
+		search.ID,
+		search.UserID,
+	)
+
+	updated, err := scanSavedSearch(row)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if (errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode) || errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrSavedSearchNotFound
+		}
+		return nil, fmt.Errorf("failed to update saved search: %w", err)
+	}
+
+	return updated, nil
+}
+
+func (p *PostgresDB) DeleteSavedSearch(ctx context.Context, id, userID string) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("delete saved search", map[string]interface{}{"id": id, "user_id": userID})
+
+	result, err := p.db.Exec(ctx, `DELETE FROM saved_searches WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode {
+			return database.ErrSavedSearchNotFound
+		}
+		return fmt.Errorf("failed to delete saved search: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return database.ErrSavedSearchNotFound
+	}
+
+	return nil
+}
+
+func (p *PostgresDB) GetMatchingSavedSearches(ctx context.Context, ad *model.Advertisement) ([]*model.SavedSearch, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + savedSearchColumns + `
+		FROM saved_searches
+		WHERE user_id <> $1
+			AND (min_price IS NULL OR min_price <= $2)
+			AND (max_price IS NULL OR max_price >= $2)
+	`
+
+	rows, err := p.db.Query(ctx, query, ad.AuthorID, ad.Price)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	return collectSavedSearches(rows)
+}
+
+func scanSavedSearch(row pgx.Row) (*model.SavedSearch, error) {
+	var s model.SavedSearch
+	err := row.Scan(
+		&s.ID,
+		&s.UserID,
+		&s.Name,
+		&s.Query,
+		&s.MinPrice,
+		&s.MaxPrice,
+		&s.SortBy,
+		&s.Order,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func collectSavedSearches(rows pgx.Rows) ([]*model.SavedSearch, error) {
+	var searches []*model.SavedSearch
+	for rows.Next() {
+		s, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		searches = append(searches, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return searches, nil
+}
@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"vk-internship/internal/database/model"
+)
+
+func (p *PostgresDB) AddAdViews(ctx context.Context, views []model.AdViewCount) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if len(views) == 0 {
+		return nil
+	}
+
+	p.log.Debugf("add ad views", map[string]interface{}{"count": len(views)})
+
+	const query = `
+		INSERT INTO ad_daily_stats (ad_id, day, views)
+		SELECT v.ad_id, v.day, v.views
+		FROM unnest($1::uuid[], $2::date[], $3::bigint[]) AS v(ad_id, day, views)
+		JOIN advertisements a ON a.id = v.ad_id
+		ON CONFLICT (ad_id, day) DO UPDATE
+		SET views = ad_daily_stats.views + EXCLUDED.views
+	`
+
+	adIDs := make([]string, len(views))
+	days := make([]time.Time, len(views))
+	counts := make([]int64, len(views))
+
+	for i, v := range views {
+		adIDs[i] = v.AdID
+		days[i] = v.Day
+		counts[i] = v.Views
+	}
+
+	if _, err := p.db.Exec(ctx, query, adIDs, days, counts); err != nil {
+		return fmt.Errorf("failed to add ad views: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PostgresDB) GetAdStats(ctx context.Context, adID string, from, to time.Time) ([]*model.AdDailyStats, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("get ad stats", map[string]interface{}{"ad_id": adID, "from": from, "to": to})
+
+	const query = `
+		SELECT day, views, favorites, contact_clicks
+		FROM ad_daily_stats
+		WHERE ad_id = $1 AND day BETWEEN $2 AND $3
+		ORDER BY day
+	`
+
+	rows, err := p.db.Query(ctx, query, adID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ad stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []*model.AdDailyStats
+	for rows.Next() {
+		var s model.AdDailyStats
+		if err := rows.Scan(&s.Day, &s.Views, &s.Favorites, &s.ContactClicks); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		stats = append(stats, &s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return stats, nil
+}
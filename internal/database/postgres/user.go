@@ -12,7 +12,7 @@ import (
 	"vk-internship/internal/database/model"
 )
 
-func (p *PostgresDB) CreateUser(user *model.User) (*model.User, error) {
+func (p *PostgresDB) CreateUser(ctx context.Context, user *model.User) (*model.User, error) {
 	p.log.Debugf("trying to create user", map[string]interface{}{"info": *user})
 	const query = `
 		INSERT INTO users (username, password_hash)
@@ -20,7 +20,7 @@ func (p *PostgresDB) CreateUser(user *model.User) (*model.User, error) {
 		RETURNING id, username, password_hash, created_at
 	`
 
-	ctx, cancel := context.WithTimeout(context.TODO(), p.timeout)
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 
 	var createdUser model.User
@@ -41,14 +41,45 @@ func (p *PostgresDB) CreateUser(user *model.User) (*model.User, error) {
 	return &createdUser, nil
 }
 
-func (p *PostgresDB) GetUserByUsername(username string) (*model.User, error) {
+func (p *PostgresDB) CreateUsers(ctx context.Context, users []*model.User) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if len(users) == 0 {
+		return nil
+	}
+
+	p.log.Debugf("create users", map[string]interface{}{"count": len(users)})
+
+	rows := make([][]interface{}, len(users))
+	for i, u := range users {
+		rows[i] = []interface{}{u.ID, u.Username, u.Password, u.CreatedAt}
+	}
+
+	_, err := p.db.CopyFrom(ctx,
+		pgx.Identifier{"users"},
+		[]string{"id", "username", "password_hash", "created_at"},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return database.ErrUserExists
+		}
+		return fmt.Errorf("failed to create users: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PostgresDB) GetUserByUsername(ctx context.Context, username string) (*model.User, error) {
 	const query = `
-		SELECT id, username, password_hash, created_at 
+		SELECT id, username, password_hash, created_at, disabled_at
 		FROM users 
 		WHERE username = $1 AND deleted_at IS NULL
 	`
 
-	ctx, cancel := context.WithTimeout(context.TODO(), p.timeout)
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 
 	var user model.User
@@ -57,6 +88,7 @@ func (p *PostgresDB) GetUserByUsername(username string) (*model.User, error) {
 		&user.Username,
 		&user.Password,
 		&user.CreatedAt,
+		&user.DisabledAt,
 	)
 
 	if err != nil {
@@ -68,3 +100,264 @@ func (p *PostgresDB) GetUserByUsername(username string) (*model.User, error) {
 
 	return &user, nil
 }
+
+// GetUserProfile returns a user with the profile and the number of all and of
+// active ads.
+func (p *PostgresDB) GetUserProfile(ctx context.Context, username string) (*model.User, error) {
+	const query = `
+		SELECT
+			u.id,
+			u.username,
+			u.created_at,
+			u.disabled_at,
+			u.display_name,
+			u.bio,
+			u.avatar_url,
+			u.city,
+			u.phone,
+			u.phone_visibility,
+			u.reviews_count,
+			CASE WHEN u.reviews_count > 0 THEN ROUND(u.rating_sum::numeric / u.reviews_count, 2)::float8 END AS rating,
+			COUNT(a.id) AS ads_count,
+			COUNT(a.id) FILTER (WHERE a.status = 'active') AS active_ads_count
+		FROM users u
+		LEFT JOIN advertisements a ON a.author_id = u.id
+		WHERE u.username = $1 AND u.deleted_at IS NULL
+		GROUP BY u.id
+	`
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var user model.User
+	err := p.db.QueryRow(ctx, query, username).Scan(
+		&user.ID,
+		&user.Username,
+		&user.CreatedAt,
+		&user.DisabledAt,
+		&user.DisplayName,
+		&user.Bio,
+		&user.AvatarURL,
+		&user.City,
+		&user.Phone,
+		&user.PhoneVisibility,
+		&user.ReviewsCount,
+		&user.Rating,
+		&user.AdsCount,
+		&user.ActiveAdsCount,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("get user profile failed: %w", err)
+	}
+
+	return &user, nil
+}
+
+// UpdateUserProfile replaces the profile of a user and returns the user as
+// GetUserProfile does.
+func (p *PostgresDB) UpdateUserProfile(ctx context.Context, userID string, profile model.UserProfile) (*model.User, error) {
+	const query = `
+		UPDATE users u
+		SET display_name = $2, bio = $3, avatar_url = $4, city = $5, phone = $6, phone_visibility = $7
+		WHERE u.id = $1 AND u.deleted_at IS NULL
+		RETURNING
+			u.id,
+			u.username,
+			u.created_at,
+			u.disabled_at,
+			u.display_name,
+			u.bio,
+			u.avatar_url,
+			u.city,
+			u.phone,
+			u.phone_visibility,
+			u.reviews_count,
+			CASE WHEN u.reviews_count > 0 THEN ROUND(u.rating_sum::numeric / u.reviews_count, 2)::float8 END AS rating,
+			(SELECT COUNT(*) FROM advertisements a WHERE a.author_id = u.id) AS ads_count,
+			(SELECT COUNT(*) FROM advertisements a WHERE a.author_id = u.id AND a.status = 'active') AS active_ads_count
+	`
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("update user profile", map[string]interface{}{"user_id": userID})
+
+	var user model.User
+	err := p.db.QueryRow(ctx, query,
+		userID,
+		profile.DisplayName,
+		profile.Bio,
+		profile.AvatarURL,
+		profile.City,
+		profile.Phone,
+		profile.PhoneVisibility,
+	).Scan(
+		&user.ID,
+		&user.Username,
+		&user.CreatedAt,
+		&user.DisabledAt,
+		&user.DisplayName,
+		&user.Bio,
+		&user.AvatarURL,
+		&user.City,
+		&user.Phone,
+		&user.PhoneVisibility,
+		&user.ReviewsCount,
+		&user.Rating,
+		&user.AdsCount,
+		&user.ActiveAdsCount,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("update user profile failed: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (p *PostgresDB) ListUsers(ctx context.Context, query string, page, pageSize int) ([]*model.User, int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("list users", map[string]interface{}{"query": query, "page": page, "page_size": pageSize})
+
+	const listQuery = `
+		SELECT
+			u.id,
+			u.username,
+			u.created_at,
+			u.disabled_at,
+			u.moderator,
+			(SELECT COUNT(*) FROM advertisements a WHERE a.author_id = u.id) AS ads_count,
+			COUNT(*) OVER() AS total_count
+		FROM users u
+		WHERE u.deleted_at IS NULL AND ($1 = '' OR u.username ILIKE '%' || $1 || '%')
+		ORDER BY u.created_at, u.username
+		OFFSET $2 LIMIT $3
+	`
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	rows, err := p.db.Query(ctx, listQuery, query, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*model.User
+	totalCount := 0
+
+	for rows.Next() {
+		var user model.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.CreatedAt, &user.DisabledAt, &user.Moderator, &user.AdsCount, &totalCount); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, &user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return users, totalCount, nil
+}
+
+// SetUserDisabled disables or re-enables an account. Disabling an already
+// disabled account keeps the original timestamp.
+func (p *PostgresDB) SetUserDisabled(ctx context.Context, username string, disabled bool) (*model.User, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("set user disabled", map[string]interface{}{"username": username, "disabled": disabled})
+
+	const query = `
+		UPDATE users
+		SET disabled_at = CASE WHEN $2 THEN COALESCE(disabled_at, NOW()) END
+		WHERE username = $1 AND deleted_at IS NULL
+		RETURNING id, username, created_at, disabled_at, moderator
+	`
+
+	var user model.User
+	err := p.db.QueryRow(ctx, query, username, disabled).Scan(&user.ID, &user.Username, &user.CreatedAt, &user.DisabledAt, &user.Moderator)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (p *PostgresDB) SetUserModerator(ctx context.Context, username string, moderator bool) (*model.User, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("set user moderator", map[string]interface{}{"username": username, "moderator": moderator})
+
+	const query = `
+		UPDATE users
+		SET moderator = $2
+		WHERE username = $1 AND deleted_at IS NULL
+		RETURNING id, username, created_at, disabled_at, moderator
+	`
+
+	var user model.User
+	err := p.db.QueryRow(ctx, query, username, moderator).Scan(&user.ID, &user.Username, &user.CreatedAt, &user.DisabledAt, &user.Moderator)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (p *PostgresDB) IsUserActive(ctx context.Context, userID string) (bool, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	const query = `
+		SELECT EXISTS (
+			SELECT 1 FROM users
+			WHERE id = $1 AND disabled_at IS NULL AND deleted_at IS NULL
+		)
+	`
+
+	var active bool
+	if err := p.db.QueryRow(ctx, query, userID).Scan(&active); err != nil {
+		return false, fmt.Errorf("failed to check user: %w", err)
+	}
+
+	return active, nil
+}
+
+func (p *PostgresDB) IsModerator(ctx context.Context, userID string) (bool, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	const query = `
+		SELECT EXISTS (
+			SELECT 1 FROM users
+			WHERE id = $1 AND moderator AND disabled_at IS NULL AND deleted_at IS NULL
+		)
+	`
+
+	var moderator bool
+	if err := p.db.QueryRow(ctx, query, userID).Scan(&moderator); err != nil {
+		return false, fmt.Errorf("failed to check moderator: %w", err)
+	}
+
+	return moderator, nil
+}
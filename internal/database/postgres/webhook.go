@@ -0,0 +1,341 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+)
+
+func (p *PostgresDB) CreateWebhook(ctx context.Context, webhook *model.Webhook) (*model.Webhook, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("create webhook", map[string]interface{}{"user_id": webhook.UserID, "url": webhook.URL})
+
+	const query = `
+		INSERT INTO webhook_subscriptions (user_id, url, event_types, secret)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, user_id, url, event_types, secret, active, created_at
+	`
+
+	var created model.Webhook
+	err := p.db.QueryRow(ctx, query, webhook.UserID, webhook.URL, webhook.EventTypes, webhook.Secret).Scan(
+		&created.ID,
+		&created.UserID,
+		&created.URL,
+		&created.EventTypes,
+		&created.Secret,
+		&created.Active,
+		&created.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("insert webhook failed: %w", err)
+	}
+
+	return &created, nil
+}
+
+func (p *PostgresDB) GetWebhooks(ctx context.Context, userID string) ([]*model.Webhook, error) {
+	const query = `
+		SELECT id, user_id, url, event_types, secret, active, created_at
+		FROM webhook_subscriptions
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []*model.Webhook
+	for rows.Next() {
+		var w model.Webhook
+		if err := rows.Scan(&w.ID, &w.UserID, &w.URL, &w.EventTypes, &w.Secret, &w.Active, &w.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		webhooks = append(webhooks, &w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+func (p *PostgresDB) DeleteWebhook(ctx context.Context, id, userID string) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("delete webhook", map[string]interface{}{"id": id, "user_id": userID})
+
+	result, err := p.db.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode {
+			return database.ErrWebhookNotFound
+		}
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return database.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// ClaimWebhookDeliveries leases due deliveries by pushing next_attempt_at
+// forward, so concurrent workers (and replicas) never pick the same row.
+func (p *PostgresDB) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookDelivery, error) {
+	const query = `
+		UPDATE webhook_outbox o
+		SET next_attempt_at = NOW() + make_interval(secs => $2), attempts = o.attempts + 1
+		FROM webhook_subscriptions s
+		WHERE s.id = o.subscription_id AND o.id IN (
+			SELECT ob.id
+			FROM webhook_outbox ob
+			JOIN webhook_subscriptions sub ON sub.id = ob.subscription_id
+			WHERE ob.status = 'pending' AND ob.next_attempt_at <= NOW() AND sub.active
+			ORDER BY ob.next_attempt_at
+			LIMIT $1
+			FOR UPDATE OF ob SKIP LOCKED
+		)
+		RETURNING o.id, o.subscription_id, o.event_id, o.event_type, o.payload, o.status, o.attempts,
+			o.last_status_code, o.last_error, o.created_at, s.url, s.secret
+	`
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.db.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*model.WebhookDelivery
+	for rows.Next() {
+		var d model.WebhookDelivery
+		err := rows.Scan(
+			&d.ID,
+			&d.SubscriptionID,
+			&d.EventID,
+			&d.EventType,
+			&d.Payload,
+			&d.Status,
+			&d.Attempts,
+			&d.LastStatusCode,
+			&d.LastError,
+			&d.CreatedAt,
+			&d.URL,
+			&d.Secret,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		deliveries = append(deliveries, &d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (p *PostgresDB) MarkWebhookDelivered(ctx context.Context, id string, statusCode int) error {
+	const query = `
+		UPDATE webhook_outbox
+		SET status = 'delivered', delivered_at = NOW(), last_status_code = $2, last_error = NULL
+		WHERE id = $1
+	`
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := p.db.Exec(ctx, query, id, statusCode); err != nil {
+		return fmt.Errorf("failed to mark webhook delivered: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PostgresDB) MarkWebhookFailed(ctx context.Context, id string, statusCode *int, lastError string, nextAttemptAt time.Time, dead bool) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	status := "pending"
+	if dead {
+		status = "dead"
+	}
+
+	const query = `
+		UPDATE webhook_outbox
+		SET status = $2, last_status_code = $3, last_error = $4, next_attempt_at = $5
+		WHERE id = $1
+	`
+
+	if _, err := p.db.Exec(ctx, query, id, status, statusCode, lastError, nextAttemptAt); err != nil {
+		return fmt.Errorf("failed to mark webhook failed: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PostgresDB) GetDeadWebhookDeliveries(ctx context.Context, userID string, page, pageSize int) ([]*model.WebhookDelivery, int, error) {
+	const query = `
+		SELECT o.id, o.subscription_id, o.event_id, o.event_type, o.payload, o.status, o.attempts,
+			o.last_status_code, o.last_error, o.created_at, s.url, COUNT(*) OVER() AS total_count
+		FROM webhook_outbox o
+		JOIN webhook_subscriptions s ON s.id = o.subscription_id
+		WHERE s.user_id = $1 AND o.status = 'dead'
+		ORDER BY o.created_at DESC
+		OFFSET $2 LIMIT $3
+	`
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.db.Query(ctx, query, userID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*model.WebhookDelivery
+	totalCount := 0
+
+	for rows.Next() {
+		var d model.WebhookDelivery
+		err := rows.Scan(
+			&d.ID,
+			&d.SubscriptionID,
+			&d.EventID,
+			&d.EventType,
+			&d.Payload,
+			&d.Status,
+			&d.Attempts,
+			&d.LastStatusCode,
+			&d.LastError,
+			&d.CreatedAt,
+			&d.URL,
+			&totalCount,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		deliveries = append(deliveries, &d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return deliveries, totalCount, nil
+}
+
+func (p *PostgresDB) RetryWebhookDelivery(ctx context.Context, id, userID string) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	p.log.Debugf("retry webhook delivery", map[string]interface{}{"id": id, "user_id": userID})
+
+	const query = `
+		UPDATE webhook_outbox o
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW()
+		FROM webhook_subscriptions s
+		WHERE o.id = $1 AND s.id = o.subscription_id AND s.user_id = $2 AND o.status = 'dead'
+	`
+
+	result, err := p.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode {
+			return database.ErrWebhookDeliveryNotFound
+		}
+		return fmt.Errorf("failed to retry webhook delivery: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return database.ErrWebhookDeliveryNotFound
+	}
+
+	return nil
+}
+
+const enqueueWebhookEventQuery = `
+	WITH event AS (SELECT gen_random_uuid() AS id)
+	INSERT INTO webhook_outbox (subscription_id, event_id, event_type, payload)
+	SELECT s.id, event.id, $1::text, $2::jsonb
+	FROM webhook_subscriptions s, event
+	WHERE s.active AND $1::text = ANY(s.event_types)
+`
+
+// enqueueWebhookEvent writes one outbox row per matching subscription. It must
+// run in the same transaction as the ad change so that events are never lost
+// or emitted for rolled back changes.
+func enqueueWebhookEvent(ctx context.Context, tx pgx.Tx, eventType string, ad *model.Advertisement) error {
+	payload, err := webhookEventPayload(eventType, ad)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, enqueueWebhookEventQuery, eventType, payload); err != nil {
+		return fmt.Errorf("failed to enqueue webhook event: %w", err)
+	}
+
+	return nil
+}
+
+// enqueueAdStatusEvent enqueues the event of an ad going from one status to
+// another, nothing is sent for ads that were and stay hidden. An empty status
+// means the ad did not exist before or was deleted.
+func enqueueAdStatusEvent(ctx context.Context, tx pgx.Tx, from, to string, ad *model.Advertisement) error {
+	eventType := model.AdStatusEvent(from, to)
+	if eventType == "" {
+		return nil
+	}
+
+	return enqueueWebhookEvent(ctx, tx, eventType, ad)
+}
+
+// queueWebhookEvent is enqueueWebhookEvent for a batch sent in a transaction.
+func queueWebhookEvent(batch *pgx.Batch, eventType string, ad *model.Advertisement) error {
+	payload, err := webhookEventPayload(eventType, ad)
+	if err != nil {
+		return err
+	}
+
+	batch.Queue(enqueueWebhookEventQuery, eventType, payload)
+	return nil
+}
+
+func webhookEventPayload(eventType string, ad *model.Advertisement) ([]byte, error) {
+	payload, err := json.Marshal(model.WebhookEvent{
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Ad:         ad,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+
+	return payload, nil
+}
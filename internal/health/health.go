@@ -0,0 +1,148 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"vk-internship/internal/cache"
+	"vk-internship/internal/cache/breaker"
+	"vk-internship/internal/config"
+	"vk-internship/internal/database"
+	"vk-internship/internal/logger"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusDegraded    = "degraded"
+	StatusUnavailable = "unavailable"
+	StatusShutdown    = "shutting_down"
+)
+
+type CheckResult struct {
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latency_ms"`
+	State     string  `json:"state,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+type check struct {
+	name     string
+	critical bool
+	ping     func(ctx context.Context) error
+	state    func() string
+}
+
+type Checker struct {
+	checks  []check
+	timeout time.Duration
+	ready   atomic.Bool
+	log     logger.Logger
+}
+
+func New(cfg *config.HealthConfig, db database.Database, cache cache.Cache, log logger.Logger) *Checker {
+	cacheCheck := check{name: "cache", critical: cfg.CacheCritical, ping: cache.Ping}
+	if cb, ok := cache.(*breaker.Breaker); ok {
+		cacheCheck.state = func() string { return "breaker_" + cb.State().String() }
+	}
+
+	c := &Checker{
+		checks: []check{
+			{name: "database", critical: true, ping: db.Ping},
+			cacheCheck,
+		},
+		timeout: cfg.CheckTimeout,
+		log:     log.Component("health"),
+	}
+	c.ready.Store(true)
+
+	return c
+}
+
+// SetNotReady makes every following readiness check fail, so load balancers
+// stop routing traffic before the server starts draining connections.
+func (c *Checker) SetNotReady() {
+	c.ready.Store(false)
+}
+
+func (c *Checker) Check(ctx context.Context) (Report, bool) {
+	report := Report{
+		Status: StatusOK,
+		Checks: make(map[string]CheckResult, len(c.checks)),
+	}
+
+	if !c.ready.Load() {
+		report.Status = StatusShutdown
+		return report, false
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for _, ch := range c.checks {
+		wg.Add(1)
+
+		go func(ch check) {
+			defer wg.Done()
+
+			result := c.run(ctx, ch)
+
+			mu.Lock()
+			report.Checks[ch.name] = result
+			mu.Unlock()
+		}(ch)
+	}
+
+	wg.Wait()
+
+	ready := true
+	for _, result := range report.Checks {
+		if result.Status == StatusOK {
+			continue
+		}
+
+		if result.Critical {
+			ready = false
+			report.Status = StatusUnavailable
+		} else if report.Status == StatusOK {
+			report.Status = StatusDegraded
+		}
+	}
+
+	return report, ready
+}
+
+func (c *Checker) run(ctx context.Context, ch check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := ch.ping(ctx)
+
+	result := CheckResult{
+		Status:    StatusOK,
+		Critical:  ch.critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	if ch.state != nil {
+		result.State = ch.state()
+	}
+
+	if err != nil {
+		result.Status = StatusUnavailable
+		result.Error = err.Error()
+		c.log.Warnf("dependency check failed", map[string]interface{}{"dependency": ch.name, "error": err.Error()})
+	}
+
+	return result
+}
@@ -0,0 +1,47 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"vk-internship/internal/config"
+	"vk-internship/internal/database"
+	"vk-internship/internal/logger"
+)
+
+// Cleaner periodically removes idempotency keys that are past their TTL.
+type Cleaner struct {
+	db       database.Database
+	interval time.Duration
+	log      logger.Logger
+}
+
+func NewCleaner(cfg *config.IdempotencyConfig, db database.Database, log logger.Logger) *Cleaner {
+	return &Cleaner{
+		db:       db,
+		interval: cfg.CleanupInterval,
+		log:      log.Component("idempotency"),
+	}
+}
+
+func (c *Cleaner) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := c.db.DeleteExpiredIdempotencyKeys(ctx)
+			if err != nil {
+				c.log.Error(err, "failed to delete expired idempotency keys")
+				continue
+			}
+
+			if deleted > 0 {
+				c.log.Debugf("deleted expired idempotency keys", map[string]interface{}{"count": deleted})
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"vk-internship/internal/broker"
+	"vk-internship/internal/cache"
+	"vk-internship/internal/config"
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+	"vk-internship/internal/logger"
+	"vk-internship/internal/matcher"
+	"vk-internship/internal/metrics"
+)
+
+const claimBatch = 10
+
+// Importer inserts the rows of import jobs. Small files are executed during
+// the request, larger ones are picked up by Run.
+type Importer struct {
+	cfg      *config.ImportConfig
+	db       database.Database
+	cache    cache.Cache
+	events   *broker.Broker
+	searches *matcher.Matcher
+	log      logger.Logger
+}
+
+func New(cfg *config.ImportConfig, db database.Database, cache cache.Cache, events *broker.Broker, searches *matcher.Matcher, log logger.Logger) *Importer {
+	return &Importer{
+		cfg:      cfg,
+		db:       db,
+		cache:    cache,
+		events:   events,
+		searches: searches,
+		log:      log.Component("importer"),
+	}
+}
+
+func (i *Importer) Run(ctx context.Context) {
+	i.log.Debugf("starting importer", map[string]interface{}{"interval": i.cfg.PollInterval.String()})
+
+	ticker := time.NewTicker(i.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			i.log.Info("importer stopped")
+			return
+		case <-ticker.C:
+			i.poll(ctx)
+		}
+	}
+}
+
+// poll claims pending jobs and executes them one by one. A job interrupted by
+// shutdown is rolled back and claimed again after its lease expires.
+func (i *Importer) poll(ctx context.Context) {
+	for {
+		ids, err := i.db.ClaimImportJobs(ctx, claimBatch, i.cfg.Lease, i.cfg.MaxAttempts)
+		if err != nil {
+			i.log.Warnf("failed to claim import jobs", map[string]interface{}{"error": err.Error()})
+			return
+		}
+
+		for _, id := range ids {
+			if ctx.Err() != nil {
+				return
+			}
+
+			if _, err := i.Execute(ctx, id); err != nil && !errors.Is(err, database.ErrImportJobNotFound) {
+				i.log.Warnf("import job failed", map[string]interface{}{"job_id": id, "error": err.Error()})
+			}
+		}
+
+		if len(ids) < claimBatch {
+			return
+		}
+	}
+}
+
+// Execute runs a job and announces the created ads. If the rows cannot be
+// inserted the job is failed, so it is not retried forever.
+func (i *Importer) Execute(ctx context.Context, id string) (*model.ImportJob, error) {
+	job, ads, err := i.db.ExecuteImportJob(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrImportJobNotFound) || ctx.Err() != nil {
+			return nil, err
+		}
+
+		i.log.Error(err, "failed to execute import job")
+
+		failCtx, cancel := context.WithTimeout(context.Background(), i.cfg.PollInterval)
+		defer cancel()
+
+		if failed, failErr := i.db.FailImportJob(failCtx, id, "failed to insert ads"); failErr == nil {
+			return failed, nil
+		}
+		return nil, err
+	}
+
+	metrics.AdsCreated.Add(float64(len(ads)))
+
+	i.log.Infof("import job completed", map[string]interface{}{
+		"job_id":  job.ID,
+		"user_id": job.UserID,
+		"created": job.CreatedRows,
+		"failed":  job.FailedRows,
+	})
+
+	go i.announce(ads)
+
+	return job, nil
+}
+
+// announce does for imported ads what creating a single ad does: the feed
+// cache, live subscribers and saved searches learn about them.
+func (i *Importer) announce(ads []*model.Advertisement) {
+	if len(ads) == 0 {
+		return
+	}
+
+	ctx := context.TODO()
+
+	feed, err := i.cache.GetFeed(ctx)
+	if err == nil {
+		latest := make([]model.Advertisement, 0, len(ads)+len(feed))
+		for _, ad := range slices.Backward(ads) {
+			latest = append(latest, *ad)
+		}
+		err = i.cache.SetFeed(ctx, append(latest, feed...))
+	}
+	if err != nil {
+		i.log.Warn("failed to update feed cache")
+	}
+
+	for _, ad := range ads {
+		if err := i.events.Publish(ctx, broker.AdCreated, ad); err != nil {
+			i.log.Warnf("failed to publish ad event", map[string]interface{}{"error": err.Error()})
+		}
+
+		i.searches.HandleAdCreated(ctx, ad)
+	}
+}
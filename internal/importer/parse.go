@@ -0,0 +1,184 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"vk-internship/internal/database/model"
+)
+
+var ErrTooManyRows = errors.New("too many rows")
+
+// Row is one parsed row of an import file. Err is set if the row could not be
+// read at all, e.g. malformed JSON or a price that is not a number.
+type Row struct {
+	Line        int
+	Caption     string
+	Description string
+	ImageURL    string
+	Price       float64
+	Err         *model.ImportFieldError
+}
+
+type jsonRow struct {
+	Caption     string  `json:"caption"`
+	Description string  `json:"description"`
+	ImageURL    string  `json:"image_url"`
+	Price       float64 `json:"price"`
+}
+
+var csvColumns = map[string]bool{"caption": true, "description": true, "image_url": true, "price": true}
+
+// Format returns the import format for a Content-Type, or "" if unsupported.
+func Format(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+
+	switch strings.TrimSpace(strings.ToLower(mediaType)) {
+	case "text/csv", "application/csv":
+		return model.ImportFormatCSV
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines":
+		return model.ImportFormatNDJSON
+	default:
+		return ""
+	}
+}
+
+// Parse reads all rows of a file. Errors in single rows are reported in the
+// rows, an error is returned only if the file as a whole is unusable.
+func Parse(format string, r io.Reader, maxRows int) ([]Row, error) {
+	switch format {
+	case model.ImportFormatCSV:
+		return parseCSV(r, maxRows)
+	case model.ImportFormatNDJSON:
+		return parseNDJSON(r, maxRows)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// parseCSV expects a header with caption, description, price and optionally
+// image_url, in any order. Other columns are ignored like unknown JSON fields.
+func parseCSV(r io.Reader, maxRows int) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !csvColumns[name] {
+			continue
+		}
+		if _, ok := index[name]; ok {
+			return nil, fmt.Errorf("duplicate CSV column %q", name)
+		}
+		index[name] = i
+	}
+
+	for _, name := range []string{"caption", "description", "price"} {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("missing CSV column %q", name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := index[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []Row
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			// the reader cannot recover from broken quoting, the rest of the
+			// file is unreadable
+			return nil, fmt.Errorf("invalid CSV at line %d: %w", parseErr.StartLine, parseErr.Err)
+		}
+
+		if len(rows) == maxRows {
+			return nil, ErrTooManyRows
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := Row{
+			Line:        line,
+			Caption:     field(record, "caption"),
+			Description: field(record, "description"),
+			ImageURL:    field(record, "image_url"),
+		}
+
+		if price := field(record, "price"); price != "" {
+			row.Price, err = strconv.ParseFloat(strings.ReplaceAll(price, ",", "."), 64)
+			if err != nil {
+				row.Err = &model.ImportFieldError{Field: "price", Message: "price must be a number"}
+			}
+		}
+
+		rows = append(rows, row)
+	}
+}
+
+func parseNDJSON(r io.Reader, maxRows int) ([]Row, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var rows []Row
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		if len(rows) == maxRows {
+			return nil, ErrTooManyRows
+		}
+
+		var v jsonRow
+		row := Row{Line: line}
+
+		if err := json.Unmarshal(data, &v); err != nil {
+			row.Err = &model.ImportFieldError{Message: "invalid JSON"}
+
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				row.Err = &model.ImportFieldError{Field: typeErr.Field, Message: fmt.Sprintf("%s has invalid type", typeErr.Field)}
+			}
+		} else {
+			row.Caption, row.Description, row.ImageURL, row.Price = v.Caption, v.Description, v.ImageURL, v.Price
+		}
+
+		rows = append(rows, row)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid NDJSON: %w", err)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,174 @@
+package importer
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"vk-internship/internal/database/model"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		input   string
+		maxRows int
+		want    []Row
+		wantErr string
+		wantIs  error
+	}{
+		{
+			name:   "csv columns in any order",
+			format: model.ImportFormatCSV,
+			input:  "\ufeffPrice,caption,description,extra\n1500,Bike,Red bike,x\n\"99,5\", Lamp ,\"Desk lamp, white\",y\n",
+			want: []Row{
+				{Line: 2, Caption: "Bike", Description: "Red bike", Price: 1500},
+				{Line: 3, Caption: "Lamp", Description: "Desk lamp, white", Price: 99.5},
+			},
+		},
+		{
+			name:   "csv price that is not a number",
+			format: model.ImportFormatCSV,
+			input:  "caption,description,price,image_url\nBike,Red bike,cheap,https://example.com/a.png\n",
+			want: []Row{
+				{Line: 2, Caption: "Bike", Description: "Red bike", ImageURL: "https://example.com/a.png", Err: &model.ImportFieldError{Field: "price", Message: "price must be a number"}},
+			},
+		},
+		{
+			name:   "csv short row leaves fields empty",
+			format: model.ImportFormatCSV,
+			input:  "caption,description,price\nBike\n",
+			want:   []Row{{Line: 2, Caption: "Bike"}},
+		},
+		{
+			name:    "csv missing column",
+			format:  model.ImportFormatCSV,
+			input:   "caption,price\nBike,10\n",
+			wantErr: `missing CSV column "description"`,
+		},
+		{
+			name:    "csv duplicate column",
+			format:  model.ImportFormatCSV,
+			input:   "caption,description,price,Price\nBike,Red,1,2\n",
+			wantErr: `duplicate CSV column "price"`,
+		},
+		{
+			name:    "csv broken quoting",
+			format:  model.ImportFormatCSV,
+			input:   "caption,description,price\n\"Bike,Red,1\n",
+			wantErr: "invalid CSV at line 2",
+		},
+		{
+			name:   "csv without rows",
+			format: model.ImportFormatCSV,
+			input:  "",
+		},
+		{
+			name:   "ndjson rows and blank lines",
+			format: model.ImportFormatNDJSON,
+			input:  "{\"caption\":\"Bike\",\"description\":\"Red bike\",\"price\":1500}\n\n{\"caption\":\"Lamp\",\"description\":\"Desk lamp\",\"price\":99.5,\"unknown\":1}\n",
+			want: []Row{
+				{Line: 1, Caption: "Bike", Description: "Red bike", Price: 1500},
+				{Line: 3, Caption: "Lamp", Description: "Desk lamp", Price: 99.5},
+			},
+		},
+		{
+			name:   "ndjson row errors",
+			format: model.ImportFormatNDJSON,
+			input:  "{\"caption\":\"Bike\"\n{\"caption\":\"Lamp\",\"price\":\"cheap\"}\n",
+			want: []Row{
+				{Line: 1, Err: &model.ImportFieldError{Message: "invalid JSON"}},
+				{Line: 2, Err: &model.ImportFieldError{Field: "price", Message: "price has invalid type"}},
+			},
+		},
+		{
+			name:    "csv row limit",
+			format:  model.ImportFormatCSV,
+			input:   "caption,description,price\nA,a,1\nB,b,2\nC,c,3\n",
+			maxRows: 2,
+			wantIs:  ErrTooManyRows,
+		},
+		{
+			name:    "ndjson row limit",
+			format:  model.ImportFormatNDJSON,
+			input:   "{}\n{}\n{}\n",
+			maxRows: 2,
+			wantIs:  ErrTooManyRows,
+		},
+		{
+			name:    "unsupported format",
+			format:  "xml",
+			wantErr: `unsupported format "xml"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxRows := tt.maxRows
+			if maxRows == 0 {
+				maxRows = 100
+			}
+
+			got, err := Parse(tt.format, strings.NewReader(tt.input), maxRows)
+			switch {
+			case tt.wantIs != nil:
+				if !errors.Is(err, tt.wantIs) {
+					t.Fatalf("Parse() = %v, want %v", err, tt.wantIs)
+				}
+				return
+			case tt.wantErr != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse() = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			case err != nil:
+				t.Fatalf("Parse() = %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Parse() returned %d rows, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if !equalRows(got[i], tt.want[i]) {
+					t.Errorf("row %d = %+v (err %+v), want %+v (err %+v)", i, got[i], got[i].Err, tt.want[i], tt.want[i].Err)
+				}
+			}
+		})
+	}
+}
+
+func TestParseSizeLimit(t *testing.T) {
+	inputs := map[string]string{
+		model.ImportFormatCSV:    "caption,description,price\n" + strings.Repeat("Bike,Red bike,1500\n", 100),
+		model.ImportFormatNDJSON: strings.Repeat("{\"caption\":\"Bike\",\"description\":\"Red bike\",\"price\":1500}\n", 100),
+	}
+
+	for format, input := range inputs {
+		t.Run(format, func(t *testing.T) {
+			body := http.MaxBytesReader(httptest.NewRecorder(), io.NopCloser(strings.NewReader(input)), 256)
+
+			_, err := Parse(format, body, 1000)
+
+			var maxBytesErr *http.MaxBytesError
+			if !errors.As(err, &maxBytesErr) {
+				t.Errorf("Parse() = %v, want *http.MaxBytesError", err)
+			}
+		})
+	}
+}
+
+func equalRows(a, b Row) bool {
+	if (a.Err == nil) != (b.Err == nil) {
+		return false
+	}
+	if a.Err != nil && *a.Err != *b.Err {
+		return false
+	}
+	a.Err, b.Err = nil, nil
+
+	return a == b
+}
@@ -1,5 +1,7 @@
 package logger
 
+import "context"
+
 type Logger interface {
 	Debug(msg string)
 	Debugf(msg string, fields map[string]interface{})
@@ -11,4 +13,8 @@ type Logger interface {
 	Fatal(err error, msg string)
 	With(fields map[string]interface{}) Logger
 	Component(name string) Logger
+	WithContext(ctx context.Context) Logger
+	// SetLevel changes the minimum level of this logger and all loggers
+	// derived from it.
+	SetLevel(level string) error
 }
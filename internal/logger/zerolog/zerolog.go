@@ -1,10 +1,12 @@
 package zerologger
 
 import (
+	"context"
 	"io"
 	"os"
 
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
 
 	"vk-internship/internal/config"
 	"vk-internship/internal/logger"
@@ -15,6 +17,12 @@ type Logger struct {
 }
 
 func New(cfg *config.LoggerConfig) *Logger {
+	return NewWriter(cfg, os.Stdout)
+}
+
+// NewWriter creates a logger writing to out, e.g. stderr for CLI commands
+// whose stdout is the result.
+func NewWriter(cfg *config.LoggerConfig, out io.Writer) *Logger {
 	logLevel, err := zerolog.ParseLevel(cfg.Level)
 	if err != nil {
 		logLevel = zerolog.DebugLevel
@@ -25,11 +33,11 @@ func New(cfg *config.LoggerConfig) *Logger {
 	var output io.Writer
 	if cfg.Pretty {
 		output = zerolog.ConsoleWriter{
-			Out:        os.Stdout,
+			Out:        out,
 			TimeFormat: zerolog.TimeFormatUnix,
 		}
 	} else {
-		output = os.Stdout
+		output = out
 	}
 
 	base := zerolog.New(output).With().Timestamp().CallerWithSkipFrameCount(3).Logger()
@@ -37,6 +45,17 @@ func New(cfg *config.LoggerConfig) *Logger {
 	return &Logger{base}
 }
 
+// SetLevel uses the global level, which every derived logger shares.
+func (l *Logger) SetLevel(level string) error {
+	logLevel, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	zerolog.SetGlobalLevel(logLevel)
+	return nil
+}
+
 func (l *Logger) Debug(msg string) {
 	l.Logger.Debug().Msg(msg)
 }
@@ -76,3 +95,17 @@ func (l *Logger) With(fields map[string]interface{}) logger.Logger {
 func (l *Logger) Component(name string) logger.Logger {
 	return &Logger{l.Logger.With().Str("component", name).Logger()}
 }
+
+// WithContext adds the trace and span IDs of the active span, if any, so log
+// lines can be correlated with traces.
+func (l *Logger) WithContext(ctx context.Context) logger.Logger {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return l
+	}
+
+	return &Logger{l.Logger.With().
+		Str("trace_id", spanCtx.TraceID().String()).
+		Str("span_id", spanCtx.SpanID().String()).
+		Logger()}
+}
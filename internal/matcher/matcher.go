@@ -0,0 +1,114 @@
+package matcher
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+	"vk-internship/internal/logger"
+)
+
+const NotificationSavedSearchMatch = "saved_search.match"
+
+type Matcher struct {
+	db  database.Database
+	log logger.Logger
+}
+
+type matchPayload struct {
+	SavedSearchID   string  `json:"saved_search_id"`
+	SavedSearchName string  `json:"saved_search_name"`
+	AdID            string  `json:"ad_id"`
+	Caption         string  `json:"caption"`
+	Price           float64 `json:"price"`
+}
+
+func New(db database.Database, log logger.Logger) *Matcher {
+	return &Matcher{
+		db:  db,
+		log: log.Component("matcher"),
+	}
+}
+
+// HandleAdCreated notifies owners of saved searches the new ad matches.
+// Price bounds are checked by the database, the text part is checked here.
+func (m *Matcher) HandleAdCreated(ctx context.Context, ad *model.Advertisement) {
+	candidates, err := m.db.GetMatchingSavedSearches(ctx, ad)
+	if err != nil {
+		m.log.Error(err, "failed to get saved searches")
+		return
+	}
+
+	notified := make(map[string]struct{})
+	var notifications []*model.Notification
+
+	for _, search := range candidates {
+		if !Matches(search, ad) {
+			continue
+		}
+
+		if _, ok := notified[search.UserID]; ok {
+			continue
+		}
+		notified[search.UserID] = struct{}{}
+
+		payload, err := json.Marshal(matchPayload{
+			SavedSearchID:   search.ID,
+			SavedSearchName: search.Name,
+			AdID:            ad.ID,
+			Caption:         ad.Caption,
+			Price:           float64(ad.Price) / 100,
+		})
+		if err != nil {
+			m.log.Error(err, "failed to marshal notification payload")
+			continue
+		}
+
+		notifications = append(notifications, &model.Notification{
+			UserID:  search.UserID,
+			Type:    NotificationSavedSearchMatch,
+			Payload: payload,
+		})
+	}
+
+	if err := m.db.CreateNotifications(ctx, notifications); err != nil {
+		m.log.Error(err, "failed to create notifications")
+		return
+	}
+
+	m.log.Debugf("ad matched saved searches", map[string]interface{}{
+		"ad_id":         ad.ID,
+		"candidates":    len(candidates),
+		"notifications": len(notifications),
+	})
+}
+
+func Matches(search *model.SavedSearch, ad *model.Advertisement) bool {
+	if search.UserID == ad.AuthorID {
+		return false
+	}
+
+	if search.MinPrice != nil && ad.Price < *search.MinPrice {
+		return false
+	}
+
+	if search.MaxPrice != nil && ad.Price > *search.MaxPrice {
+		return false
+	}
+
+	query := strings.ToLower(strings.TrimSpace(search.Query))
+	if query == "" {
+		return true
+	}
+
+	text := strings.ToLower(ad.Caption + " " + ad.Description)
+	for _, word := range strings.Fields(query) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+
+	return true
+}
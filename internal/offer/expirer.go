@@ -0,0 +1,48 @@
+package offer
+
+import (
+	"context"
+	"time"
+
+	"vk-internship/internal/config"
+	"vk-internship/internal/database"
+	"vk-internship/internal/logger"
+)
+
+// Expirer periodically closes offers nobody answered in time, so that both
+// parties get notified. Until it does, the offers already read as expired.
+type Expirer struct {
+	db       database.Database
+	interval time.Duration
+	log      logger.Logger
+}
+
+func NewExpirer(cfg *config.OfferConfig, db database.Database, log logger.Logger) *Expirer {
+	return &Expirer{
+		db:       db,
+		interval: cfg.ExpireInterval,
+		log:      log.Component("offer"),
+	}
+}
+
+func (e *Expirer) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := e.db.ExpireOffers(ctx)
+			if err != nil {
+				e.log.Error(err, "failed to expire offers")
+				continue
+			}
+
+			if expired > 0 {
+				e.log.Debugf("expired offers", map[string]interface{}{"count": expired})
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"vk-internship/internal/config"
+	"vk-internship/internal/logger"
+)
+
+type Group string
+
+const (
+	GroupRead  Group = "read"
+	GroupWrite Group = "write"
+	GroupAuth  Group = "auth"
+)
+
+const (
+	StoreRedis  = "redis"
+	StoreMemory = "memory"
+)
+
+// Limit is a token bucket: Burst requests at once, refilled at PerMinute.
+type Limit struct {
+	PerMinute float64
+	Burst     int
+}
+
+func (l Limit) rate() float64 {
+	return l.PerMinute / 60
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is the time until the next token, zero if allowed.
+	RetryAfter time.Duration
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+}
+
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (bool, float64, error)
+}
+
+type Limiter struct {
+	enabled  atomic.Bool
+	limits   atomic.Pointer[map[Group]Limit]
+	store    Store
+	fallback Store
+	log      logger.Logger
+}
+
+// New creates a limiter on top of store. If the store fails (e.g. Redis is
+// down) requests are limited per replica by an in-memory store instead.
+func New(cfg *config.RateLimitConfig, store Store, log logger.Logger) *Limiter {
+	l := &Limiter{
+		store:    store,
+		fallback: NewMemoryStore(),
+		log:      log.Component("ratelimit"),
+	}
+	l.Configure(cfg)
+
+	return l
+}
+
+// Configure replaces the limits and can be called while serving requests.
+func (l *Limiter) Configure(cfg *config.RateLimitConfig) {
+	limits := map[Group]Limit{
+		GroupRead:  {PerMinute: cfg.ReadPerMinute, Burst: cfg.ReadBurst},
+		GroupWrite: {PerMinute: cfg.WritePerMinute, Burst: cfg.WriteBurst},
+		GroupAuth:  {PerMinute: cfg.AuthPerMinute, Burst: cfg.AuthBurst},
+	}
+
+	l.limits.Store(&limits)
+	l.enabled.Store(cfg.Enabled)
+}
+
+func (l *Limiter) Enabled() bool {
+	return l.enabled.Load()
+}
+
+func (l *Limiter) Take(ctx context.Context, group Group, identity string) (Result, error) {
+	limit, ok := (*l.limits.Load())[group]
+	if !ok {
+		return Result{}, fmt.Errorf("unknown rate limit group %q", group)
+	}
+
+	key := string(group) + ":" + identity
+
+	allowed, tokens, err := l.store.Take(ctx, key, limit)
+	if err != nil {
+		l.log.Debugf("rate limit store failed, using memory store", map[string]interface{}{"error": err.Error()})
+
+		allowed, tokens, err = l.fallback.Take(ctx, key, limit)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	rate := limit.rate()
+	result := Result{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     secondsToDuration((float64(limit.Burst) - tokens) / rate),
+	}
+
+	if !allowed {
+		result.RetryAfter = secondsToDuration((1 - tokens) / rate)
+	}
+
+	return result, nil
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	if seconds <= 0 || math.IsInf(seconds, 0) || math.IsNaN(seconds) {
+		return 0
+	}
+
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"vk-internship/internal/cache"
+)
+
+// CacheStore keeps buckets in the shared cache so limits hold across replicas.
+type CacheStore struct {
+	cache cache.Cache
+}
+
+func NewCacheStore(cache cache.Cache) *CacheStore {
+	return &CacheStore{cache: cache}
+}
+
+func (s *CacheStore) Take(ctx context.Context, key string, limit Limit) (bool, float64, error) {
+	return s.cache.TakeToken(ctx, key, limit.rate(), limit.Burst)
+}
+
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// rate and burst are those of the bucket's own limit, groups refill at
+	// different speeds
+	rate  float64
+	burst float64
+}
+
+// MemoryStore keeps buckets in process memory. Limits are per replica.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (bool, float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	rate := limit.rate()
+	burst := float64(limit.Burst)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		s.buckets[key] = b
+	}
+	b.rate, b.burst = rate, burst
+
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	allowed := false
+	if b.tokens >= 1 {
+		b.tokens--
+		allowed = true
+	}
+
+	if now.Sub(s.lastSweep) > sweepInterval {
+		s.sweep(now)
+	}
+
+	return allowed, b.tokens, nil
+}
+
+// sweep drops buckets idle long enough to have refilled completely, they
+// are indistinguishable from new ones.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.burst {
+			delete(s.buckets, key)
+		}
+	}
+
+	s.lastSweep = now
+}
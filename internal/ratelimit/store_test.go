@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreRefill(t *testing.T) {
+	// one token per second, two at once
+	limit := Limit{PerMinute: 60, Burst: 2}
+
+	steps := []struct {
+		name        string
+		advance     time.Duration
+		wantAllowed bool
+		wantTokens  float64
+	}{
+		{name: "new bucket starts full", wantAllowed: true, wantTokens: 1},
+		{name: "burst is used up", wantAllowed: true, wantTokens: 0},
+		{name: "empty bucket denies", wantAllowed: false, wantTokens: 0},
+		{name: "partial refill still denies", advance: 500 * time.Millisecond, wantAllowed: false, wantTokens: 0.5},
+		{name: "refilled token is taken", advance: 500 * time.Millisecond, wantAllowed: true, wantTokens: 0},
+		{name: "refill is capped at burst", advance: time.Minute, wantAllowed: true, wantTokens: 1},
+	}
+
+	now := time.Unix(1700000000, 0)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+
+		allowed, tokens, err := s.Take(context.Background(), "read:client", limit)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+		if allowed != step.wantAllowed {
+			t.Errorf("%s: allowed = %v, want %v", step.name, allowed, step.wantAllowed)
+		}
+		if math.Abs(tokens-step.wantTokens) > 1e-9 {
+			t.Errorf("%s: tokens = %v, want %v", step.name, tokens, step.wantTokens)
+		}
+	}
+}
+
+func TestMemoryStoreKeysAreIndependent(t *testing.T) {
+	limit := Limit{PerMinute: 60, Burst: 1}
+	s := NewMemoryStore()
+
+	if allowed, _, _ := s.Take(context.Background(), "read:a", limit); !allowed {
+		t.Fatal("first request of a was denied")
+	}
+	if allowed, _, _ := s.Take(context.Background(), "read:a", limit); allowed {
+		t.Error("second request of a was allowed")
+	}
+	if allowed, _, _ := s.Take(context.Background(), "read:b", limit); !allowed {
+		t.Error("b was limited by the bucket of a")
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	// the auth bucket needs five minutes to refill, the read bucket seconds
+	auth := Limit{PerMinute: 1, Burst: 5}
+	read := Limit{PerMinute: 300, Burst: 60}
+
+	tests := []struct {
+		name     string
+		idle     time.Duration
+		wantKept bool
+	}{
+		{name: "partly drained slow bucket is kept", idle: 2 * time.Minute, wantKept: true},
+		{name: "refilled slow bucket is dropped", idle: 6 * time.Minute, wantKept: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Unix(1700000000, 0)
+			s := NewMemoryStore()
+			s.now = func() time.Time { return now }
+			s.lastSweep = now
+
+			for range auth.Burst {
+				s.Take(context.Background(), "auth:client", auth)
+			}
+
+			// a request of another group runs the sweep
+			now = now.Add(tt.idle)
+			s.Take(context.Background(), "read:client", read)
+
+			if _, kept := s.buckets["auth:client"]; kept != tt.wantKept {
+				t.Errorf("auth bucket kept = %v, want %v", kept, tt.wantKept)
+			}
+		})
+	}
+}
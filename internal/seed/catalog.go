@@ -0,0 +1,80 @@
+package seed
+
+// item is a kind of goods with a price range in rubles.
+type item struct {
+	ru, en   string
+	brands   []string
+	min, max int
+}
+
+var items = []item{
+	{ru: "Пылесос", en: "vacuum cleaner", brands: []string{"Dyson", "Samsung", "Philips", "Xiaomi"}, min: 3000, max: 45000},
+	{ru: "Велосипед", en: "bicycle", brands: []string{"Stels", "Merida", "Trek", "Forward"}, min: 5000, max: 120000},
+	{ru: "Смартфон", en: "smartphone", brands: []string{"iPhone 13", "Samsung Galaxy S22", "Xiaomi Redmi Note 12", "Google Pixel 7"}, min: 6000, max: 110000},
+	{ru: "Ноутбук", en: "laptop", brands: []string{"Lenovo ThinkPad", "MacBook Air", "ASUS ZenBook", "HP Pavilion"}, min: 15000, max: 180000},
+	{ru: "Диван", en: "sofa", brands: []string{"IKEA", "Hoff", "Askona"}, min: 5000, max: 90000},
+	{ru: "Холодильник", en: "fridge", brands: []string{"Bosch", "Atlant", "LG", "Indesit"}, min: 8000, max: 95000},
+	{ru: "Кроссовки", en: "sneakers", brands: []string{"Nike", "Adidas", "New Balance", "Asics"}, min: 1500, max: 25000},
+	{ru: "Куртка", en: "jacket", brands: []string{"The North Face", "Columbia", "Zara", "Uniqlo"}, min: 2000, max: 35000},
+	{ru: "Коляска", en: "baby stroller", brands: []string{"Cybex", "Inglesina", "Chicco"}, min: 3000, max: 60000},
+	{ru: "Гитара", en: "guitar", brands: []string{"Yamaha", "Fender", "Cort", "Ibanez"}, min: 4000, max: 80000},
+	{ru: "Кофемашина", en: "coffee machine", brands: []string{"DeLonghi", "Philips", "Krups", "Saeco"}, min: 5000, max: 70000},
+	{ru: "Монитор", en: "monitor", brands: []string{"Dell", "LG", "Samsung", "AOC"}, min: 5000, max: 60000},
+	{ru: "Палатка", en: "tent", brands: []string{"Tramp", "Outventure", "Quechua"}, min: 2500, max: 30000},
+	{ru: "Наушники", en: "headphones", brands: []string{"Sony", "JBL", "AirPods", "Sennheiser"}, min: 1000, max: 40000},
+	{ru: "Приставка", en: "game console", brands: []string{"PlayStation 5", "Xbox Series S", "Nintendo Switch"}, min: 12000, max: 65000},
+	{ru: "Стиральная машина", en: "washing machine", brands: []string{"Bosch", "Haier", "Samsung", "Candy"}, min: 7000, max: 70000},
+}
+
+var conditionsRU = []string{
+	"в отличном состоянии",
+	"в хорошем состоянии",
+	"как новый",
+	"б/у",
+	"с документами",
+	"срочно",
+	"торг",
+}
+
+var conditionsEN = []string{
+	"excellent condition",
+	"good condition",
+	"like new",
+	"used",
+	"with receipt",
+	"urgent sale",
+	"negotiable",
+}
+
+var descriptionsRU = []string{
+	"Продаю за ненадобностью.",
+	"Пользовались аккуратно, без сколов и царапин.",
+	"Все работает, проверка при покупке.",
+	"Полный комплект, есть коробка.",
+	"Торг уместен.",
+	"Возможна доставка по городу.",
+	"Отвечу на вопросы в сообщениях.",
+	"Есть небольшие следы использования, на работу не влияют.",
+	"Обмен не интересует.",
+}
+
+var descriptionsEN = []string{
+	"Selling because I no longer need it.",
+	"Used with care, no scratches or dents.",
+	"Everything works, you can check it before buying.",
+	"Complete set, original box included.",
+	"Price is negotiable.",
+	"Delivery within the city is possible.",
+	"Message me with any questions.",
+	"Minor signs of use that do not affect anything.",
+	"No trades, please.",
+}
+
+var stationsRU = []string{"Маяковская", "Чистые пруды", "Сокол", "Таганская", "Автово", "Площадь Восстания", "Динамо"}
+
+var stationsEN = []string{"Mayakovskaya", "Chistye Prudy", "Sokol", "Taganskaya", "Avtovo", "Ploshchad Vosstaniya", "Dinamo"}
+
+// Names are transliterated, usernames must be alphanumeric.
+var firstNames = []string{"ivan", "anna", "dmitry", "olga", "sergey", "maria", "alexey", "elena", "nikita", "daria", "john", "emma", "oliver", "sophie", "max", "kate"}
+
+var lastNames = []string{"ivanov", "smirnova", "kuznetsov", "popova", "sokolov", "volkova", "morozov", "lebedeva", "smith", "brown", "taylor", "wilson"}
@@ -0,0 +1,110 @@
+package seed
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"vk-internship/internal/database/model"
+)
+
+// Generator produces synthetic users and ads. The same seed, period and until
+// give the same data as long as the calls are made in the same order.
+type Generator struct {
+	rnd    *rand.Rand
+	until  time.Time
+	period time.Duration
+}
+
+// New creates a generator whose timestamps fall within period before until.
+func New(seed uint64, until time.Time, period time.Duration) *Generator {
+	return &Generator{
+		rnd:    rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)),
+		until:  until.UTC(),
+		period: period,
+	}
+}
+
+// User generates the i-th user. The index is part of the username, so users
+// of one run never collide.
+func (g *Generator) User(i int, passwordHash string) *model.User {
+	first := pick(g.rnd, firstNames)
+	last := pick(g.rnd, lastNames)
+
+	return &model.User{
+		ID:        g.uuid(),
+		Username:  fmt.Sprintf("%s%s%d", first, last, i+1),
+		Password:  passwordHash,
+		CreatedAt: g.before(g.until, g.period),
+	}
+}
+
+// Ad generates an ad of a random author, created after the author.
+func (g *Generator) Ad(authors []*model.User) *model.Advertisement {
+	author := authors[g.rnd.IntN(len(authors))]
+	it := pick(g.rnd, items)
+
+	var caption, description string
+	if g.rnd.IntN(2) == 0 {
+		caption = fmt.Sprintf("%s %s, %s", it.ru, pick(g.rnd, it.brands), pick(g.rnd, conditionsRU))
+		description = g.description(descriptionsRU, stationsRU, "Самовывоз от метро %s.")
+	} else {
+		caption = fmt.Sprintf("%s %s, %s", pick(g.rnd, it.brands), it.en, pick(g.rnd, conditionsEN))
+		description = g.description(descriptionsEN, stationsEN, "Pickup near %s station.")
+	}
+
+	// Prices are rounded to tens of rubles like real ones, stored in kopecks.
+	price := (it.min + g.rnd.IntN(it.max-it.min+1)) / 10 * 10 * 100
+
+	var imageURL string
+	if g.rnd.IntN(10) < 7 {
+		imageURL = fmt.Sprintf("https://picsum.photos/seed/%016x/640/480", g.rnd.Uint64())
+	}
+
+	createdAt := author.CreatedAt.Add(time.Duration(g.rnd.Int64N(int64(g.until.Sub(author.CreatedAt)) + 1))).Truncate(time.Second)
+
+	return &model.Advertisement{
+		ID:             g.uuid(),
+		AuthorID:       author.ID,
+		AuthorUsername: author.Username,
+		Caption:        caption,
+		Description:    description,
+		ImageURL:       imageURL,
+		Price:          price,
+		CreatedAt:      createdAt,
+		UpdatedAt:      createdAt,
+	}
+}
+
+func (g *Generator) description(sentences, stations []string, pickup string) string {
+	n := 2 + g.rnd.IntN(3)
+	parts := make([]string, 0, n+1)
+	for _, i := range g.rnd.Perm(len(sentences))[:n] {
+		parts = append(parts, sentences[i])
+	}
+
+	if g.rnd.IntN(2) == 0 {
+		parts = append(parts, fmt.Sprintf(pickup, pick(g.rnd, stations)))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func (g *Generator) before(t time.Time, period time.Duration) time.Time {
+	return t.Add(-time.Duration(g.rnd.Int64N(int64(period) + 1))).Truncate(time.Second)
+}
+
+// uuid returns a random version 4 UUID taken from the generator, so ids are
+// reproducible too.
+func (g *Generator) uuid() string {
+	hi, lo := g.rnd.Uint64(), g.rnd.Uint64()
+	hi = hi&^0xf000 | 0x4000
+	lo = lo&^(0xc<<60) | 0x8<<60
+
+	return fmt.Sprintf("%08x-%04x-%04x-%04x-%012x", hi>>32, hi>>16&0xffff, hi&0xffff, lo>>48, lo&0xffffffffffff)
+}
+
+func pick[T any](rnd *rand.Rand, values []T) T {
+	return values[rnd.IntN(len(values))]
+}
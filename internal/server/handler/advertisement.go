@@ -9,10 +9,15 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"vk-internship/internal/broker"
 	"vk-internship/internal/cache"
+	"vk-internship/internal/config"
 	"vk-internship/internal/database"
 	"vk-internship/internal/database/model"
 	"vk-internship/internal/logger"
+	"vk-internship/internal/matcher"
+	"vk-internship/internal/metrics"
+	"vk-internship/internal/server/problem"
 	"vk-internship/internal/utils"
 )
 
@@ -23,6 +28,7 @@ type CreateAdRequest struct {
 	Description string  `json:"description" validate:"required,max=1024"`
 	ImageURL    string  `json:"image_url" validate:"omitempty,url"`
 	Price       float64 `json:"price" validate:"required,min=0"`
+	Status      string  `json:"status" validate:"omitempty,oneof=active draft"`
 }
 
 // CreateAdResponse представляет ответ после создания объявления
@@ -34,37 +40,43 @@ type CreateAdResponse struct {
 	Description string    `json:"description"`
 	ImageURL    string    `json:"image_url,omitempty"`
 	Price       float64   `json:"price"`
+	Status      string    `json:"status"`
 	CreatedAt   time.Time `json:"created_at"`
 }
 
 // CreateAdHandler создает новое объявление
 // @Security BearerAuth
 // @Summary Создать объявление
-// @Description Создает новое объявление от имени авторизованного пользователя
+// @Description Создает новое объявление от имени авторизованного пользователя. Объявление со статусом draft видно только автору, в ленту оно попадет после публикации (status active)
 // @Tags ads
 // @Accept json
 // @Produce json
 // @Param request body CreateAdRequest true "Данные объявления"
+// @Param Idempotency-Key header string false "Ключ идемпотентности"
 // @Success 201 {object} CreateAdResponse
-// @Failure 400 {object} map[string]string "Неверный формат запроса или ошибки валидации"
-// @Failure 401 {string} string "Не авторизован"
-// @Failure 500 {string} string "Внутренняя ошибка сервера"
-// @Router /ads [post]
-func CreateAdHandler(log logger.Logger, db database.Database, cache cache.Cache) http.HandlerFunc {
+// @Failure 400 {object} problem.Problem "Неверный формат запроса или ошибки валидации"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 409 {object} problem.Problem "Запрос с этим ключом еще выполняется"
+// @Failure 422 {object} problem.Problem "Ключ использован для другого запроса"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/ads [post]
+func CreateAdHandler(log logger.Logger, db database.Database, cache cache.Cache, events *broker.Broker, searches *matcher.Matcher, mapper ResponseMapper) http.HandlerFunc {
 	validate := utils.NewValidator()
 
 	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
 		userID, ok := r.Context().Value("userID").(string)
 		if !ok || userID == "" {
 			log.Warn("userID not found in context")
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
 			return
 		}
 
 		var req CreateAdRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			log.Warnf("invalid request body", map[string]interface{}{"error": err.Error()})
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
 			return
 		}
 
@@ -72,9 +84,7 @@ func CreateAdHandler(log logger.Logger, db database.Database, cache cache.Cache)
 			validationErrors := validate.FormatValidationErrors(err)
 			log.Warnf("validation failed", map[string]interface{}{"errors": validationErrors})
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(validationErrors)
+			problem.Validation(w, r, validationErrors.Errors)
 			return
 		}
 
@@ -84,31 +94,42 @@ func CreateAdHandler(log logger.Logger, db database.Database, cache cache.Cache)
 			Description: req.Description,
 			ImageURL:    req.ImageURL,
 			Price:       int(req.Price * 100),
+			Status:      req.Status,
 		}
 
-		createdAd, err := db.CreateAd(ad)
+		createdAd, err := db.CreateAd(r.Context(), ad)
 		if err != nil {
 			log.Error(err, "failed to create ad")
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
 			return
 		}
 
-		go func() {
-			if err := cache.UpdateFeed(context.TODO(), *createdAd); err != nil {
-				log.Warn("failed to update feed cache")
-			}
-		}()
-
-		response := CreateAdResponse{
-			ID:          createdAd.ID,
-			AuthorID:    createdAd.AuthorID,
-			Caption:     createdAd.Caption,
-			Description: createdAd.Description,
-			ImageURL:    createdAd.ImageURL,
-			Price:       float64(createdAd.Price) / 100,
-			CreatedAt:   createdAd.CreatedAt,
+		metrics.AdsCreated.Inc()
+
+		if username, ok := r.Context().Value("username").(string); ok {
+			createdAd.AuthorUsername = username
 		}
 
+		if createdAd.Status == model.AdStatusActive {
+			detach(r, func(ctx context.Context) {
+				if err := cache.UpdateFeed(ctx, *createdAd); err != nil {
+					log.Warn("failed to update feed cache")
+				}
+			})
+
+			detach(r, func(ctx context.Context) {
+				if err := events.Publish(ctx, broker.AdCreated, createdAd); err != nil {
+					log.Warnf("failed to publish ad event", map[string]interface{}{"error": err.Error()})
+				}
+			})
+
+			detach(r, func(ctx context.Context) {
+				searches.HandleAdCreated(ctx, createdAd)
+			})
+		}
+
+		response := mapper.CreatedAd(createdAd)
+
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusCreated)
 		if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -122,6 +143,7 @@ func CreateAdHandler(log logger.Logger, db database.Database, cache cache.Cache)
 			"description":      createdAd.Description,
 			"image_url":        createdAd.ImageURL,
 			"price":            createdAd.Price,
+			"status":           createdAd.Status,
 			"created_at":       createdAd.CreatedAt,
 		})
 	}
@@ -130,35 +152,39 @@ func CreateAdHandler(log logger.Logger, db database.Database, cache cache.Cache)
 // GetAdResponse представляет информацию об объявлении
 // @Description Полная информация об объявлении
 type GetAdResponse struct {
-	ID             string    `json:"id"`
-	AuthorUsername string    `json:"author_username"`
-	Caption        string    `json:"caption"`
-	Description    string    `json:"description"`
-	ImageURL       string    `json:"image_url,omitempty"`
-	Price          float64   `json:"price"`
-	CreatedAt      time.Time `json:"created_at"`
-	IsOwner        *bool     `json:"is_owner,omitempty"`
+	ID             string         `json:"id"`
+	AuthorUsername string         `json:"author_username"`
+	Author         AuthorResponse `json:"author"`
+	Caption        string         `json:"caption"`
+	Description    string         `json:"description"`
+	ImageURL       string         `json:"image_url,omitempty"`
+	Price          float64        `json:"price"`
+	Status         string         `json:"status,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	IsOwner        *bool          `json:"is_owner,omitempty"`
 }
 
 // GetAdHandler возвращает информацию об объявлении
 // @Summary Получить объявление
-// @Description Возвращает полную информацию об объявлении по ID
+// @Description Возвращает полную информацию об объявлении по ID. Черновики и снятые с публикации объявления доступны только автору, для него в ответе есть status
 // @Tags ads
 // @Accept json
 // @Produce json
 // @Param id path string true "ID объявления"
 // @Security ApiKeyAuth
 // @Success 200 {object} GetAdResponse
-// @Failure 400 {string} string "Неверный ID объявления"
-// @Failure 404 {string} string "Объявление не найдено"
-// @Failure 500 {string} string "Внутренняя ошибка сервера"
-// @Router /ads/{id} [get]
-func GetAdHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+// @Failure 400 {object} problem.Problem "Неверный ID объявления"
+// @Failure 404 {object} problem.Problem "Объявление не найдено"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/ads/{id} [get]
+func GetAdHandler(cfg *config.StatsConfig, log logger.Logger, db database.Database, cache cache.Cache, mapper ResponseMapper) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
 		adID := chi.URLParam(r, "id")
 		if adID == "" {
 			log.Warn("id not provided")
-			http.Error(w, "Ad ID is required", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "Ad ID is required")
 			return
 		}
 
@@ -166,11 +192,11 @@ func GetAdHandler(log logger.Logger, db database.Database) http.HandlerFunc {
 		if err != nil {
 			if errors.Is(err, database.ErrAdNotFound) {
 				log.Warnf("ad not found", map[string]interface{}{"ad_id": adID})
-				http.Error(w, "Ad not found", http.StatusNotFound)
+				problem.Write(w, r, http.StatusNotFound, "Ad not found")
 				return
 			}
 			log.Error(err, "failed to get ad")
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
 			return
 		}
 
@@ -181,19 +207,21 @@ func GetAdHandler(log logger.Logger, db database.Database) http.HandlerFunc {
 			isAuthenticated = true
 		}
 
-		response := GetAdResponse{
-			ID:             ad.ID,
-			AuthorUsername: ad.AuthorUsername,
-			Caption:        ad.Caption,
-			Description:    ad.Description,
-			ImageURL:       ad.ImageURL,
-			Price:          float64(ad.Price) / 100,
-			CreatedAt:      ad.CreatedAt,
+		if ad.Status != model.AdStatusActive && userID != ad.AuthorID {
+			log.Warnf("ad not found", map[string]interface{}{"ad_id": adID})
+			problem.Write(w, r, http.StatusNotFound, "Ad not found")
+			return
 		}
 
-		if isAuthenticated {
-			isOwner := userID == ad.AuthorID
-			response.IsOwner = &isOwner
+		response := mapper.Ad(ad, userID)
+
+		if !isAuthenticated || userID != ad.AuthorID {
+			viewer := viewerID(r, userID)
+			detach(r, func(ctx context.Context) {
+				if _, err := cache.RegisterView(ctx, ad.ID, viewer, cfg.ViewWindow); err != nil {
+					log.Warnf("failed to register view", map[string]interface{}{"ad_id": ad.ID, "error": err.Error()})
+				}
+			})
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -213,37 +241,46 @@ func GetAdHandler(log logger.Logger, db database.Database) http.HandlerFunc {
 // @Param id path string true "ID объявления"
 // @Security BearerAuth
 // @Success 204 "Объявление успешно удалено"
-// @Failure 400 {string} string "Неверный ID объявления"
-// @Failure 401 {string} string "Не авторизован"
-// @Failure 403 {string} string "Нет прав на удаление"
-// @Failure 404 {string} string "Объявление не найдено"
-// @Failure 500 {string} string "Внутренняя ошибка сервера"
-// @Router /ads/{id} [delete]
-func DeleteAdHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+// @Failure 400 {object} problem.Problem "Неверный ID объявления"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 403 {object} problem.Problem "Нет прав на удаление"
+// @Failure 404 {object} problem.Problem "Объявление не найдено"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/ads/{id} [delete]
+func DeleteAdHandler(log logger.Logger, db database.Database, events *broker.Broker) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
 		userID, ok := r.Context().Value("userID").(string)
 		if !ok || userID == "" {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
 			return
 		}
 
 		adID := chi.URLParam(r, "id")
 		if adID == "" {
-			http.Error(w, "Ad ID is required", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "Ad ID is required")
 			return
 		}
 
 		err := db.DeleteAd(r.Context(), adID, userID)
 		if err != nil {
 			if errors.Is(err, database.ErrAdNotFoundOrNotOwnedByUser) {
-				http.Error(w, "Ad not found or not owned by user", http.StatusNotFound)
+				problem.Write(w, r, http.StatusNotFound, "Ad not found or not owned by user")
 				return
 			}
 			log.Error(err, "failed to delete ad")
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
 			return
 		}
 
+		detach(r, func(ctx context.Context) {
+			deletedAd := &model.Advertisement{ID: adID, AuthorID: userID}
+			if err := events.Publish(ctx, broker.AdDeleted, deletedAd); err != nil {
+				log.Warnf("failed to publish ad event", map[string]interface{}{"error": err.Error()})
+			}
+		})
+
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
@@ -255,6 +292,7 @@ type UpdateAdRequest struct {
 	Description string  `json:"description" validate:"omitempty,max=1024"`
 	ImageURL    string  `json:"image_url" validate:"omitempty,url"`
 	Price       float64 `json:"price" validate:"omitempty,min=0"`
+	Status      string  `json:"status" validate:"omitempty,oneof=active draft inactive sold"`
 }
 
 // UpdateAdResponse представляет ответ после обновления объявления
@@ -265,6 +303,7 @@ type UpdateAdResponse struct {
 	Description string    `json:"description"`
 	ImageURL    string    `json:"image_url,omitempty"`
 	Price       float64   `json:"price"`
+	Status      string    `json:"status"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -272,7 +311,7 @@ type UpdateAdResponse struct {
 // UpdateAdHandler обновляет объявление
 // @Security ApiKeyAuth
 // @Summary Обновить объявление
-// @Description Обновляет информацию об объявлении (только для автора объявления)
+// @Description Обновляет информацию об объявлении (только для автора объявления). Через status объявление можно опубликовать (active), вернуть в черновики (draft), снять с публикации (inactive) или отметить проданным (sold). При продаже и удалении объявления открытые предложения цены отклоняются
 // @Tags ads
 // @Accept json
 // @Produce json
@@ -280,32 +319,34 @@ type UpdateAdResponse struct {
 // @Param request body UpdateAdRequest true "Данные для обновления"
 // @Security BearerAuth
 // @Success 200 {object} UpdateAdResponse
-// @Failure 400 {object} map[string]string "Неверный формат запроса или ошибки валидации"
-// @Failure 401 {string} string "Не авторизован"
-// @Failure 403 {string} string "Нет прав на обновление"
-// @Failure 404 {string} string "Объявление не найдено"
-// @Failure 500 {string} string "Внутренняя ошибка сервера"
-// @Router /ads/{id} [put]
-func UpdateAdHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+// @Failure 400 {object} problem.Problem "Неверный формат запроса или ошибки валидации"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 403 {object} problem.Problem "Нет прав на обновление или объявление скрыто модерацией"
+// @Failure 404 {object} problem.Problem "Объявление не найдено"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/ads/{id} [put]
+func UpdateAdHandler(log logger.Logger, db database.Database, events *broker.Broker, searches *matcher.Matcher, mapper ResponseMapper) http.HandlerFunc {
 	validate := utils.NewValidator()
 
 	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
 		userID, ok := r.Context().Value("userID").(string)
 		if !ok || userID == "" {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
 			return
 		}
 
 		adID := chi.URLParam(r, "id")
 		if adID == "" {
-			http.Error(w, "Ad ID is required", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "Ad ID is required")
 			return
 		}
 
 		var req UpdateAdRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			log.Warnf("invalid request body", map[string]interface{}{"error": err.Error()})
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
 			return
 		}
 
@@ -313,24 +354,23 @@ func UpdateAdHandler(log logger.Logger, db database.Database) http.HandlerFunc {
 			validationErrors := validate.FormatValidationErrors(err)
 			log.Warnf("validation failed", map[string]interface{}{"errors": validationErrors})
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(validationErrors)
+			problem.Validation(w, r, validationErrors.Errors)
+			return
 		}
 
 		currentAd, err := db.GetAd(r.Context(), adID)
 		if err != nil {
 			if errors.Is(err, database.ErrAdNotFound) {
-				http.Error(w, "Ad not found", http.StatusNotFound)
+				problem.Write(w, r, http.StatusNotFound, "Ad not found")
 				return
 			}
 			log.Error(err, "failed to get ad")
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
 			return
 		}
 
 		if currentAd.AuthorID != userID {
-			http.Error(w, "Forbidden", http.StatusForbidden)
+			problem.Write(w, r, http.StatusForbidden, "Forbidden")
 			return
 		}
 
@@ -363,23 +403,38 @@ func UpdateAdHandler(log logger.Logger, db database.Database) http.HandlerFunc {
 			update.Price = currentAd.Price
 		}
 
+		update.Status = req.Status
+
 		updatedAd, err := db.UpdateAd(r.Context(), &update)
 		if err != nil {
+			if errors.Is(err, database.ErrAdHidden) {
+				problem.Write(w, r, http.StatusForbidden, "Ad is hidden by moderation")
+				return
+			}
+
 			log.Error(err, "failed to update ad")
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
 			return
 		}
 
-		response := UpdateAdResponse{
-			ID:          updatedAd.ID,
-			Caption:     updatedAd.Caption,
-			Description: updatedAd.Description,
-			ImageURL:    updatedAd.ImageURL,
-			Price:       float64(updatedAd.Price) / 100,
-			CreatedAt:   updatedAd.CreatedAt,
-			UpdatedAt:   updatedAd.UpdatedAt,
+		updatedAd.AuthorUsername = currentAd.AuthorUsername
+
+		if event := model.AdStatusEvent(currentAd.Status, updatedAd.Status); event != "" {
+			detach(r, func(ctx context.Context) {
+				if err := events.Publish(ctx, broker.EventType(event), updatedAd); err != nil {
+					log.Warnf("failed to publish ad event", map[string]interface{}{"error": err.Error()})
+				}
+			})
+
+			if event == model.EventAdCreated {
+				detach(r, func(ctx context.Context) {
+					searches.HandleAdCreated(ctx, updatedAd)
+				})
+			}
 		}
 
+		response := mapper.UpdatedAd(updatedAd)
+
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -387,3 +442,18 @@ func UpdateAdHandler(log logger.Logger, db database.Database) http.HandlerFunc {
 		}
 	}
 }
+
+// backgroundTimeout bounds the work a handler leaves running after it has
+// responded.
+const backgroundTimeout = 10 * time.Second
+
+// detach runs fn in the background with a context that keeps the request's
+// trace and log fields but is not canceled when the response is sent.
+func detach(r *http.Request, fn func(ctx context.Context)) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), backgroundTimeout)
+
+	go func() {
+		defer cancel()
+		fn(ctx)
+	}()
+}
@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+	"vk-internship/internal/logger"
+	"vk-internship/internal/server/problem"
+)
+
+// exportFlushRows is how many rows are written before the response is flushed
+// to the client.
+const exportFlushRows = 100
+
+var exportDelimiters = map[string]rune{
+	"comma":     ',',
+	"semicolon": ';',
+	"tab":       '\t',
+}
+
+var exportColumns = []string{"id", "author_username", "caption", "description", "image_url", "price", "status", "created_at", "updated_at"}
+
+// ExportAdsHandler выгружает объявления в файл
+// @Summary Выгрузить объявления
+// @Description Выгружает все объявления, подходящие под те же фильтры и сортировку, что и список объявлений, без пагинации. CSV содержит колонки id, author_username, caption, description, image_url, price, status, created_at, updated_at, цена указывается в рублях. NDJSON содержит по объекту объявления на строку. Для Excel передайте bom=true и delimiter=semicolon
+// @Tags ads
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "Формат файла" default(csv) Enums(csv, ndjson)
+// @Param bom query bool false "Добавить UTF-8 BOM в начало CSV"
+// @Param delimiter query string false "Разделитель CSV" default(comma) Enums(comma, semicolon, tab)
+// @Param sort_by query string false "Поле для сортировки (created_at, price)" default(created_at) Enums(created_at, price)
+// @Param order query string false "Порядок сортировки (ASC, DESC)" default(DESC) Enums(ASC, DESC)
+// @Param min_price query number false "Минимальная цена"
+// @Param max_price query number false "Максимальная цена"
+// @Security ApiKeyAuth
+// @Success 200 {file} file "Файл с объявлениями"
+// @Failure 400 {object} problem.Problem "Неверные параметры запроса"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/ads/export [get]
+func ExportAdsHandler(log logger.Logger, db database.Database, mapper ResponseMapper) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, _ := r.Context().Value("userID").(string)
+
+		exportAds(w, r, log, db, mapper, userID, false)
+	}
+}
+
+// ExportMyAdsHandler выгружает объявления текущего пользователя в файл
+// @Security BearerAuth
+// @Summary Выгрузить свои объявления
+// @Description Выгружает все объявления текущего пользователя, включая черновики и снятые с публикации. Параметры и формат файла такие же, как у выгрузки всех объявлений
+// @Tags ads
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param status query []string false "Статусы объявлений, по умолчанию все" collectionFormat(multi) Enums(active, draft, inactive, sold, hidden)
+// @Param format query string false "Формат файла" default(csv) Enums(csv, ndjson)
+// @Param bom query bool false "Добавить UTF-8 BOM в начало CSV"
+// @Param delimiter query string false "Разделитель CSV" default(comma) Enums(comma, semicolon, tab)
+// @Param sort_by query string false "Поле для сортировки (created_at, price)" default(created_at) Enums(created_at, price)
+// @Param order query string false "Порядок сортировки (ASC, DESC)" default(DESC) Enums(ASC, DESC)
+// @Param min_price query number false "Минимальная цена"
+// @Param max_price query number false "Максимальная цена"
+// @Success 200 {file} file "Файл с объявлениями"
+// @Failure 400 {object} problem.Problem "Неверные параметры запроса"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/me/ads/export [get]
+func ExportMyAdsHandler(log logger.Logger, db database.Database, mapper ResponseMapper) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			log.Warn("userID not found in context")
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		exportAds(w, r, log, db, mapper, userID, true)
+	}
+}
+
+// exportAds streams the ads as they are read from the database. The status is
+// sent with the first row, so an error before it is still answered with a
+// problem; after it the response can only be aborted.
+func exportAds(w http.ResponseWriter, r *http.Request, log logger.Logger, db database.Database, mapper ResponseMapper, userID string, own bool) {
+	query := r.URL.Query()
+
+	format := query.Get("format")
+	if format == "" {
+		format = model.ImportFormatCSV
+	}
+	if format != model.ImportFormatCSV && format != model.ImportFormatNDJSON {
+		problem.Write(w, r, http.StatusBadRequest, "format must be csv or ndjson")
+		return
+	}
+
+	delimiter := ','
+	if name := query.Get("delimiter"); name != "" {
+		d, ok := exportDelimiters[name]
+		if !ok {
+			problem.Write(w, r, http.StatusBadRequest, "delimiter must be comma, semicolon or tab")
+			return
+		}
+		delimiter = d
+	}
+
+	var bom bool
+	if value := query.Get("bom"); value != "" {
+		var err error
+		bom, err = strconv.ParseBool(value)
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "bom must be true or false")
+			return
+		}
+	}
+
+	filter, err := parseAdFilter(query)
+	if own {
+		filter, err = parseOwnAdFilter(query, userID)
+	}
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.Warnf("failed to disable write deadline", map[string]interface{}{"error": err.Error()})
+	}
+
+	var (
+		started bool
+		rows    int
+		cw      *csv.Writer
+		write   func(ad *model.Advertisement) error
+		flush   func() error
+	)
+
+	switch format {
+	case model.ImportFormatCSV:
+		cw = csv.NewWriter(w)
+		cw.Comma = delimiter
+
+		write = func(ad *model.Advertisement) error {
+			return cw.Write([]string{
+				ad.ID,
+				csvCell(ad.AuthorUsername),
+				csvCell(ad.Caption),
+				csvCell(ad.Description),
+				ad.ImageURL,
+				formatPrice(ad.Price),
+				ad.Status,
+				ad.CreatedAt.UTC().Format(time.RFC3339),
+				ad.UpdatedAt.UTC().Format(time.RFC3339),
+			})
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+
+	default:
+		enc := json.NewEncoder(w)
+
+		write = func(ad *model.Advertisement) error {
+			return enc.Encode(mapper.Ad(ad, userID))
+		}
+		flush = func() error { return nil }
+	}
+
+	start := func() error {
+		started = true
+
+		contentType := "application/x-ndjson"
+		if format == model.ImportFormatCSV {
+			contentType = "text/csv; charset=utf-8"
+		}
+
+		filename := fmt.Sprintf("ads-%s.%s", time.Now().UTC().Format("20060102"), format)
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		if cw == nil {
+			return nil
+		}
+
+		if bom {
+			if _, err := io.WriteString(w, "\ufeff"); err != nil {
+				return err
+			}
+		}
+
+		if err := cw.Write(exportColumns); err != nil {
+			return err
+		}
+		return flush()
+	}
+
+	err = db.ExportAds(r.Context(), filter, func(ad *model.Advertisement) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		if err := write(ad); err != nil {
+			return err
+		}
+
+		rows++
+		if rows%exportFlushRows == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			return rc.Flush()
+		}
+
+		return nil
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		err = flush()
+	}
+
+	if err != nil {
+		if !started {
+			log.Error(err, "failed to export ads")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		if r.Context().Err() == nil {
+			log.Error(err, "failed to export ads")
+		}
+		// the status is already sent, break the connection so the client does
+		// not take a truncated file for a complete one
+		panic(http.ErrAbortHandler)
+	}
+
+	log.Infof("ads exported", map[string]interface{}{
+		"user_id": userID,
+		"own":     own,
+		"format":  format,
+		"rows":    rows,
+	})
+}
+
+// formatPrice writes kopecks as rubles without going through a float.
+func formatPrice(price int) string {
+	sign := ""
+	if price < 0 {
+		sign, price = "-", -price
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, price/100, price%100)
+}
+
+// csvCell keeps spreadsheets from running user text as a formula: a cell
+// starting with one of the formula characters is prefixed with a quote.
+func csvCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+
+	return s
+}
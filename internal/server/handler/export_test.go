@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"vk-internship/internal/config"
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+	zerologger "vk-internship/internal/logger/zerolog"
+)
+
+// exportDB streams the given ads, the other methods panic through the nil
+// embedded interface.
+type exportDB struct {
+	database.Database
+	ads []*model.Advertisement
+}
+
+func (db *exportDB) ExportAds(_ context.Context, _ model.AdFilter, fn func(*model.Advertisement) error) error {
+	for _, ad := range db.ads {
+		if err := fn(ad); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestCSVCell(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "=HYPERLINK(\"http://evil\")", want: "'=HYPERLINK(\"http://evil\")"},
+		{in: "+7 999 123-45-67", want: "'+7 999 123-45-67"},
+		{in: "-1+2", want: "'-1+2"},
+		{in: "@SUM(A1)", want: "'@SUM(A1)"},
+		{in: "\t=1", want: "'\t=1"},
+		{in: "\r=1", want: "'\r=1"},
+		{in: "Bike = cheap", want: "Bike = cheap"},
+		{in: "'quoted", want: "'quoted"},
+		{in: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := csvCell(tt.in); got != tt.want {
+			t.Errorf("csvCell(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExportAdsHandlerEscapesFormulas(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	db := &exportDB{ads: []*model.Advertisement{{
+		ID:             "ad-1",
+		AuthorUsername: "@admin",
+		Caption:        "=cmd|' /C calc'!A0",
+		Description:    "-2+3",
+		ImageURL:       "https://example.com/a.png",
+		Price:          150050,
+		Status:         model.AdStatusActive,
+		CreatedAt:      created,
+		UpdatedAt:      created,
+	}}}
+	log := zerologger.NewWriter(&config.LoggerConfig{Level: "disabled"}, io.Discard)
+
+	h := ExportAdsHandler(log, db, nil)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/ads/export", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("records = %q, want header and one row", records)
+	}
+
+	want := []string{"ad-1", "'@admin", "'=cmd|' /C calc'!A0", "'-2+3", "https://example.com/a.png", "1500.50", model.AdStatusActive, "2024-05-01T12:00:00Z", "2024-05-01T12:00:00Z"}
+	for i, cell := range records[1] {
+		if cell != want[i] {
+			t.Errorf("column %s = %q, want %q", exportColumns[i], cell, want[i])
+		}
+	}
+}
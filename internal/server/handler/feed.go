@@ -2,13 +2,17 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
 	"vk-internship/internal/logger"
+	"vk-internship/internal/server/problem"
 )
 
 // FeedResponse представляет ответ с лентой объявлений
@@ -24,14 +28,16 @@ type FeedResponse struct {
 // AdResponse представляет одно объявление в ответе
 // @Description Информация об объявлении
 type AdResponse struct {
-	ID             string    `json:"id"`
-	AuthorUsername string    `json:"author_username"`
-	Caption        string    `json:"caption"`
-	Description    string    `json:"description"`
-	ImageURL       string    `json:"image_url,omitempty"`
-	Price          float64   `json:"price"`
-	CreatedAt      time.Time `json:"created_at"`
-	IsOwner        *bool     `json:"is_owner,omitempty"`
+	ID             string         `json:"id"`
+	AuthorUsername string         `json:"author_username"`
+	Author         AuthorResponse `json:"author"`
+	Caption        string         `json:"caption"`
+	Description    string         `json:"description"`
+	ImageURL       string         `json:"image_url,omitempty"`
+	Price          float64        `json:"price"`
+	Status         string         `json:"status,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	IsOwner        *bool          `json:"is_owner,omitempty"`
 }
 
 var ValidSorts = map[string]struct{}{
@@ -53,135 +59,111 @@ var ValidSorts = map[string]struct{}{
 // @Param max_price query number false "Максимальная цена"
 // @Security ApiKeyAuth
 // @Success 200 {object} FeedResponse
-// @Failure 400 {string} string "Неверные параметры запроса"
-// @Failure 500 {string} string "Внутренняя ошибка сервера"
-// @Router /ads [get]
-func GetAdsHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+// @Failure 400 {object} problem.Problem "Неверные параметры запроса"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/ads [get]
+func GetAdsHandler(log logger.Logger, db database.Database, mapper ResponseMapper) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		query := r.URL.Query()
+		log := log.WithContext(r.Context())
 
-		page, err := strconv.Atoi(query.Get("page"))
-		if err != nil || page < 1 {
-			page = 1
-		}
-
-		pageSize, err := strconv.Atoi(query.Get("page_size"))
-		if err != nil || pageSize < 1 {
-			pageSize = 10
-		}
-		if pageSize > 100 {
-			pageSize = 100
-		}
-
-		sortBy := query.Get("sort_by")
-		if _, ok := ValidSorts[sortBy]; !ok {
-			sortBy = "created_at"
+		filter, err := parseAdFilter(r.URL.Query())
+		if err != nil {
+			log.Error(err, "min_price > max_price")
+			problem.Write(w, r, http.StatusBadRequest, "min_price must be less than or equal to max_price")
+			return
 		}
 
-		order := strings.ToUpper(query.Get("order"))
-		if order != "ASC" && order != "DESC" {
-			order = "DESC"
-		}
+		writeAdsPage(w, r, log, db, mapper, filter)
+	}
+}
 
-		var minPrice, maxPrice *int
-		if minStr := query.Get("min_price"); minStr != "" {
-			if val, err := strconv.ParseFloat(minStr, 64); err == nil && val >= 0 {
-				minPriceVal := int(val * 100)
-				minPrice = &minPriceVal
-			}
-		}
+// writeAdsPage answers with the requested page of the ads matching filter. A
+// page past the end is replaced with the last one.
+func writeAdsPage(w http.ResponseWriter, r *http.Request, log logger.Logger, db database.Database, mapper ResponseMapper, filter model.AdFilter) {
+	page, pageSize := parsePagination(r)
 
-		if maxStr := query.Get("max_price"); maxStr != "" {
-			if val, err := strconv.ParseFloat(maxStr, 64); err == nil && val >= 0 {
-				maxPriceVal := int(val * 100)
-				maxPrice = &maxPriceVal
-			}
-		}
+	ads, total, err := db.GetAds(r.Context(), filter, page, pageSize)
+	if err != nil {
+		log.Error(err, "failed to get ads")
+		problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+		return
+	}
 
-		if minPrice != nil && maxPrice != nil && *minPrice > *maxPrice {
-			log.Error(err, "min_price > max_price")
-			http.Error(w, "min_price must be less than or equal to max_price", http.StatusBadRequest)
-			return
-		}
+	pages := totalPages(total, pageSize)
 
-		ads, total, err := db.GetAds(
-			r.Context(),
-			sortBy,
-			order,
-			minPrice,
-			maxPrice,
-			page,
-			pageSize,
-		)
+	if pages > 0 && page > pages {
+		page = pages
+		ads, total, err = db.GetAds(r.Context(), filter, page, pageSize)
 		if err != nil {
 			log.Error(err, "failed to get ads")
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
 			return
 		}
+	}
 
-		totalPages := total / pageSize
-		if total%pageSize > 0 {
-			totalPages++
-		}
+	var userID string
+	var isAuthenticated bool
+	if ctxUserID, ok := r.Context().Value("userID").(string); ok && ctxUserID != "" {
+		userID = ctxUserID
+		isAuthenticated = true
+	}
 
-		if totalPages > 0 && page > totalPages {
-			page = totalPages
-			ads, total, err = db.GetAds(
-				r.Context(),
-				sortBy,
-				order,
-				minPrice,
-				maxPrice,
-				page,
-				pageSize,
-			)
-			if err != nil {
-				log.Error(err, "failed to get ads")
-				http.Error(w, "Internal server error", http.StatusInternalServerError)
-				return
-			}
-		}
+	log.Debugf("check userID", map[string]interface{}{"userID": userID, "isAuthenticated": isAuthenticated})
 
-		var userID string
-		var isAuthenticated bool
-		if ctxUserID, ok := r.Context().Value("userID").(string); ok && ctxUserID != "" {
-			userID = ctxUserID
-			isAuthenticated = true
-		}
+	response := mapper.Feed(FeedPage{
+		Ads:        ads,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: pages,
+	}, userID)
 
-		log.Debugf("check userID", map[string]interface{}{"userID": userID, "isAuthenticated": isAuthenticated})
-
-		responseAds := make([]AdResponse, 0, len(ads))
-		for _, ad := range ads {
-			respAd := AdResponse{
-				ID:             ad.ID,
-				AuthorUsername: ad.AuthorUsername,
-				Caption:        ad.Caption,
-				Description:    ad.Description,
-				ImageURL:       ad.ImageURL,
-				Price:          float64(ad.Price) / 100,
-				CreatedAt:      ad.CreatedAt,
-			}
-
-			if isAuthenticated {
-				isOwner := userID == ad.AuthorID
-				respAd.IsOwner = &isOwner
-			}
-
-			responseAds = append(responseAds, respAd)
-		}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error(err, "failed to encode response")
+	}
+}
+
+// parseAdFilter reads the sorting and price filters shared by the feed and
+// the export. Unknown sorting falls back to the newest ads first.
+func parseAdFilter(query url.Values) (model.AdFilter, error) {
+	sortBy := query.Get("sort_by")
+	if _, ok := ValidSorts[sortBy]; !ok {
+		sortBy = "created_at"
+	}
+
+	order := strings.ToUpper(query.Get("order"))
+	if order != "ASC" && order != "DESC" {
+		order = "DESC"
+	}
+
+	minPrice, maxPrice, err := parsePriceRange(query)
+	if err != nil {
+		return model.AdFilter{}, err
+	}
+
+	return model.AdFilter{SortBy: sortBy, Order: order, MinPrice: minPrice, MaxPrice: maxPrice}, nil
+}
 
-		response := FeedResponse{
-			Ads:        responseAds,
-			Page:       page,
-			PageSize:   pageSize,
-			Total:      total,
-			TotalPages: totalPages,
+func parsePriceRange(query url.Values) (*int, *int, error) {
+	var minPrice, maxPrice *int
+	if minStr := query.Get("min_price"); minStr != "" {
+		if val, err := strconv.ParseFloat(minStr, 64); err == nil && val >= 0 {
+			minPriceVal := int(val * 100)
+			minPrice = &minPriceVal
 		}
+	}
 
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			log.Error(err, "failed to encode response")
+	if maxStr := query.Get("max_price"); maxStr != "" {
+		if val, err := strconv.ParseFloat(maxStr, 64); err == nil && val >= 0 {
+			maxPriceVal := int(val * 100)
+			maxPrice = &maxPriceVal
 		}
 	}
+
+	if minPrice != nil && maxPrice != nil && *minPrice > *maxPrice {
+		return nil, nil, errors.New("min_price must be less than or equal to max_price")
+	}
+
+	return minPrice, maxPrice, nil
 }
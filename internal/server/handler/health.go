@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"vk-internship/internal/health"
+	"vk-internship/internal/logger"
+)
+
+// LivenessResponse представляет ответ проверки жизнеспособности
+// @Description Процесс запущен и обрабатывает запросы
+type LivenessResponse struct {
+	Status string `json:"status" example:"ok"`
+}
+
+// LivenessHandler проверяет, что процесс жив
+// @Summary Проверка жизнеспособности
+// @Description Всегда возвращает 200, пока процесс обрабатывает запросы. Зависимости не проверяются
+// @Tags health
+// @Produce json
+// @Success 200 {object} LivenessResponse
+// @Router /healthz [get]
+func LivenessHandler(log logger.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(LivenessResponse{Status: health.StatusOK}); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// ReadinessHandler проверяет готовность принимать трафик
+// @Summary Проверка готовности
+// @Description Проверяет доступность PostgreSQL и Redis и возвращает статус и задержку каждой зависимости. Недоступность некритичной зависимости (кэш при HEALTH_CACHE_CRITICAL=false) дает статус degraded с кодом 200. Во время остановки сервера всегда возвращает 503
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func ReadinessHandler(log logger.Logger, checker *health.Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		report, ready := checker.Check(r.Context())
+
+		status := http.StatusOK
+		if !ready {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
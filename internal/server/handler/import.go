@@ -0,0 +1,293 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"vk-internship/internal/config"
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+	"vk-internship/internal/importer"
+	"vk-internship/internal/logger"
+	"vk-internship/internal/server/problem"
+	"vk-internship/internal/utils"
+)
+
+// ImportRowResponse представляет результат обработки одной строки файла
+// @Description Результат строки: created, invalid, skipped или pending, пока задача не выполнена
+type ImportRowResponse struct {
+	Line   int                     `json:"line"`
+	Status string                  `json:"status"`
+	AdID   string                  `json:"ad_id,omitempty"`
+	Errors []utils.ValidationError `json:"errors,omitempty"`
+}
+
+// ImportJobResponse представляет задачу импорта объявлений
+// @Description Состояние импорта (pending, running, completed, failed) и отчет по строкам
+type ImportJobResponse struct {
+	ID          string              `json:"id"`
+	Status      string              `json:"status"`
+	Format      string              `json:"format"`
+	Mode        string              `json:"mode"`
+	TotalRows   int                 `json:"total_rows"`
+	CreatedRows int                 `json:"created_rows"`
+	FailedRows  int                 `json:"failed_rows"`
+	Error       string              `json:"error,omitempty"`
+	Rows        []ImportRowResponse `json:"rows"`
+	CreatedAt   time.Time           `json:"created_at"`
+	FinishedAt  *time.Time          `json:"finished_at,omitempty"`
+}
+
+func toImportJobResponse(job *model.ImportJob) ImportJobResponse {
+	response := ImportJobResponse{
+		ID:          job.ID,
+		Status:      job.Status,
+		Format:      job.Format,
+		Mode:        job.Mode,
+		TotalRows:   job.TotalRows,
+		CreatedRows: job.CreatedRows,
+		FailedRows:  job.FailedRows,
+		Rows:        make([]ImportRowResponse, 0, len(job.Rows)),
+		CreatedAt:   job.CreatedAt,
+		FinishedAt:  job.FinishedAt,
+	}
+
+	if job.Error != nil {
+		response.Error = *job.Error
+	}
+
+	for _, row := range job.Rows {
+		respRow := ImportRowResponse{Line: row.Line, Status: row.Status, AdID: row.AdID}
+		for _, e := range row.Errors {
+			respRow.Errors = append(respRow.Errors, utils.ValidationError{Field: e.Field, Message: e.Message})
+		}
+		response.Rows = append(response.Rows, respRow)
+	}
+
+	return response
+}
+
+// ImportAdsHandler импортирует объявления из файла
+// @Security BearerAuth
+// @Summary Импортировать объявления
+// @Description Принимает CSV (заголовок caption, description, price и необязательный image_url) или NDJSON (по объекту CreateAdRequest на строку). Каждая строка проверяется по тем же правилам, что и при создании объявления. В режиме atomic при любой ошибке ничего не создается, в режиме partial создаются только корректные строки. Небольшие файлы обрабатываются сразу, большие ставятся в очередь: ответ 202 с заголовком Location, по которому можно узнать состояние задачи
+// @Tags ads
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param file body string true "Содержимое файла"
+// @Param mode query string false "Режим: atomic (по умолчанию) или partial" Enums(atomic, partial)
+// @Param format query string false "Формат, если Content-Type не указан" Enums(csv, ndjson)
+// @Param Idempotency-Key header string false "Ключ идемпотентности"
+// @Success 200 {object} ImportJobResponse "Импорт выполнен или отклонен"
+// @Success 202 {object} ImportJobResponse "Задача поставлена в очередь"
+// @Failure 400 {object} problem.Problem "Неверный файл или параметры"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 413 {object} problem.Problem "Файл слишком большой"
+// @Failure 415 {object} problem.Problem "Неподдерживаемый формат"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/ads/import [post]
+func ImportAdsHandler(cfg *config.ImportConfig, log logger.Logger, db database.Database, imports *importer.Importer) http.HandlerFunc {
+	validate := utils.NewValidator()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			log.Warn("userID not found in context")
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		mode := r.URL.Query().Get("mode")
+		if mode == "" {
+			mode = model.ImportModeAtomic
+		}
+		if mode != model.ImportModeAtomic && mode != model.ImportModePartial {
+			problem.Write(w, r, http.StatusBadRequest, "mode must be atomic or partial")
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = importer.Format(r.Header.Get("Content-Type"))
+		}
+		if format != model.ImportFormatCSV && format != model.ImportFormatNDJSON {
+			problem.Write(w, r, http.StatusUnsupportedMediaType, "Send text/csv or application/x-ndjson")
+			return
+		}
+
+		rows, err := importer.Parse(format, http.MaxBytesReader(w, r.Body, cfg.MaxBytes), cfg.MaxRows)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			switch {
+			case errors.As(err, &maxBytesErr):
+				problem.Write(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("File is larger than %d bytes", cfg.MaxBytes))
+			case errors.Is(err, importer.ErrTooManyRows):
+				problem.Write(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("File has more than %d rows", cfg.MaxRows))
+			default:
+				log.Warnf("invalid import file", map[string]interface{}{"error": err.Error()})
+				problem.Write(w, r, http.StatusBadRequest, err.Error())
+			}
+			return
+		}
+
+		if len(rows) == 0 {
+			problem.Write(w, r, http.StatusBadRequest, "File has no rows")
+			return
+		}
+
+		job := &model.ImportJob{
+			UserID:    userID,
+			Format:    format,
+			Mode:      mode,
+			TotalRows: len(rows),
+			Rows:      make([]model.ImportRow, len(rows)),
+		}
+
+		for i, row := range rows {
+			result := model.ImportRow{Line: row.Line, Status: model.ImportRowPending}
+
+			req := CreateAdRequest{
+				Caption:     row.Caption,
+				Description: row.Description,
+				ImageURL:    row.ImageURL,
+				Price:       row.Price,
+			}
+
+			if row.Err != nil {
+				result.Errors = []model.ImportFieldError{*row.Err}
+			} else if err := validate.Validate(req); err != nil {
+				for _, e := range validate.FormatValidationErrors(err).Errors {
+					result.Errors = append(result.Errors, model.ImportFieldError{Field: e.Field, Message: e.Message})
+				}
+			}
+
+			if result.Errors != nil {
+				result.Status = model.ImportRowInvalid
+				job.FailedRows++
+			} else {
+				result.Ad = &model.Advertisement{
+					Caption:     req.Caption,
+					Description: req.Description,
+					ImageURL:    req.ImageURL,
+					Price:       int(req.Price * 100),
+				}
+			}
+
+			job.Rows[i] = result
+		}
+
+		switch {
+		case job.FailedRows > 0 && mode == model.ImportModeAtomic:
+			reason := fmt.Sprintf("%d of %d rows are invalid, nothing was imported", job.FailedRows, job.TotalRows)
+			job.Status = model.ImportStatusFailed
+			job.Error = &reason
+			for i := range job.Rows {
+				if job.Rows[i].Status == model.ImportRowPending {
+					job.Rows[i].Status = model.ImportRowSkipped
+					job.Rows[i].Ad = nil
+				}
+			}
+
+		case job.FailedRows == job.TotalRows:
+			job.Status = model.ImportStatusCompleted
+
+		default:
+			job.Status = model.ImportStatusPending
+		}
+
+		job, err = db.CreateImportJob(r.Context(), job)
+		if err != nil {
+			log.Error(err, "failed to create import job")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		status := http.StatusOK
+
+		if job.Status == model.ImportStatusPending {
+			if job.TotalRows <= cfg.SyncRows {
+				id := job.ID
+				job, err = imports.Execute(r.Context(), id)
+				if errors.Is(err, database.ErrImportJobNotFound) {
+					// the importer picked the job up first
+					job, err = db.GetImportJob(r.Context(), id, userID)
+				}
+				if err != nil {
+					log.Error(err, "failed to execute import job")
+					problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+					return
+				}
+			} else {
+				status = http.StatusAccepted
+				w.Header().Set("Location", r.URL.Path+"/"+job.ID)
+			}
+		}
+
+		log.Infof("ads import accepted", map[string]interface{}{
+			"job_id":  job.ID,
+			"user_id": userID,
+			"format":  format,
+			"mode":    mode,
+			"rows":    job.TotalRows,
+			"invalid": job.FailedRows,
+			"status":  job.Status,
+			"queued":  status == http.StatusAccepted,
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(toImportJobResponse(job)); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// GetImportJobHandler возвращает состояние импорта
+// @Security BearerAuth
+// @Summary Получить состояние импорта
+// @Description Возвращает состояние задачи импорта и отчет по строкам. Доступно только автору импорта
+// @Tags ads
+// @Produce json
+// @Param id path string true "ID задачи импорта"
+// @Success 200 {object} ImportJobResponse
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 404 {object} problem.Problem "Задача не найдена"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/ads/import/{id} [get]
+func GetImportJobHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			log.Warn("userID not found in context")
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		job, err := db.GetImportJob(r.Context(), chi.URLParam(r, "id"), userID)
+		if err != nil {
+			if errors.Is(err, database.ErrImportJobNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Import job not found")
+				return
+			}
+
+			log.Error(err, "failed to get import job")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(toImportJobResponse(job)); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"vk-internship/internal/config"
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+	zerologger "vk-internship/internal/logger/zerolog"
+)
+
+// importDB stores the created job, the other methods panic through the nil
+// embedded interface.
+type importDB struct {
+	database.Database
+	job *model.ImportJob
+}
+
+func (db *importDB) CreateImportJob(_ context.Context, job *model.ImportJob) (*model.ImportJob, error) {
+	job.ID = "job-1"
+	db.job = job
+	return job, nil
+}
+
+func TestImportAdsHandler(t *testing.T) {
+	const header = "caption,description,price\n"
+
+	tests := []struct {
+		name        string
+		query       string
+		contentType string
+		body        string
+		wantStatus  int
+		wantDetail  string
+		wantJob     string
+		wantFailed  int
+		// wantRows are the row statuses of the report
+		wantRows []string
+	}{
+		{
+			name:        "valid rows are queued",
+			query:       "?mode=partial",
+			contentType: "text/csv",
+			body:        header + "Bike,Red bike,1500\nLamp,Desk lamp,99.5\n",
+			wantStatus:  http.StatusAccepted,
+			wantJob:     model.ImportStatusPending,
+			wantRows:    []string{model.ImportRowPending, model.ImportRowPending},
+		},
+		{
+			name:        "atomic import with an invalid row imports nothing",
+			contentType: "text/csv",
+			body:        header + "Bike,Red bike,1500\nAb,Too short caption,10\n",
+			wantStatus:  http.StatusOK,
+			wantJob:     model.ImportStatusFailed,
+			wantFailed:  1,
+			wantRows:    []string{model.ImportRowSkipped, model.ImportRowInvalid},
+		},
+		{
+			name:        "partial import keeps the valid rows",
+			query:       "?mode=partial",
+			contentType: "application/x-ndjson",
+			body:        "{\"caption\":\"Bike\",\"description\":\"Red bike\",\"price\":1500}\n{\"caption\":\"Lamp\",\"price\":\"cheap\"}\n",
+			wantStatus:  http.StatusAccepted,
+			wantJob:     model.ImportStatusPending,
+			wantFailed:  1,
+			wantRows:    []string{model.ImportRowPending, model.ImportRowInvalid},
+		},
+		{
+			name:        "only invalid rows",
+			query:       "?mode=partial",
+			contentType: "text/csv",
+			body:        header + "Bike,,1500\n",
+			wantStatus:  http.StatusOK,
+			wantJob:     model.ImportStatusCompleted,
+			wantFailed:  1,
+			wantRows:    []string{model.ImportRowInvalid},
+		},
+		{
+			name:        "file over the size limit",
+			contentType: "text/csv",
+			body:        header + "Bike," + strings.Repeat("x", 300) + ",1500\n",
+			wantStatus:  http.StatusRequestEntityTooLarge,
+			wantDetail:  "larger than 256 bytes",
+		},
+		{
+			name:        "too many rows",
+			contentType: "text/csv",
+			body:        header + strings.Repeat("A,a,1\n", 4),
+			wantStatus:  http.StatusRequestEntityTooLarge,
+			wantDetail:  "more than 3 rows",
+		},
+		{
+			name:        "file without rows",
+			contentType: "text/csv",
+			body:        header,
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "missing column",
+			contentType: "text/csv",
+			body:        "caption,price\nBike,1500\n",
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "unsupported content type",
+			contentType: "application/json",
+			body:        "[]",
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "unknown mode",
+			query:       "?mode=best_effort",
+			contentType: "text/csv",
+			body:        header + "Bike,Red bike,1500\n",
+			wantStatus:  http.StatusBadRequest,
+		},
+	}
+
+	cfg := &config.ImportConfig{MaxBytes: 256, MaxRows: 3, SyncRows: 0}
+	log := zerologger.NewWriter(&config.LoggerConfig{Level: "disabled"}, io.Discard)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &importDB{}
+			h := ImportAdsHandler(cfg, log, db, nil)
+
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/ads/import"+tt.query, strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+			r = r.WithContext(context.WithValue(r.Context(), "userID", "user-1"))
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantDetail != "" && !strings.Contains(rec.Body.String(), tt.wantDetail) {
+				t.Errorf("body = %s, want detail %q", rec.Body.String(), tt.wantDetail)
+			}
+			if tt.wantJob == "" {
+				if db.job != nil {
+					t.Error("job was created for a rejected file")
+				}
+				return
+			}
+
+			var response ImportJobResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+
+			if response.Status != tt.wantJob {
+				t.Errorf("job status = %q, want %q", response.Status, tt.wantJob)
+			}
+			if response.FailedRows != tt.wantFailed {
+				t.Errorf("failed rows = %d, want %d", response.FailedRows, tt.wantFailed)
+			}
+			if len(response.Rows) != len(tt.wantRows) {
+				t.Fatalf("rows = %+v, want statuses %v", response.Rows, tt.wantRows)
+			}
+			for i, row := range response.Rows {
+				if row.Status != tt.wantRows[i] {
+					t.Errorf("row %d status = %q, want %q", i, row.Status, tt.wantRows[i])
+				}
+				if (row.Status == model.ImportRowInvalid) != (len(row.Errors) > 0) {
+					t.Errorf("row %d errors = %+v", i, row.Errors)
+				}
+			}
+		})
+	}
+}
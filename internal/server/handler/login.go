@@ -11,6 +11,9 @@ import (
 	"vk-internship/internal/config"
 	"vk-internship/internal/database"
 	"vk-internship/internal/logger"
+	"vk-internship/internal/metrics"
+	"vk-internship/internal/server/problem"
+	"vk-internship/internal/tracing"
 	"vk-internship/internal/utils"
 )
 
@@ -39,14 +42,17 @@ type LoginResponse struct {
 // @Produce json
 // @Param request body LoginRequest true "Данные для входа"
 // @Success 200 {object} LoginResponse
-// @Failure 400 {object} map[string]string "Неверный формат запроса или ошибки валидации"
-// @Failure 401 {string} string "Неверные учетные данные"
-// @Failure 500 {string} string "Внутренняя ошибка сервера"
-// @Router /login [post]
+// @Failure 400 {object} problem.Problem "Неверный формат запроса или ошибки валидации"
+// @Failure 401 {object} problem.Problem "Неверные учетные данные"
+// @Failure 403 {object} problem.Problem "Учетная запись заблокирована"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/login [post]
 func LoginHandler(cfg *config.ServerConfig, log logger.Logger, db database.Database) http.HandlerFunc {
 	validate := utils.NewValidator()
 
 	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
 		var (
 			currentUserID string
 			isAuthorized  bool
@@ -60,7 +66,7 @@ func LoginHandler(cfg *config.ServerConfig, log logger.Logger, db database.Datab
 		var req LoginRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			log.Warnf("invalid request body", map[string]interface{}{"error": err.Error()})
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
 			return
 		}
 
@@ -68,35 +74,46 @@ func LoginHandler(cfg *config.ServerConfig, log logger.Logger, db database.Datab
 			validationErrors := validate.FormatValidationErrors(err)
 			log.Warnf("validation failed", map[string]interface{}{"errors": validationErrors})
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(validationErrors)
+			problem.Validation(w, r, validationErrors.Errors)
 			return
 		}
 
-		user, err := db.GetUserByUsername(req.Username)
+		user, err := db.GetUserByUsername(r.Context(), req.Username)
 		if err != nil {
 			if errors.Is(err, database.ErrUserNotFound) {
+				metrics.LoginsFailed.WithLabelValues("user_not_found").Inc()
 				log.Warnf("user not found", map[string]interface{}{"username": req.Username})
-				http.Error(w, "User not found", http.StatusUnauthorized)
+				problem.Write(w, r, http.StatusUnauthorized, "User not found")
 				return
 			}
 
 			log.Error(err, "failed to get user")
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
 			return
 		}
 
-		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		_, span := tracing.Start(r.Context(), "bcrypt.CompareHashAndPassword")
+		err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+		span.End()
+
+		if err != nil {
+			metrics.LoginsFailed.WithLabelValues("invalid_password").Inc()
 			log.Warnf("invalid password", map[string]interface{}{"username": req.Username})
-			http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+			problem.Write(w, r, http.StatusUnauthorized, "Invalid credentials")
+			return
+		}
+
+		if user.DisabledAt != nil {
+			metrics.LoginsFailed.WithLabelValues("disabled").Inc()
+			log.Warnf("login of disabled user", map[string]interface{}{"username": req.Username})
+			problem.Write(w, r, http.StatusForbidden, "Account is disabled")
 			return
 		}
 
 		token, err := utils.GenerateJWTToken(cfg, user.ID, user.Username)
 		if err != nil {
 			log.Error(err, "failed to generate token")
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
 			return
 		}
 
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"vk-internship/internal/broker"
+	"vk-internship/internal/database/model"
+)
+
+// ResponseMapper converts models into the response bodies of one API version.
+// Handlers that return ads take a mapper, so a new version can reuse them and
+// change only the payloads.
+type ResponseMapper interface {
+	CreatedAd(ad *model.Advertisement) interface{}
+	UpdatedAd(ad *model.Advertisement) interface{}
+	// Ad and Feed get an empty userID for anonymous requests.
+	Ad(ad *model.Advertisement, userID string) interface{}
+	Feed(feed FeedPage, userID string) interface{}
+	AdEvent(event broker.Event) interface{}
+}
+
+// FeedPage is one page of the feed before it is mapped to a response.
+type FeedPage struct {
+	Ads        []*model.Advertisement
+	Page       int
+	PageSize   int
+	Total      int
+	TotalPages int
+}
+
+type v1Mapper struct{}
+
+var V1 ResponseMapper = v1Mapper{}
+
+func (v1Mapper) CreatedAd(ad *model.Advertisement) interface{} {
+	return CreateAdResponse{
+		ID:          ad.ID,
+		AuthorID:    ad.AuthorID,
+		Caption:     ad.Caption,
+		Description: ad.Description,
+		ImageURL:    ad.ImageURL,
+		Price:       float64(ad.Price) / 100,
+		Status:      ad.Status,
+		CreatedAt:   ad.CreatedAt,
+	}
+}
+
+func (v1Mapper) UpdatedAd(ad *model.Advertisement) interface{} {
+	return UpdateAdResponse{
+		ID:          ad.ID,
+		Caption:     ad.Caption,
+		Description: ad.Description,
+		ImageURL:    ad.ImageURL,
+		Price:       float64(ad.Price) / 100,
+		Status:      ad.Status,
+		CreatedAt:   ad.CreatedAt,
+		UpdatedAt:   ad.UpdatedAt,
+	}
+}
+
+func (v1Mapper) Ad(ad *model.Advertisement, userID string) interface{} {
+	response := GetAdResponse{
+		ID:             ad.ID,
+		AuthorUsername: ad.AuthorUsername,
+		Author:         toAuthorResponse(ad, userID),
+		Caption:        ad.Caption,
+		Description:    ad.Description,
+		ImageURL:       ad.ImageURL,
+		Price:          float64(ad.Price) / 100,
+		CreatedAt:      ad.CreatedAt,
+	}
+
+	if userID != "" {
+		isOwner := userID == ad.AuthorID
+		response.IsOwner = &isOwner
+		if isOwner {
+			response.Status = ad.Status
+		}
+	}
+
+	return response
+}
+
+func (v1Mapper) Feed(feed FeedPage, userID string) interface{} {
+	ads := make([]AdResponse, 0, len(feed.Ads))
+	for _, ad := range feed.Ads {
+		respAd := toAdResponse(ad, userID)
+
+		if userID != "" {
+			isOwner := userID == ad.AuthorID
+			respAd.IsOwner = &isOwner
+			if isOwner {
+				respAd.Status = ad.Status
+			}
+		}
+
+		ads = append(ads, respAd)
+	}
+
+	return FeedResponse{
+		Ads:        ads,
+		Page:       feed.Page,
+		PageSize:   feed.PageSize,
+		Total:      feed.Total,
+		TotalPages: feed.TotalPages,
+	}
+}
+
+func (v1Mapper) AdEvent(event broker.Event) interface{} {
+	data := AdEventResponse{
+		AdID:       event.AdID,
+		OccurredAt: event.OccurredAt,
+	}
+	if event.Ad != nil {
+		ad := toAdResponse(event.Ad, "")
+		data.Ad = &ad
+	}
+
+	return data
+}
+
+func toAdResponse(ad *model.Advertisement, userID string) AdResponse {
+	return AdResponse{
+		ID:             ad.ID,
+		AuthorUsername: ad.AuthorUsername,
+		Author:         toAuthorResponse(ad, userID),
+		Caption:        ad.Caption,
+		Description:    ad.Description,
+		ImageURL:       ad.ImageURL,
+		Price:          float64(ad.Price) / 100,
+		CreatedAt:      ad.CreatedAt,
+	}
+}
+
+// toAuthorResponse returns the public part of the author's profile, the phone
+// only if its visibility allows userID to see it.
+func toAuthorResponse(ad *model.Advertisement, userID string) AuthorResponse {
+	return AuthorResponse{
+		Username:    ad.AuthorUsername,
+		DisplayName: ad.Author.DisplayName,
+		AvatarURL:   ad.Author.AvatarURL,
+		City:        ad.Author.City,
+		Phone:       ad.Author.VisiblePhone(ad.AuthorID, userID),
+	}
+}
@@ -0,0 +1,550 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+	"vk-internship/internal/logger"
+	"vk-internship/internal/server/problem"
+	"vk-internship/internal/utils"
+)
+
+// SendMessageRequest представляет запрос на отправку сообщения
+// @Description Текст сообщения
+type SendMessageRequest struct {
+	Body string `json:"body" validate:"required,max=2000"`
+}
+
+// MessageResponse представляет одно сообщение
+// @Description Сообщение в переписке
+type MessageResponse struct {
+	ID             string     `json:"id"`
+	ConversationID string     `json:"conversation_id"`
+	SenderID       string     `json:"sender_id"`
+	Body           string     `json:"body"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ReadAt         *time.Time `json:"read_at,omitempty"`
+	IsMine         bool       `json:"is_mine"`
+}
+
+// StartConversationResponse представляет ответ после первого сообщения продавцу
+// @Description Переписка и отправленное сообщение
+type StartConversationResponse struct {
+	ConversationID string          `json:"conversation_id"`
+	Message        MessageResponse `json:"message"`
+}
+
+// ConversationResponse представляет переписку по объявлению
+// @Description Переписка покупателя и продавца по объявлению
+type ConversationResponse struct {
+	ID             string           `json:"id"`
+	AdID           *string          `json:"ad_id,omitempty"`
+	AdCaption      *string          `json:"ad_caption,omitempty"`
+	BuyerUsername  string           `json:"buyer_username"`
+	SellerUsername string           `json:"seller_username"`
+	IsSeller       bool             `json:"is_seller"`
+	CreatedAt      time.Time        `json:"created_at"`
+	LastMessageAt  time.Time        `json:"last_message_at"`
+	LastMessage    *MessageResponse `json:"last_message,omitempty"`
+	UnreadCount    int              `json:"unread_count"`
+}
+
+// ConversationsResponse представляет список переписок
+// @Description Список переписок с пагинацией
+type ConversationsResponse struct {
+	Conversations []ConversationResponse `json:"conversations"`
+	Page          int                    `json:"page"`
+	PageSize      int                    `json:"page_size"`
+	Total         int                    `json:"total"`
+	TotalPages    int                    `json:"total_pages"`
+}
+
+// MessagesResponse представляет список сообщений
+// @Description Сообщения переписки (от новых к старым) с пагинацией
+type MessagesResponse struct {
+	Messages   []MessageResponse `json:"messages"`
+	Page       int               `json:"page"`
+	PageSize   int               `json:"page_size"`
+	Total      int               `json:"total"`
+	TotalPages int               `json:"total_pages"`
+}
+
+// UnreadCountResponse представляет количество непрочитанных сообщений
+// @Description Количество непрочитанных сообщений
+type UnreadCountResponse struct {
+	Unread int `json:"unread"`
+}
+
+// MarkReadResponse представляет результат отметки сообщений прочитанными
+// @Description Количество сообщений, отмеченных прочитанными
+type MarkReadResponse struct {
+	Marked int `json:"marked"`
+}
+
+// StartConversationHandler начинает переписку с продавцом
+// @Security BearerAuth
+// @Summary Написать продавцу
+// @Description Отправляет сообщение автору объявления. Если переписка по объявлению уже есть, сообщение добавляется в нее
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path string true "ID объявления"
+// @Param request body SendMessageRequest true "Сообщение"
+// @Success 201 {object} StartConversationResponse
+// @Failure 400 {object} problem.Problem "Неверный формат запроса или ошибки валидации"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 403 {object} problem.Problem "Пользователь заблокирован или объявление принадлежит пользователю"
+// @Failure 404 {object} problem.Problem "Объявление не найдено"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/ads/{id}/messages [post]
+func StartConversationHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	validate := utils.NewValidator()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		adID := chi.URLParam(r, "id")
+		if adID == "" {
+			problem.Write(w, r, http.StatusBadRequest, "Ad ID is required")
+			return
+		}
+
+		var req SendMessageRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Warnf("invalid request body", map[string]interface{}{"error": err.Error()})
+			problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		if err := validate.Validate(req); err != nil {
+			validationErrors := validate.FormatValidationErrors(err)
+			log.Warnf("validation failed", map[string]interface{}{"errors": validationErrors})
+
+			problem.Validation(w, r, validationErrors.Errors)
+			return
+		}
+
+		conversation, message, err := db.StartConversation(r.Context(), adID, userID, req.Body)
+		if err != nil {
+			switch {
+			case errors.Is(err, database.ErrAdNotFound):
+				problem.Write(w, r, http.StatusNotFound, "Ad not found")
+			case errors.Is(err, database.ErrCannotMessageOwnAd):
+				problem.Write(w, r, http.StatusForbidden, "Cannot message yourself")
+			case errors.Is(err, database.ErrUserBlocked):
+				problem.Write(w, r, http.StatusForbidden, "User is blocked")
+			default:
+				log.Error(err, "failed to start conversation")
+				problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			}
+			return
+		}
+
+		response := StartConversationResponse{
+			ConversationID: conversation.ID,
+			Message:        toMessageResponse(message, userID),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+
+		log.Infof("conversation message sent", map[string]interface{}{
+			"conversation_id": conversation.ID,
+			"ad_id":           adID,
+			"sender_id":       userID,
+		})
+	}
+}
+
+// SendMessageHandler отправляет сообщение в переписку
+// @Security BearerAuth
+// @Summary Отправить сообщение
+// @Description Отправляет сообщение в существующую переписку (только для участников)
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path string true "ID переписки"
+// @Param request body SendMessageRequest true "Сообщение"
+// @Success 201 {object} MessageResponse
+// @Failure 400 {object} problem.Problem "Неверный формат запроса или ошибки валидации"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 403 {object} problem.Problem "Пользователь заблокирован"
+// @Failure 404 {object} problem.Problem "Переписка не найдена"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/conversations/{id}/messages [post]
+func SendMessageHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	validate := utils.NewValidator()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		conversationID := chi.URLParam(r, "id")
+		if conversationID == "" {
+			problem.Write(w, r, http.StatusBadRequest, "Conversation ID is required")
+			return
+		}
+
+		var req SendMessageRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Warnf("invalid request body", map[string]interface{}{"error": err.Error()})
+			problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		if err := validate.Validate(req); err != nil {
+			validationErrors := validate.FormatValidationErrors(err)
+			log.Warnf("validation failed", map[string]interface{}{"errors": validationErrors})
+
+			problem.Validation(w, r, validationErrors.Errors)
+			return
+		}
+
+		message, err := db.SendMessage(r.Context(), conversationID, userID, req.Body)
+		if err != nil {
+			switch {
+			case errors.Is(err, database.ErrConversationNotFound):
+				problem.Write(w, r, http.StatusNotFound, "Conversation not found")
+			case errors.Is(err, database.ErrUserBlocked):
+				problem.Write(w, r, http.StatusForbidden, "User is blocked")
+			default:
+				log.Error(err, "failed to send message")
+				problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(toMessageResponse(message, userID)); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// GetConversationsHandler возвращает переписки пользователя
+// @Security BearerAuth
+// @Summary Список переписок
+// @Description Возвращает переписки текущего пользователя (как покупателя и как продавца) с количеством непрочитанных сообщений
+// @Tags messages
+// @Produce json
+// @Param page query int false "Номер страницы" default(1) minimum(1)
+// @Param page_size query int false "Количество элементов на странице" default(10) minimum(1) maximum(100)
+// @Success 200 {object} ConversationsResponse
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/conversations [get]
+func GetConversationsHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		page, pageSize := parsePagination(r)
+
+		conversations, total, err := db.GetConversations(r.Context(), userID, page, pageSize)
+		if err != nil {
+			log.Error(err, "failed to get conversations")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		response := ConversationsResponse{
+			Conversations: make([]ConversationResponse, 0, len(conversations)),
+			Page:          page,
+			PageSize:      pageSize,
+			Total:         total,
+			TotalPages:    totalPages(total, pageSize),
+		}
+
+		for _, c := range conversations {
+			conversation := ConversationResponse{
+				ID:             c.ID,
+				AdID:           c.AdID,
+				AdCaption:      c.AdCaption,
+				BuyerUsername:  c.BuyerUsername,
+				SellerUsername: c.SellerUsername,
+				IsSeller:       c.SellerID == userID,
+				CreatedAt:      c.CreatedAt,
+				LastMessageAt:  c.LastMessageAt,
+				UnreadCount:    c.UnreadCount,
+			}
+
+			if c.LastMessage != nil {
+				lastMessage := toMessageResponse(c.LastMessage, userID)
+				conversation.LastMessage = &lastMessage
+			}
+
+			response.Conversations = append(response.Conversations, conversation)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// GetMessagesHandler возвращает сообщения переписки
+// @Security BearerAuth
+// @Summary Сообщения переписки
+// @Description Возвращает сообщения переписки от новых к старым (только для участников)
+// @Tags messages
+// @Produce json
+// @Param id path string true "ID переписки"
+// @Param page query int false "Номер страницы" default(1) minimum(1)
+// @Param page_size query int false "Количество элементов на странице" default(10) minimum(1) maximum(100)
+// @Success 200 {object} MessagesResponse
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 404 {object} problem.Problem "Переписка не найдена"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/conversations/{id}/messages [get]
+func GetMessagesHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		conversationID := chi.URLParam(r, "id")
+		if conversationID == "" {
+			problem.Write(w, r, http.StatusBadRequest, "Conversation ID is required")
+			return
+		}
+
+		page, pageSize := parsePagination(r)
+
+		messages, total, err := db.GetMessages(r.Context(), conversationID, userID, page, pageSize)
+		if err != nil {
+			if errors.Is(err, database.ErrConversationNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Conversation not found")
+				return
+			}
+			log.Error(err, "failed to get messages")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		response := MessagesResponse{
+			Messages:   make([]MessageResponse, 0, len(messages)),
+			Page:       page,
+			PageSize:   pageSize,
+			Total:      total,
+			TotalPages: totalPages(total, pageSize),
+		}
+
+		for _, m := range messages {
+			response.Messages = append(response.Messages, toMessageResponse(m, userID))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// MarkConversationReadHandler отмечает сообщения переписки прочитанными
+// @Security BearerAuth
+// @Summary Прочитать переписку
+// @Description Отмечает все входящие сообщения переписки прочитанными
+// @Tags messages
+// @Produce json
+// @Param id path string true "ID переписки"
+// @Success 200 {object} MarkReadResponse
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 404 {object} problem.Problem "Переписка не найдена"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/conversations/{id}/read [post]
+func MarkConversationReadHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		conversationID := chi.URLParam(r, "id")
+		if conversationID == "" {
+			problem.Write(w, r, http.StatusBadRequest, "Conversation ID is required")
+			return
+		}
+
+		marked, err := db.MarkConversationRead(r.Context(), conversationID, userID)
+		if err != nil {
+			if errors.Is(err, database.ErrConversationNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Conversation not found")
+				return
+			}
+			log.Error(err, "failed to mark conversation as read")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(MarkReadResponse{Marked: marked}); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// GetUnreadCountHandler возвращает количество непрочитанных сообщений
+// @Security BearerAuth
+// @Summary Непрочитанные сообщения
+// @Description Возвращает общее количество непрочитанных сообщений во всех переписках пользователя
+// @Tags messages
+// @Produce json
+// @Success 200 {object} UnreadCountResponse
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/conversations/unread [get]
+func GetUnreadCountHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		unread, err := db.GetUnreadCount(r.Context(), userID)
+		if err != nil {
+			log.Error(err, "failed to get unread count")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(UnreadCountResponse{Unread: unread}); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// BlockUserHandler блокирует пользователя
+// @Security BearerAuth
+// @Summary Заблокировать пользователя
+// @Description Запрещает переписку с пользователем в обе стороны
+// @Tags messages
+// @Param username path string true "Имя пользователя"
+// @Success 204 "Пользователь заблокирован"
+// @Failure 400 {object} problem.Problem "Нельзя заблокировать себя"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 404 {object} problem.Problem "Пользователь не найден"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/users/{username}/block [post]
+func BlockUserHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		username := chi.URLParam(r, "username")
+		if username == "" {
+			problem.Write(w, r, http.StatusBadRequest, "Username is required")
+			return
+		}
+
+		if err := db.BlockUser(r.Context(), userID, username); err != nil {
+			switch {
+			case errors.Is(err, database.ErrUserNotFound):
+				problem.Write(w, r, http.StatusNotFound, "User not found")
+			case errors.Is(err, database.ErrCannotBlockSelf):
+				problem.Write(w, r, http.StatusBadRequest, "Cannot block yourself")
+			default:
+				log.Error(err, "failed to block user")
+				problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// UnblockUserHandler разблокирует пользователя
+// @Security BearerAuth
+// @Summary Разблокировать пользователя
+// @Description Снимает блокировку с пользователя
+// @Tags messages
+// @Param username path string true "Имя пользователя"
+// @Success 204 "Пользователь разблокирован"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 404 {object} problem.Problem "Пользователь не найден"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/users/{username}/block [delete]
+func UnblockUserHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		username := chi.URLParam(r, "username")
+		if username == "" {
+			problem.Write(w, r, http.StatusBadRequest, "Username is required")
+			return
+		}
+
+		if err := db.UnblockUser(r.Context(), userID, username); err != nil {
+			if errors.Is(err, database.ErrUserNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "User not found")
+				return
+			}
+			log.Error(err, "failed to unblock user")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func toMessageResponse(m *model.Message, userID string) MessageResponse {
+	return MessageResponse{
+		ID:             m.ID,
+		ConversationID: m.ConversationID,
+		SenderID:       m.SenderID,
+		Body:           m.Body,
+		CreatedAt:      m.CreatedAt,
+		ReadAt:         m.ReadAt,
+		IsMine:         m.SenderID == userID,
+	}
+}
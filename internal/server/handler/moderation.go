@@ -0,0 +1,628 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"vk-internship/internal/broker"
+	"vk-internship/internal/config"
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+	"vk-internship/internal/logger"
+	"vk-internship/internal/server/problem"
+	"vk-internship/internal/utils"
+)
+
+// ReportAdRequest представляет жалобу на объявление
+// @Description Причина жалобы и необязательный комментарий
+type ReportAdRequest struct {
+	Reason  string `json:"reason" validate:"required,oneof=scam prohibited spam offensive duplicate other"`
+	Comment string `json:"comment" validate:"omitempty,max=1024"`
+}
+
+// ReportAdResponse представляет принятую жалобу
+// @Description Жалоба на объявление
+type ReportAdResponse struct {
+	ID        string    `json:"id"`
+	AdID      string    `json:"ad_id"`
+	Reason    string    `json:"reason"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ResolveModerationCaseRequest представляет решение модератора
+// @Description hide - скрыть объявление, delete - удалить, dismiss - отклонить жалобы
+type ResolveModerationCaseRequest struct {
+	Action string `json:"action" validate:"required,oneof=hide delete dismiss"`
+	Note   string `json:"note" validate:"omitempty,max=1024"`
+}
+
+// RemoveReviewRequest представляет удаление отзыва модератором
+// @Description Причина удаления отзыва
+type RemoveReviewRequest struct {
+	Reason string `json:"reason" validate:"required,max=1024"`
+}
+
+// ModerationReportResponse представляет жалобу в деле модерации
+// @Description Жалоба пользователя
+type ModerationReportResponse struct {
+	ID               string    `json:"id"`
+	ReporterUsername string    `json:"reporter_username"`
+	Reason           string    `json:"reason"`
+	Comment          string    `json:"comment,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// ModerationActionResponse представляет запись журнала модерации
+// @Description Действие модератора. moderator_username отсутствует у автоматических действий и действий оператора
+type ModerationActionResponse struct {
+	ID                string    `json:"id"`
+	CaseID            *string   `json:"case_id,omitempty"`
+	AdID              *string   `json:"ad_id,omitempty"`
+	ReviewID          *string   `json:"review_id,omitempty"`
+	ModeratorUsername *string   `json:"moderator_username,omitempty"`
+	Action            string    `json:"action" enums:"claim,auto_hide,hide,delete,dismiss,remove_review"`
+	Note              string    `json:"note,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// ModerationAdResponse представляет объявление в деле модерации
+// @Description Объявление, на которое пожаловались
+type ModerationAdResponse struct {
+	ID             string    `json:"id"`
+	AuthorUsername string    `json:"author_username"`
+	Caption        string    `json:"caption"`
+	Description    string    `json:"description"`
+	ImageURL       string    `json:"image_url,omitempty"`
+	Price          float64   `json:"price"`
+	Status         string    `json:"status"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ModerationCaseResponse представляет дело модерации
+// @Description Жалобы на объявление. open - ждет модератора, claimed - взято модератором, resolved - решено. reasons - число жалоб по причинам. Поля объявления отсутствуют, если оно удалено
+type ModerationCaseResponse struct {
+	ID                string                     `json:"id"`
+	AdID              *string                    `json:"ad_id,omitempty"`
+	AdCaption         *string                    `json:"ad_caption,omitempty"`
+	AdStatus          *string                    `json:"ad_status,omitempty"`
+	AuthorUsername    *string                    `json:"author_username,omitempty"`
+	Status            string                     `json:"status"`
+	ReportsCount      int                        `json:"reports_count"`
+	Reasons           map[string]int             `json:"reasons"`
+	AutoHidden        bool                       `json:"auto_hidden"`
+	ModeratorUsername *string                    `json:"moderator_username,omitempty"`
+	ClaimedAt         *time.Time                 `json:"claimed_at,omitempty"`
+	Resolution        *string                    `json:"resolution,omitempty"`
+	Note              *string                    `json:"note,omitempty"`
+	ResolvedAt        *time.Time                 `json:"resolved_at,omitempty"`
+	CreatedAt         time.Time                  `json:"created_at"`
+	UpdatedAt         time.Time                  `json:"updated_at"`
+	Ad                *ModerationAdResponse      `json:"ad,omitempty"`
+	Reports           []ModerationReportResponse `json:"reports,omitempty"`
+	Actions           []ModerationActionResponse `json:"actions,omitempty"`
+}
+
+// ModerationCasesResponse представляет очередь модерации
+// @Description Дела модерации с пагинацией, с наибольшим числом жалоб первыми
+type ModerationCasesResponse struct {
+	Cases      []ModerationCaseResponse `json:"cases"`
+	Page       int                      `json:"page"`
+	PageSize   int                      `json:"page_size"`
+	Total      int                      `json:"total"`
+	TotalPages int                      `json:"total_pages"`
+}
+
+// ModerationActionsResponse представляет журнал модерации
+// @Description Действия модераторов с пагинацией, новые первыми
+type ModerationActionsResponse struct {
+	Actions    []ModerationActionResponse `json:"actions"`
+	Page       int                        `json:"page"`
+	PageSize   int                        `json:"page_size"`
+	Total      int                        `json:"total"`
+	TotalPages int                        `json:"total_pages"`
+}
+
+func toModerationActionResponse(action *model.ModerationAction) ModerationActionResponse {
+	return ModerationActionResponse{
+		ID:                action.ID,
+		CaseID:            action.CaseID,
+		AdID:              action.AdID,
+		ReviewID:          action.ReviewID,
+		ModeratorUsername: action.ModeratorUsername,
+		Action:            action.Action,
+		Note:              action.Note,
+		CreatedAt:         action.CreatedAt,
+	}
+}
+
+func toModerationCaseResponse(c *model.ModerationCase) ModerationCaseResponse {
+	response := ModerationCaseResponse{
+		ID:                c.ID,
+		AdID:              c.AdID,
+		AdCaption:         c.AdCaption,
+		AdStatus:          c.AdStatus,
+		AuthorUsername:    c.AuthorUsername,
+		Status:            c.Status,
+		ReportsCount:      c.ReportsCount,
+		Reasons:           c.Reasons,
+		AutoHidden:        c.AutoHidden,
+		ModeratorUsername: c.ModeratorUsername,
+		ClaimedAt:         c.ClaimedAt,
+		Resolution:        c.Resolution,
+		Note:              c.Note,
+		ResolvedAt:        c.ResolvedAt,
+		CreatedAt:         c.CreatedAt,
+		UpdatedAt:         c.UpdatedAt,
+	}
+
+	for _, report := range c.Reports {
+		response.Reports = append(response.Reports, ModerationReportResponse{
+			ID:               report.ID,
+			ReporterUsername: report.ReporterUsername,
+			Reason:           report.Reason,
+			Comment:          report.Comment,
+			CreatedAt:        report.CreatedAt,
+		})
+	}
+
+	for _, action := range c.Actions {
+		response.Actions = append(response.Actions, toModerationActionResponse(action))
+	}
+
+	return response
+}
+
+// publishAdChange publishes the broker event of an ad a moderation step hid,
+// restored or deleted.
+func publishAdChange(r *http.Request, log logger.Logger, events *broker.Broker, change *model.AdChange) {
+	if change == nil {
+		return
+	}
+
+	event := model.AdStatusEvent(change.From, change.To)
+	if event == "" {
+		return
+	}
+
+	detach(r, func(ctx context.Context) {
+		if err := events.Publish(ctx, broker.EventType(event), change.Ad); err != nil {
+			log.Warnf("failed to publish ad event", map[string]interface{}{"error": err.Error()})
+		}
+	})
+}
+
+// ReportAdHandler принимает жалобу на объявление
+// @Security BearerAuth
+// @Summary Пожаловаться на объявление
+// @Description Отправляет жалобу на активное объявление в очередь модерации. Пользователь может пожаловаться на объявление один раз, пока дело не решено. После настроенного числа жалоб от разных пользователей объявление скрывается до решения модератора
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path string true "ID объявления"
+// @Param request body ReportAdRequest true "Жалоба"
+// @Param Idempotency-Key header string false "Ключ идемпотентности"
+// @Success 201 {object} ReportAdResponse
+// @Failure 400 {object} problem.Problem "Неверный формат запроса или ошибки валидации"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 403 {object} problem.Problem "Свое объявление"
+// @Failure 404 {object} problem.Problem "Объявление не найдено"
+// @Failure 409 {object} problem.Problem "Жалоба уже отправлена"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/ads/{id}/report [post]
+func ReportAdHandler(moderationcfg *config.ModerationConfig, log logger.Logger, db database.Database, events *broker.Broker) http.HandlerFunc {
+	validate := utils.NewValidator()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		var req ReportAdRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Warnf("invalid request body", map[string]interface{}{"error": err.Error()})
+			problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		req.Comment = strings.TrimSpace(req.Comment)
+
+		if err := validate.Validate(req); err != nil {
+			problem.Validation(w, r, validate.FormatValidationErrors(err).Errors)
+			return
+		}
+
+		adID := chi.URLParam(r, "id")
+
+		report, change, err := db.ReportAd(r.Context(), &model.AdReport{
+			AdID:       &adID,
+			ReporterID: userID,
+			Reason:     req.Reason,
+			Comment:    req.Comment,
+		}, moderationcfg.AutoHideReports)
+		if err != nil {
+			switch {
+			case errors.Is(err, database.ErrAdNotFound):
+				problem.Write(w, r, http.StatusNotFound, "Ad not found")
+			case errors.Is(err, database.ErrCannotReportOwnAd):
+				problem.Write(w, r, http.StatusForbidden, "Cannot report your own ad")
+			case errors.Is(err, database.ErrAdAlreadyReported):
+				problem.Write(w, r, http.StatusConflict, "You have already reported this ad")
+			default:
+				log.Error(err, "failed to report ad")
+				problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			}
+			return
+		}
+
+		log.Infof("ad reported", map[string]interface{}{
+			"report_id":   report.ID,
+			"case_id":     report.CaseID,
+			"ad_id":       adID,
+			"reporter_id": userID,
+			"reason":      report.Reason,
+			"auto_hidden": change != nil,
+		})
+
+		publishAdChange(r, log, events, change)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(ReportAdResponse{
+			ID:        report.ID,
+			AdID:      adID,
+			Reason:    report.Reason,
+			Comment:   report.Comment,
+			CreatedAt: report.CreatedAt,
+		}); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// GetModerationCasesHandler возвращает очередь модерации
+// @Security BearerAuth
+// @Summary Очередь модерации
+// @Description Возвращает дела модерации. Без status - открытые и взятые в работу дела. Только для модераторов
+// @Tags moderation
+// @Produce json
+// @Param status query string false "Статус дела" Enums(open, claimed, resolved)
+// @Param page query int false "Номер страницы" default(1) minimum(1)
+// @Param page_size query int false "Количество элементов на странице" default(10) minimum(1) maximum(100)
+// @Success 200 {object} ModerationCasesResponse
+// @Failure 400 {object} problem.Problem "Неверные параметры запроса"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 403 {object} problem.Problem "Не модератор"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/moderation/cases [get]
+func GetModerationCasesHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		status := r.URL.Query().Get("status")
+		if status != "" && !slices.Contains(model.ModerationCaseStatuses, status) {
+			problem.Write(w, r, http.StatusBadRequest, "status must be one of open, claimed, resolved")
+			return
+		}
+
+		page, pageSize := parsePagination(r)
+
+		cases, total, err := db.GetModerationCases(r.Context(), status, page, pageSize)
+		if err != nil {
+			log.Error(err, "failed to get moderation cases")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		response := ModerationCasesResponse{
+			Cases:      make([]ModerationCaseResponse, 0, len(cases)),
+			Page:       page,
+			PageSize:   pageSize,
+			Total:      total,
+			TotalPages: totalPages(total, pageSize),
+		}
+		for _, c := range cases {
+			response.Cases = append(response.Cases, toModerationCaseResponse(c))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// GetModerationCaseHandler возвращает дело модерации
+// @Security BearerAuth
+// @Summary Дело модерации
+// @Description Возвращает дело с объявлением, всеми жалобами и действиями модераторов. Только для модераторов
+// @Tags moderation
+// @Produce json
+// @Param id path string true "ID дела"
+// @Success 200 {object} ModerationCaseResponse
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 403 {object} problem.Problem "Не модератор"
+// @Failure 404 {object} problem.Problem "Дело не найдено"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/moderation/cases/{id} [get]
+func GetModerationCaseHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		c, err := db.GetModerationCase(r.Context(), chi.URLParam(r, "id"))
+		if err != nil {
+			if errors.Is(err, database.ErrModerationCaseNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Moderation case not found")
+				return
+			}
+
+			log.Error(err, "failed to get moderation case")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		response := toModerationCaseResponse(c)
+
+		if c.AdID != nil {
+			ad, err := db.GetAd(r.Context(), *c.AdID)
+			if err != nil && !errors.Is(err, database.ErrAdNotFound) {
+				log.Error(err, "failed to get ad")
+				problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+				return
+			}
+
+			if ad != nil {
+				response.Ad = &ModerationAdResponse{
+					ID:             ad.ID,
+					AuthorUsername: ad.AuthorUsername,
+					Caption:        ad.Caption,
+					Description:    ad.Description,
+					ImageURL:       ad.ImageURL,
+					Price:          float64(ad.Price) / 100,
+					Status:         ad.Status,
+					CreatedAt:      ad.CreatedAt,
+					UpdatedAt:      ad.UpdatedAt,
+				}
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// ClaimModerationCaseHandler берет дело модерации в работу
+// @Security BearerAuth
+// @Summary Взять дело
+// @Description Назначает открытое дело текущему модератору, решить его сможет только он. Повторный запрос того же модератора ничего не меняет
+// @Tags moderation
+// @Produce json
+// @Param id path string true "ID дела"
+// @Success 200 {object} ModerationCaseResponse
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 403 {object} problem.Problem "Не модератор"
+// @Failure 404 {object} problem.Problem "Дело не найдено"
+// @Failure 409 {object} problem.Problem "Дело взято другим модератором или решено"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/moderation/cases/{id}/claim [post]
+func ClaimModerationCaseHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, _ := r.Context().Value("userID").(string)
+
+		c, err := db.ClaimModerationCase(r.Context(), chi.URLParam(r, "id"), userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, database.ErrModerationCaseNotFound):
+				problem.Write(w, r, http.StatusNotFound, "Moderation case not found")
+			case errors.Is(err, database.ErrModerationCaseClaimed):
+				problem.Write(w, r, http.StatusConflict, "Moderation case is claimed by another moderator")
+			case errors.Is(err, database.ErrModerationCaseResolved):
+				problem.Write(w, r, http.StatusConflict, "Moderation case is already resolved")
+			default:
+				log.Error(err, "failed to claim moderation case")
+				problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(toModerationCaseResponse(c)); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// ResolveModerationCaseHandler решает дело модерации
+// @Security BearerAuth
+// @Summary Решить дело
+// @Description Решает взятое текущим модератором дело. hide скрывает объявление и отклоняет открытые предложения цены, delete удаляет объявление, dismiss отклоняет жалобы и возвращает автоматически скрытое объявление в ленту
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path string true "ID дела"
+// @Param request body ResolveModerationCaseRequest true "Решение"
+// @Param Idempotency-Key header string false "Ключ идемпотентности"
+// @Success 200 {object} ModerationCaseResponse
+// @Failure 400 {object} problem.Problem "Неверный формат запроса или ошибки валидации"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 403 {object} problem.Problem "Не модератор"
+// @Failure 404 {object} problem.Problem "Дело не найдено"
+// @Failure 409 {object} problem.Problem "Дело не взято текущим модератором или уже решено"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/moderation/cases/{id}/resolve [post]
+func ResolveModerationCaseHandler(log logger.Logger, db database.Database, events *broker.Broker) http.HandlerFunc {
+	validate := utils.NewValidator()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, _ := r.Context().Value("userID").(string)
+
+		var req ResolveModerationCaseRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Warnf("invalid request body", map[string]interface{}{"error": err.Error()})
+			problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		req.Note = strings.TrimSpace(req.Note)
+
+		if err := validate.Validate(req); err != nil {
+			problem.Validation(w, r, validate.FormatValidationErrors(err).Errors)
+			return
+		}
+
+		c, change, err := db.ResolveModerationCase(r.Context(), chi.URLParam(r, "id"), userID, req.Action, req.Note)
+		if err != nil {
+			switch {
+			case errors.Is(err, database.ErrModerationCaseNotFound):
+				problem.Write(w, r, http.StatusNotFound, "Moderation case not found")
+			case errors.Is(err, database.ErrModerationCaseNotClaimed):
+				problem.Write(w, r, http.StatusConflict, "Claim the moderation case before resolving it")
+			case errors.Is(err, database.ErrModerationCaseResolved):
+				problem.Write(w, r, http.StatusConflict, "Moderation case is already resolved")
+			default:
+				log.Error(err, "failed to resolve moderation case")
+				problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			}
+			return
+		}
+
+		log.Infof("moderation case resolved", map[string]interface{}{
+			"case_id":      c.ID,
+			"ad_id":        c.AdID,
+			"moderator_id": userID,
+			"resolution":   req.Action,
+		})
+
+		publishAdChange(r, log, events, change)
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(toModerationCaseResponse(c)); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// GetModerationActionsHandler возвращает журнал модерации
+// @Security BearerAuth
+// @Summary Журнал модерации
+// @Description Возвращает действия модераторов и автоматические действия, новые первыми. Только для модераторов
+// @Tags moderation
+// @Produce json
+// @Param moderator query string false "Имя модератора"
+// @Param page query int false "Номер страницы" default(1) minimum(1)
+// @Param page_size query int false "Количество элементов на странице" default(10) minimum(1) maximum(100)
+// @Success 200 {object} ModerationActionsResponse
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 403 {object} problem.Problem "Не модератор"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/moderation/actions [get]
+func GetModerationActionsHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		page, pageSize := parsePagination(r)
+
+		actions, total, err := db.GetModerationActions(r.Context(), r.URL.Query().Get("moderator"), page, pageSize)
+		if err != nil {
+			log.Error(err, "failed to get moderation actions")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		response := ModerationActionsResponse{
+			Actions:    make([]ModerationActionResponse, 0, len(actions)),
+			Page:       page,
+			PageSize:   pageSize,
+			Total:      total,
+			TotalPages: totalPages(total, pageSize),
+		}
+		for _, action := range actions {
+			response.Actions = append(response.Actions, toModerationActionResponse(action))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// RemoveReviewHandler удаляет отзыв
+// @Security BearerAuth
+// @Summary Удалить отзыв
+// @Description Скрывает отзыв и исключает его из рейтинга продавца. Удаление попадает в журнал модерации. Только для модераторов
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path string true "ID отзыва"
+// @Param request body RemoveReviewRequest true "Причина"
+// @Success 200 {object} ReviewResponse
+// @Failure 400 {object} problem.Problem "Неверный формат запроса или ошибки валидации"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 403 {object} problem.Problem "Не модератор"
+// @Failure 404 {object} problem.Problem "Отзыв не найден"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/moderation/reviews/{id}/remove [post]
+func RemoveReviewHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	validate := utils.NewValidator()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, _ := r.Context().Value("userID").(string)
+
+		var req RemoveReviewRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Warnf("invalid request body", map[string]interface{}{"error": err.Error()})
+			problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		req.Reason = strings.TrimSpace(req.Reason)
+
+		if err := validate.Validate(req); err != nil {
+			problem.Validation(w, r, validate.FormatValidationErrors(err).Errors)
+			return
+		}
+
+		review, err := db.RemoveReview(r.Context(), chi.URLParam(r, "id"), userID, req.Reason)
+		if err != nil {
+			if errors.Is(err, database.ErrReviewNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Review not found")
+				return
+			}
+
+			log.Error(err, "failed to remove review")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		log.Infof("review removed", map[string]interface{}{
+			"review_id":    review.ID,
+			"moderator_id": userID,
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(toReviewResponse(review)); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
@@ -0,0 +1,454 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"vk-internship/internal/broker"
+	"vk-internship/internal/config"
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+	"vk-internship/internal/logger"
+	zerologger "vk-internship/internal/logger/zerolog"
+)
+
+// moderationDB reports and resolves cases the way PostgresDB does for one ad,
+// the other methods panic through the nil embedded interface.
+type moderationDB struct {
+	database.Database
+	ad *model.Advertisement
+	c  *model.ModerationCase
+	// reporters are the users who reported the ad in the open case
+	reporters map[string]bool
+	// offersRejected is set when the open offers of the ad are closed
+	offersRejected bool
+}
+
+func (db *moderationDB) ReportAd(_ context.Context, report *model.AdReport, autoHideReports int) (*model.AdReport, *model.AdChange, error) {
+	if db.ad == nil || db.ad.ID != *report.AdID || db.ad.Status != model.AdStatusActive {
+		return nil, nil, database.ErrAdNotFound
+	}
+	if db.ad.AuthorID == report.ReporterID {
+		return nil, nil, database.ErrCannotReportOwnAd
+	}
+
+	if db.c == nil || db.c.Status == model.ModerationCaseResolved {
+		db.c = &model.ModerationCase{ID: "case-1", AdID: &db.ad.ID, Status: model.ModerationCaseOpen}
+		db.reporters = map[string]bool{}
+	}
+	if db.reporters[report.ReporterID] {
+		return nil, nil, database.ErrAdAlreadyReported
+	}
+	db.reporters[report.ReporterID] = true
+	db.c.ReportsCount++
+
+	created := *report
+	created.ID = "report-" + report.ReporterID
+	created.CaseID = db.c.ID
+
+	var change *model.AdChange
+	if autoHideReports > 0 && db.c.ReportsCount >= autoHideReports {
+		db.ad.Status = model.AdStatusHidden
+		db.c.AutoHidden = true
+
+		ad := *db.ad
+		change = &model.AdChange{Ad: &ad, From: model.AdStatusActive, To: model.AdStatusHidden}
+	}
+
+	return &created, change, nil
+}
+
+func (db *moderationDB) ResolveModerationCase(_ context.Context, id, moderatorID, resolution, note string) (*model.ModerationCase, *model.AdChange, error) {
+	if db.c == nil || db.c.ID != id {
+		return nil, nil, database.ErrModerationCaseNotFound
+	}
+	if db.c.Status == model.ModerationCaseResolved {
+		return nil, nil, database.ErrModerationCaseResolved
+	}
+	if db.c.Status != model.ModerationCaseClaimed || db.c.ModeratorID == nil || *db.c.ModeratorID != moderatorID {
+		return nil, nil, database.ErrModerationCaseNotClaimed
+	}
+
+	var change *model.AdChange
+	if db.ad != nil {
+		from := db.ad.Status
+
+		switch {
+		case resolution == model.ModerationResolutionHide:
+			db.offersRejected = true
+			if from != model.AdStatusHidden {
+				db.ad.Status = model.AdStatusHidden
+				ad := *db.ad
+				change = &model.AdChange{Ad: &ad, From: from, To: model.AdStatusHidden}
+			}
+		case resolution == model.ModerationResolutionDelete:
+			db.offersRejected = true
+			change = &model.AdChange{Ad: db.ad, From: from}
+			db.ad = nil
+		case resolution == model.ModerationResolutionDismiss && db.c.AutoHidden && from == model.AdStatusHidden:
+			db.ad.Status = model.AdStatusActive
+			ad := *db.ad
+			change = &model.AdChange{Ad: &ad, From: from, To: model.AdStatusActive}
+		}
+	}
+
+	db.c.Status = model.ModerationCaseResolved
+	db.c.Resolution = &resolution
+	if note != "" {
+		db.c.Note = &note
+	}
+
+	c := *db.c
+	return &c, change, nil
+}
+
+// nextEvent waits for the event a handler publishes in the background.
+func nextEvent(sub *broker.Subscription, wait time.Duration) (broker.Event, bool) {
+	select {
+	case event := <-sub.Events:
+		return event, true
+	case <-time.After(wait):
+		return broker.Event{}, false
+	}
+}
+
+func newModerationTest(t *testing.T) (logger.Logger, *broker.Broker, *broker.Subscription) {
+	t.Helper()
+
+	log := zerologger.NewWriter(&config.LoggerConfig{Level: "disabled"}, io.Discard)
+	events := broker.New(&config.BrokerConfig{HistorySize: 10, SubscriberBuffer: 10}, nil, log)
+	sub, _ := events.Subscribe(0)
+	t.Cleanup(events.Close)
+
+	return log, events, sub
+}
+
+func TestReportAdHandlerAutoHide(t *testing.T) {
+	log, events, sub := newModerationTest(t)
+
+	db := &moderationDB{ad: &model.Advertisement{ID: "ad-1", AuthorID: "author", Status: model.AdStatusActive}}
+	moderationcfg := &config.ModerationConfig{AutoHideReports: 2}
+
+	router := chi.NewRouter()
+	router.Post("/api/v1/ads/{id}/report", ReportAdHandler(moderationcfg, log, db, events))
+
+	// the steps run in order against the same ad
+	steps := []struct {
+		name       string
+		userID     string
+		body       string
+		wantStatus int
+		wantAd     string
+		wantCount  int
+		wantHidden bool
+		wantEvent  broker.EventType
+	}{
+		{
+			name:       "first report keeps the ad",
+			userID:     "reporter-1",
+			body:       `{"reason": "scam", "comment": "  asks for prepayment  "}`,
+			wantStatus: http.StatusCreated,
+			wantAd:     model.AdStatusActive,
+			wantCount:  1,
+		},
+		{
+			name:       "same user cannot report twice",
+			userID:     "reporter-1",
+			body:       `{"reason": "spam"}`,
+			wantStatus: http.StatusConflict,
+			wantAd:     model.AdStatusActive,
+			wantCount:  1,
+		},
+		{
+			name:       "author cannot report own ad",
+			userID:     "author",
+			body:       `{"reason": "other"}`,
+			wantStatus: http.StatusForbidden,
+			wantAd:     model.AdStatusActive,
+			wantCount:  1,
+		},
+		{
+			name:       "unknown reason",
+			userID:     "reporter-2",
+			body:       `{"reason": "boring"}`,
+			wantStatus: http.StatusBadRequest,
+			wantAd:     model.AdStatusActive,
+			wantCount:  1,
+		},
+		{
+			name:       "anonymous request",
+			body:       `{"reason": "spam"}`,
+			wantStatus: http.StatusUnauthorized,
+			wantAd:     model.AdStatusActive,
+			wantCount:  1,
+		},
+		{
+			name:       "report reaching the threshold hides the ad",
+			userID:     "reporter-2",
+			body:       `{"reason": "scam"}`,
+			wantStatus: http.StatusCreated,
+			wantAd:     model.AdStatusHidden,
+			wantCount:  2,
+			wantHidden: true,
+			wantEvent:  broker.AdDeleted,
+		},
+		{
+			name:       "hidden ad cannot be reported",
+			userID:     "reporter-3",
+			body:       `{"reason": "scam"}`,
+			wantStatus: http.StatusNotFound,
+			wantAd:     model.AdStatusHidden,
+			wantCount:  2,
+			wantHidden: true,
+		},
+	}
+
+	for _, step := range steps {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/ads/ad-1/report", strings.NewReader(step.body))
+		if step.userID != "" {
+			r = r.WithContext(context.WithValue(r.Context(), "userID", step.userID))
+		}
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, r)
+
+		if rec.Code != step.wantStatus {
+			t.Fatalf("%s: status = %d, want %d: %s", step.name, rec.Code, step.wantStatus, rec.Body.String())
+		}
+		if db.ad.Status != step.wantAd {
+			t.Errorf("%s: ad status = %q, want %q", step.name, db.ad.Status, step.wantAd)
+		}
+		if db.c.ReportsCount != step.wantCount || db.c.AutoHidden != step.wantHidden {
+			t.Errorf("%s: case = %d reports, auto hidden %v, want %d, %v", step.name, db.c.ReportsCount, db.c.AutoHidden, step.wantCount, step.wantHidden)
+		}
+
+		if step.wantStatus == http.StatusCreated {
+			var response ReportAdResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			if response.ID != "report-"+step.userID || response.AdID != "ad-1" {
+				t.Errorf("%s: response = %+v", step.name, response)
+			}
+		}
+
+		if step.wantEvent == "" {
+			if event, ok := nextEvent(sub, 50*time.Millisecond); ok {
+				t.Errorf("%s: unexpected event %s", step.name, event.Type)
+			}
+			continue
+		}
+
+		event, ok := nextEvent(sub, time.Second)
+		if !ok {
+			t.Fatalf("%s: no %s event", step.name, step.wantEvent)
+		}
+		if event.Type != step.wantEvent || event.AdID != "ad-1" {
+			t.Errorf("%s: event = %s for %q, want %s for ad-1", step.name, event.Type, event.AdID, step.wantEvent)
+		}
+	}
+}
+
+func TestResolveModerationCaseHandler(t *testing.T) {
+	const moderator = "moderator-1"
+
+	tests := []struct {
+		name       string
+		status     string
+		autoHidden bool
+		adStatus   string
+		userID     string
+		body       string
+		wantStatus int
+		// wantAd is the status the ad is left in, "" if it was deleted
+		wantAd         string
+		wantCase       string
+		wantRejected   bool
+		wantEvent      broker.EventType
+		wantResolution string
+	}{
+		{
+			name:           "dismiss restores the auto-hidden ad",
+			status:         model.ModerationCaseClaimed,
+			autoHidden:     true,
+			adStatus:       model.AdStatusHidden,
+			userID:         moderator,
+			body:           `{"action": "dismiss", "note": "reports are unfounded"}`,
+			wantStatus:     http.StatusOK,
+			wantAd:         model.AdStatusActive,
+			wantCase:       model.ModerationCaseResolved,
+			wantEvent:      broker.AdCreated,
+			wantResolution: model.ModerationResolutionDismiss,
+		},
+		{
+			name:           "hide keeps the auto-hidden ad hidden and closes its offers",
+			status:         model.ModerationCaseClaimed,
+			autoHidden:     true,
+			adStatus:       model.AdStatusHidden,
+			userID:         moderator,
+			body:           `{"action": "hide"}`,
+			wantStatus:     http.StatusOK,
+			wantAd:         model.AdStatusHidden,
+			wantCase:       model.ModerationCaseResolved,
+			wantRejected:   true,
+			wantResolution: model.ModerationResolutionHide,
+		},
+		{
+			name:           "hide takes an active ad off the feed",
+			status:         model.ModerationCaseClaimed,
+			adStatus:       model.AdStatusActive,
+			userID:         moderator,
+			body:           `{"action": "hide"}`,
+			wantStatus:     http.StatusOK,
+			wantAd:         model.AdStatusHidden,
+			wantCase:       model.ModerationCaseResolved,
+			wantRejected:   true,
+			wantEvent:      broker.AdDeleted,
+			wantResolution: model.ModerationResolutionHide,
+		},
+		{
+			name:           "delete removes the auto-hidden ad",
+			status:         model.ModerationCaseClaimed,
+			autoHidden:     true,
+			adStatus:       model.AdStatusHidden,
+			userID:         moderator,
+			body:           `{"action": "delete"}`,
+			wantStatus:     http.StatusOK,
+			wantCase:       model.ModerationCaseResolved,
+			wantRejected:   true,
+			wantResolution: model.ModerationResolutionDelete,
+		},
+		{
+			name:           "dismiss leaves an ad the author hid",
+			status:         model.ModerationCaseClaimed,
+			adStatus:       model.AdStatusInactive,
+			userID:         moderator,
+			body:           `{"action": "dismiss"}`,
+			wantStatus:     http.StatusOK,
+			wantAd:         model.AdStatusInactive,
+			wantCase:       model.ModerationCaseResolved,
+			wantResolution: model.ModerationResolutionDismiss,
+		},
+		{
+			name:       "case not claimed",
+			status:     model.ModerationCaseOpen,
+			autoHidden: true,
+			adStatus:   model.AdStatusHidden,
+			userID:     moderator,
+			body:       `{"action": "dismiss"}`,
+			wantStatus: http.StatusConflict,
+			wantAd:     model.AdStatusHidden,
+			wantCase:   model.ModerationCaseOpen,
+		},
+		{
+			name:       "case claimed by another moderator",
+			status:     model.ModerationCaseClaimed,
+			autoHidden: true,
+			adStatus:   model.AdStatusHidden,
+			userID:     "moderator-2",
+			body:       `{"action": "dismiss"}`,
+			wantStatus: http.StatusConflict,
+			wantAd:     model.AdStatusHidden,
+			wantCase:   model.ModerationCaseClaimed,
+		},
+		{
+			name:       "case already resolved",
+			status:     model.ModerationCaseResolved,
+			adStatus:   model.AdStatusActive,
+			userID:     moderator,
+			body:       `{"action": "hide"}`,
+			wantStatus: http.StatusConflict,
+			wantAd:     model.AdStatusActive,
+			wantCase:   model.ModerationCaseResolved,
+		},
+		{
+			name:       "unknown action",
+			status:     model.ModerationCaseClaimed,
+			autoHidden: true,
+			adStatus:   model.AdStatusHidden,
+			userID:     moderator,
+			body:       `{"action": "ban"}`,
+			wantStatus: http.StatusBadRequest,
+			wantAd:     model.AdStatusHidden,
+			wantCase:   model.ModerationCaseClaimed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, events, sub := newModerationTest(t)
+
+			holder := moderator
+			db := &moderationDB{
+				ad: &model.Advertisement{ID: "ad-1", AuthorID: "author", Status: tt.adStatus},
+				c: &model.ModerationCase{
+					ID:           "case-1",
+					Status:       tt.status,
+					ReportsCount: 2,
+					AutoHidden:   tt.autoHidden,
+					ModeratorID:  &holder,
+				},
+			}
+			db.c.AdID = &db.ad.ID
+
+			router := chi.NewRouter()
+			router.Post("/api/v1/moderation/cases/{id}/resolve", ResolveModerationCaseHandler(log, db, events))
+
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/moderation/cases/case-1/resolve", strings.NewReader(tt.body))
+			r = r.WithContext(context.WithValue(r.Context(), "userID", tt.userID))
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+
+			switch {
+			case tt.wantAd == "" && db.ad != nil:
+				t.Errorf("ad = %+v, want deleted", db.ad)
+			case tt.wantAd != "" && (db.ad == nil || db.ad.Status != tt.wantAd):
+				t.Errorf("ad = %+v, want status %q", db.ad, tt.wantAd)
+			}
+			if db.c.Status != tt.wantCase {
+				t.Errorf("case status = %q, want %q", db.c.Status, tt.wantCase)
+			}
+			if db.offersRejected != tt.wantRejected {
+				t.Errorf("offers rejected = %v, want %v", db.offersRejected, tt.wantRejected)
+			}
+
+			if tt.wantStatus == http.StatusOK {
+				var response ModerationCaseResponse
+				if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+					t.Fatal(err)
+				}
+				if response.Status != model.ModerationCaseResolved || response.Resolution == nil || *response.Resolution != tt.wantResolution {
+					t.Errorf("response = %+v, want resolved with %q", response, tt.wantResolution)
+				}
+			}
+
+			if tt.wantEvent == "" {
+				if event, ok := nextEvent(sub, 50*time.Millisecond); ok {
+					t.Errorf("unexpected event %s", event.Type)
+				}
+				return
+			}
+
+			event, ok := nextEvent(sub, time.Second)
+			if !ok {
+				t.Fatalf("no %s event", tt.wantEvent)
+			}
+			if event.Type != tt.wantEvent || event.AdID != "ad-1" {
+				t.Errorf("event = %s for %q, want %s for ad-1", event.Type, event.AdID, tt.wantEvent)
+			}
+		})
+	}
+}
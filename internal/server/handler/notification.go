@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"vk-internship/internal/database"
+	"vk-internship/internal/logger"
+	"vk-internship/internal/server/problem"
+)
+
+// NotificationResponse представляет уведомление
+// @Description Уведомление пользователя. Содержимое payload зависит от type
+type NotificationResponse struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at"`
+	ReadAt    *time.Time      `json:"read_at,omitempty"`
+}
+
+// NotificationsResponse представляет список уведомлений
+// @Description Уведомления пользователя (от новых к старым) с пагинацией
+type NotificationsResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	Unread        int                    `json:"unread"`
+	Page          int                    `json:"page"`
+	PageSize      int                    `json:"page_size"`
+	Total         int                    `json:"total"`
+	TotalPages    int                    `json:"total_pages"`
+}
+
+// GetNotificationsHandler возвращает уведомления пользователя
+// @Security BearerAuth
+// @Summary Список уведомлений
+// @Description Возвращает уведомления текущего пользователя и общее количество непрочитанных
+// @Tags notifications
+// @Produce json
+// @Param unread_only query bool false "Только непрочитанные"
+// @Param page query int false "Номер страницы" default(1) minimum(1)
+// @Param page_size query int false "Количество элементов на странице" default(10) minimum(1) maximum(100)
+// @Success 200 {object} NotificationsResponse
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/me/notifications [get]
+func GetNotificationsHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		page, pageSize := parsePagination(r)
+		unreadOnly := r.URL.Query().Get("unread_only") == "true"
+
+		notifications, total, err := db.GetNotifications(r.Context(), userID, unreadOnly, page, pageSize)
+		if err != nil {
+			log.Error(err, "failed to get notifications")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		unread, err := db.GetUnreadNotificationsCount(r.Context(), userID)
+		if err != nil {
+			log.Error(err, "failed to get unread notifications count")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		response := NotificationsResponse{
+			Notifications: make([]NotificationResponse, 0, len(notifications)),
+			Unread:        unread,
+			Page:          page,
+			PageSize:      pageSize,
+			Total:         total,
+			TotalPages:    totalPages(total, pageSize),
+		}
+
+		for _, n := range notifications {
+			response.Notifications = append(response.Notifications, NotificationResponse{
+				ID:        n.ID,
+				Type:      n.Type,
+				Payload:   n.Payload,
+				CreatedAt: n.CreatedAt,
+				ReadAt:    n.ReadAt,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// MarkNotificationReadHandler отмечает уведомление прочитанным
+// @Security BearerAuth
+// @Summary Прочитать уведомление
+// @Description Отмечает уведомление прочитанным
+// @Tags notifications
+// @Param id path string true "ID уведомления"
+// @Success 204 "Уведомление отмечено прочитанным"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 404 {object} problem.Problem "Уведомление не найдено"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/me/notifications/{id}/read [post]
+func MarkNotificationReadHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		if err := db.MarkNotificationRead(r.Context(), chi.URLParam(r, "id"), userID); err != nil {
+			if errors.Is(err, database.ErrNotificationNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Notification not found")
+				return
+			}
+			log.Error(err, "failed to mark notification as read")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// MarkAllNotificationsReadHandler отмечает все уведомления прочитанными
+// @Security BearerAuth
+// @Summary Прочитать все уведомления
+// @Description Отмечает все непрочитанные уведомления пользователя прочитанными
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} MarkReadResponse
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/me/notifications/read [post]
+func MarkAllNotificationsReadHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		marked, err := db.MarkAllNotificationsRead(r.Context(), userID)
+		if err != nil {
+			log.Error(err, "failed to mark notifications as read")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(MarkReadResponse{Marked: marked}); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
@@ -0,0 +1,390 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"vk-internship/internal/config"
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+	"vk-internship/internal/logger"
+	"vk-internship/internal/server/problem"
+	"vk-internship/internal/utils"
+)
+
+// OfferRequest представляет предложение цены
+// @Description Предлагаемая цена в рублях
+type OfferRequest struct {
+	Amount float64 `json:"amount" validate:"required,gt=0"`
+}
+
+// OfferResponse представляет предложение цены
+// @Description Предложение цены по объявлению. pending - ждет ответа продавца, countered - продавец предложил свою цену (counter_amount) и ждет ответа покупателя, accepted, rejected и expired - предложение закрыто. awaiting_you - ответить должен текущий пользователь
+type OfferResponse struct {
+	ID             string    `json:"id"`
+	AdID           *string   `json:"ad_id,omitempty"`
+	AdCaption      *string   `json:"ad_caption,omitempty"`
+	BuyerUsername  string    `json:"buyer_username"`
+	SellerUsername string    `json:"seller_username"`
+	Amount         float64   `json:"amount"`
+	CounterAmount  *float64  `json:"counter_amount,omitempty"`
+	Status         string    `json:"status"`
+	AwaitingYou    bool      `json:"awaiting_you"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// OffersResponse представляет список предложений цены
+// @Description Предложения цены с пагинацией, недавно измененные первыми
+type OffersResponse struct {
+	Offers     []OfferResponse `json:"offers"`
+	Page       int             `json:"page"`
+	PageSize   int             `json:"page_size"`
+	Total      int             `json:"total"`
+	TotalPages int             `json:"total_pages"`
+}
+
+func toOfferResponse(offer *model.Offer, userID string) OfferResponse {
+	response := OfferResponse{
+		ID:             offer.ID,
+		AdID:           offer.AdID,
+		AdCaption:      offer.AdCaption,
+		BuyerUsername:  offer.BuyerUsername,
+		SellerUsername: offer.SellerUsername,
+		Amount:         float64(offer.Amount) / 100,
+		Status:         offer.Status,
+		AwaitingYou:    model.NextOfferStatus(offer.Status, model.OfferActionAccept, offer.SellerID == userID) != "",
+		ExpiresAt:      offer.ExpiresAt,
+		CreatedAt:      offer.CreatedAt,
+		UpdatedAt:      offer.UpdatedAt,
+	}
+
+	if offer.CounterAmount != nil {
+		counter := float64(*offer.CounterAmount) / 100
+		response.CounterAmount = &counter
+	}
+
+	return response
+}
+
+// decodeOfferAmount reads an OfferRequest and returns the amount in kopecks,
+// writing the problem if the request is invalid.
+func decodeOfferAmount(w http.ResponseWriter, r *http.Request, log logger.Logger, validate *utils.Validator) (int, bool) {
+	var req OfferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warnf("invalid request body", map[string]interface{}{"error": err.Error()})
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
+		return 0, false
+	}
+
+	if err := validate.Validate(req); err != nil {
+		problem.Validation(w, r, validate.FormatValidationErrors(err).Errors)
+		return 0, false
+	}
+
+	amount := int(req.Amount * 100)
+	if amount < 1 {
+		problem.Write(w, r, http.StatusBadRequest, "amount must be at least 0.01")
+		return 0, false
+	}
+
+	return amount, true
+}
+
+// CreateOfferHandler создает предложение цены
+// @Security BearerAuth
+// @Summary Предложить цену
+// @Description Предлагает продавцу свою цену за объявление. У покупателя может быть одно открытое предложение по объявлению. Продавец должен ответить до expires_at, иначе предложение истекает. Продавец получает уведомление
+// @Tags offers
+// @Accept json
+// @Produce json
+// @Param id path string true "ID объявления"
+// @Param request body OfferRequest true "Предложение"
+// @Param Idempotency-Key header string false "Ключ идемпотентности"
+// @Success 201 {object} OfferResponse
+// @Failure 400 {object} problem.Problem "Неверный формат запроса или ошибки валидации"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 403 {object} problem.Problem "Свое объявление или пользователь заблокирован"
+// @Failure 404 {object} problem.Problem "Объявление не найдено"
+// @Failure 409 {object} problem.Problem "Уже есть открытое предложение"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/ads/{id}/offers [post]
+func CreateOfferHandler(offercfg *config.OfferConfig, log logger.Logger, db database.Database) http.HandlerFunc {
+	validate := utils.NewValidator()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		amount, ok := decodeOfferAmount(w, r, log, validate)
+		if !ok {
+			return
+		}
+
+		adID := chi.URLParam(r, "id")
+
+		offer, err := db.CreateOffer(r.Context(), &model.Offer{
+			AdID:      &adID,
+			BuyerID:   userID,
+			Amount:    amount,
+			ExpiresAt: time.Now().Add(offercfg.TTL),
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, database.ErrAdNotFound):
+				problem.Write(w, r, http.StatusNotFound, "Ad not found")
+			case errors.Is(err, database.ErrCannotOfferOwnAd):
+				problem.Write(w, r, http.StatusForbidden, "Cannot make an offer on your own ad")
+			case errors.Is(err, database.ErrUserBlocked):
+				problem.Write(w, r, http.StatusForbidden, "User is blocked")
+			case errors.Is(err, database.ErrOfferExists):
+				problem.Write(w, r, http.StatusConflict, "You already have an open offer for this ad")
+			default:
+				log.Error(err, "failed to create offer")
+				problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			}
+			return
+		}
+
+		log.Infof("offer created", map[string]interface{}{
+			"offer_id":  offer.ID,
+			"ad_id":     adID,
+			"buyer_id":  userID,
+			"seller_id": offer.SellerID,
+			"amount":    offer.Amount,
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(toOfferResponse(offer, userID)); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// GetOffersHandler возвращает предложения цены пользователя
+// @Security BearerAuth
+// @Summary Список предложений цены
+// @Description Возвращает предложения, сделанные пользователем (role=buyer), полученные им (role=seller) или все
+// @Tags offers
+// @Produce json
+// @Param role query string false "Роль пользователя в предложении" Enums(buyer, seller)
+// @Param status query string false "Статус предложения" Enums(pending, countered, accepted, rejected, expired)
+// @Param page query int false "Номер страницы" default(1) minimum(1)
+// @Param page_size query int false "Количество элементов на странице" default(10) minimum(1) maximum(100)
+// @Success 200 {object} OffersResponse
+// @Failure 400 {object} problem.Problem "Неверные параметры запроса"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/me/offers [get]
+func GetOffersHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		query := r.URL.Query()
+
+		role := query.Get("role")
+		if role != "" && role != model.OfferRoleBuyer && role != model.OfferRoleSeller {
+			problem.Write(w, r, http.StatusBadRequest, "role must be buyer or seller")
+			return
+		}
+
+		status := query.Get("status")
+		if status != "" && !slices.Contains(model.OfferStatuses, status) {
+			problem.Write(w, r, http.StatusBadRequest, "status must be one of pending, countered, accepted, rejected, expired")
+			return
+		}
+
+		page, pageSize := parsePagination(r)
+
+		offers, total, err := db.GetOffers(r.Context(), userID, role, status, page, pageSize)
+		if err != nil {
+			log.Error(err, "failed to get offers")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		response := OffersResponse{
+			Offers:     make([]OfferResponse, 0, len(offers)),
+			Page:       page,
+			PageSize:   pageSize,
+			Total:      total,
+			TotalPages: totalPages(total, pageSize),
+		}
+		for _, offer := range offers {
+			response.Offers = append(response.Offers, toOfferResponse(offer, userID))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// GetOfferHandler возвращает предложение цены
+// @Security BearerAuth
+// @Summary Предложение цены
+// @Description Возвращает предложение, в котором пользователь покупатель или продавец
+// @Tags offers
+// @Produce json
+// @Param id path string true "ID предложения"
+// @Success 200 {object} OfferResponse
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 404 {object} problem.Problem "Предложение не найдено"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/offers/{id} [get]
+func GetOfferHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		offer, err := db.GetOffer(r.Context(), chi.URLParam(r, "id"), userID)
+		if err != nil {
+			if errors.Is(err, database.ErrOfferNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Offer not found")
+				return
+			}
+
+			log.Error(err, "failed to get offer")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(toOfferResponse(offer, userID)); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// AcceptOfferHandler принимает предложение цены
+// @Security BearerAuth
+// @Summary Принять предложение
+// @Description Продавец принимает предложение покупателя (pending), покупатель - встречную цену продавца (countered). Другая сторона получает уведомление
+// @Tags offers
+// @Produce json
+// @Param id path string true "ID предложения"
+// @Success 200 {object} OfferResponse
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 404 {object} problem.Problem "Предложение не найдено"
+// @Failure 409 {object} problem.Problem "Предложение истекло, закрыто или ждет ответа другой стороны"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/offers/{id}/accept [post]
+func AcceptOfferHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		respondToOffer(w, r, log, db, model.OfferActionAccept, 0, time.Time{})
+	}
+}
+
+// RejectOfferHandler отклоняет предложение цены
+// @Security BearerAuth
+// @Summary Отклонить предложение
+// @Description Продавец отклоняет предложение покупателя (pending), покупатель - встречную цену продавца (countered). Другая сторона получает уведомление
+// @Tags offers
+// @Produce json
+// @Param id path string true "ID предложения"
+// @Success 200 {object} OfferResponse
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 404 {object} problem.Problem "Предложение не найдено"
+// @Failure 409 {object} problem.Problem "Предложение истекло, закрыто или ждет ответа другой стороны"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/offers/{id}/reject [post]
+func RejectOfferHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		respondToOffer(w, r, log, db, model.OfferActionReject, 0, time.Time{})
+	}
+}
+
+// CounterOfferHandler предлагает встречную цену
+// @Security BearerAuth
+// @Summary Предложить встречную цену
+// @Description Продавец отвечает на предложение покупателя (pending) своей ценой. Срок ответа продлевается, покупатель получает уведомление и может принять или отклонить цену
+// @Tags offers
+// @Accept json
+// @Produce json
+// @Param id path string true "ID предложения"
+// @Param request body OfferRequest true "Встречная цена"
+// @Success 200 {object} OfferResponse
+// @Failure 400 {object} problem.Problem "Неверный формат запроса или ошибки валидации"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 404 {object} problem.Problem "Предложение не найдено"
+// @Failure 409 {object} problem.Problem "Предложение истекло, закрыто или ждет ответа другой стороны"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/offers/{id}/counter [post]
+func CounterOfferHandler(offercfg *config.OfferConfig, log logger.Logger, db database.Database) http.HandlerFunc {
+	validate := utils.NewValidator()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		amount, ok := decodeOfferAmount(w, r, log, validate)
+		if !ok {
+			return
+		}
+
+		respondToOffer(w, r, log, db, model.OfferActionCounter, amount, time.Now().Add(offercfg.TTL))
+	}
+}
+
+func respondToOffer(w http.ResponseWriter, r *http.Request, log logger.Logger, db database.Database, action string, counterAmount int, expiresAt time.Time) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	offer, err := db.RespondToOffer(r.Context(), chi.URLParam(r, "id"), userID, action, counterAmount, expiresAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrOfferNotFound):
+			problem.Write(w, r, http.StatusNotFound, "Offer not found")
+		case errors.Is(err, database.ErrOfferExpired):
+			problem.Write(w, r, http.StatusConflict, "Offer has expired")
+		case errors.Is(err, database.ErrOfferActionNotAllowed):
+			problem.Write(w, r, http.StatusConflict, "Offer cannot be answered this way now")
+		default:
+			log.Error(err, "failed to respond to offer")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+		}
+		return
+	}
+
+	log.Infof("offer answered", map[string]interface{}{
+		"offer_id": offer.ID,
+		"user_id":  userID,
+		"action":   action,
+		"status":   offer.Status,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toOfferResponse(offer, userID)); err != nil {
+		log.Error(err, "failed to encode response")
+	}
+}
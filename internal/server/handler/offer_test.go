@@ -0,0 +1,277 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"vk-internship/internal/config"
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+	zerologger "vk-internship/internal/logger/zerolog"
+)
+
+// offersDB answers offers the way PostgresDB.RespondToOffer does, the other
+// methods panic through the nil embedded interface.
+type offersDB struct {
+	database.Database
+	offers map[string]*model.Offer
+}
+
+func (db *offersDB) RespondToOffer(_ context.Context, id, userID, action string, counterAmount int, expiresAt time.Time) (*model.Offer, error) {
+	offer, ok := db.offers[id]
+	if !ok || (offer.BuyerID != userID && offer.SellerID != userID) {
+		return nil, database.ErrOfferNotFound
+	}
+
+	open := offer.Status == model.OfferStatusPending || offer.Status == model.OfferStatusCountered
+	if offer.Status == model.OfferStatusExpired || (open && !offer.ExpiresAt.After(time.Now())) {
+		return nil, database.ErrOfferExpired
+	}
+
+	next := model.NextOfferStatus(offer.Status, action, offer.SellerID == userID)
+	if next == "" {
+		return nil, database.ErrOfferActionNotAllowed
+	}
+
+	offer.Status = next
+	if next == model.OfferStatusCountered {
+		offer.CounterAmount, offer.ExpiresAt = &counterAmount, expiresAt
+	}
+
+	answered := *offer
+	return &answered, nil
+}
+
+func TestRespondToOfferHandlers(t *testing.T) {
+	const (
+		buyer  = "buyer-1"
+		seller = "seller-1"
+	)
+
+	tests := []struct {
+		name       string
+		status     string
+		expiresIn  time.Duration
+		userID     string
+		action     string
+		body       string
+		wantStatus int
+		wantDetail string
+		// wantOffer is the status the offer is left in
+		wantOffer   string
+		wantCounter int
+	}{
+		{
+			name:       "seller accepts the offer",
+			status:     model.OfferStatusPending,
+			userID:     seller,
+			action:     model.OfferActionAccept,
+			wantStatus: http.StatusOK,
+			wantOffer:  model.OfferStatusAccepted,
+		},
+		{
+			name:       "seller rejects the offer",
+			status:     model.OfferStatusPending,
+			userID:     seller,
+			action:     model.OfferActionReject,
+			wantStatus: http.StatusOK,
+			wantOffer:  model.OfferStatusRejected,
+		},
+		{
+			name:        "seller counters the offer",
+			status:      model.OfferStatusPending,
+			userID:      seller,
+			action:      model.OfferActionCounter,
+			body:        `{"amount": 1200.5}`,
+			wantStatus:  http.StatusOK,
+			wantOffer:   model.OfferStatusCountered,
+			wantCounter: 120050,
+		},
+		{
+			name:       "buyer accepts the counter",
+			status:     model.OfferStatusCountered,
+			userID:     buyer,
+			action:     model.OfferActionAccept,
+			wantStatus: http.StatusOK,
+			wantOffer:  model.OfferStatusAccepted,
+		},
+		{
+			name:       "buyer rejects the counter",
+			status:     model.OfferStatusCountered,
+			userID:     buyer,
+			action:     model.OfferActionReject,
+			wantStatus: http.StatusOK,
+			wantOffer:  model.OfferStatusRejected,
+		},
+		{
+			name:       "buyer cannot accept own offer",
+			status:     model.OfferStatusPending,
+			userID:     buyer,
+			action:     model.OfferActionAccept,
+			wantStatus: http.StatusConflict,
+			wantDetail: "cannot be answered",
+			wantOffer:  model.OfferStatusPending,
+		},
+		{
+			name:       "buyer cannot counter the counter",
+			status:     model.OfferStatusCountered,
+			userID:     buyer,
+			action:     model.OfferActionCounter,
+			body:       `{"amount": 1000}`,
+			wantStatus: http.StatusConflict,
+			wantDetail: "cannot be answered",
+			wantOffer:  model.OfferStatusCountered,
+		},
+		{
+			name:       "seller cannot answer an accepted offer",
+			status:     model.OfferStatusAccepted,
+			userID:     seller,
+			action:     model.OfferActionReject,
+			wantStatus: http.StatusConflict,
+			wantDetail: "cannot be answered",
+			wantOffer:  model.OfferStatusAccepted,
+		},
+		{
+			name:       "offer past its expiry",
+			status:     model.OfferStatusPending,
+			expiresIn:  -time.Minute,
+			userID:     seller,
+			action:     model.OfferActionAccept,
+			wantStatus: http.StatusConflict,
+			wantDetail: "expired",
+			wantOffer:  model.OfferStatusPending,
+		},
+		{
+			name:       "counter past its expiry",
+			status:     model.OfferStatusCountered,
+			expiresIn:  -time.Minute,
+			userID:     buyer,
+			action:     model.OfferActionAccept,
+			wantStatus: http.StatusConflict,
+			wantDetail: "expired",
+			wantOffer:  model.OfferStatusCountered,
+		},
+		{
+			name:       "offer closed by the expirer",
+			status:     model.OfferStatusExpired,
+			userID:     seller,
+			action:     model.OfferActionReject,
+			wantStatus: http.StatusConflict,
+			wantDetail: "expired",
+			wantOffer:  model.OfferStatusExpired,
+		},
+		{
+			name:       "someone else's offer",
+			status:     model.OfferStatusPending,
+			userID:     "stranger",
+			action:     model.OfferActionAccept,
+			wantStatus: http.StatusNotFound,
+			wantOffer:  model.OfferStatusPending,
+		},
+		{
+			name:       "counter without an amount",
+			status:     model.OfferStatusPending,
+			userID:     seller,
+			action:     model.OfferActionCounter,
+			body:       `{"amount": 0}`,
+			wantStatus: http.StatusBadRequest,
+			wantOffer:  model.OfferStatusPending,
+		},
+		{
+			name:       "anonymous request",
+			status:     model.OfferStatusPending,
+			action:     model.OfferActionAccept,
+			wantStatus: http.StatusUnauthorized,
+			wantOffer:  model.OfferStatusPending,
+		},
+	}
+
+	offercfg := &config.OfferConfig{TTL: 48 * time.Hour}
+	log := zerologger.NewWriter(&config.LoggerConfig{Level: "disabled"}, io.Discard)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expiresIn := tt.expiresIn
+			if expiresIn == 0 {
+				expiresIn = time.Hour
+			}
+			expiresAt := time.Now().Add(expiresIn)
+
+			offer := &model.Offer{
+				ID:        "offer-1",
+				BuyerID:   buyer,
+				SellerID:  seller,
+				Amount:    100000,
+				Status:    tt.status,
+				ExpiresAt: expiresAt,
+			}
+			db := &offersDB{offers: map[string]*model.Offer{offer.ID: offer}}
+
+			router := chi.NewRouter()
+			router.Post("/api/v1/offers/{id}/accept", AcceptOfferHandler(log, db))
+			router.Post("/api/v1/offers/{id}/reject", RejectOfferHandler(log, db))
+			router.Post("/api/v1/offers/{id}/counter", CounterOfferHandler(offercfg, log, db))
+
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/offers/offer-1/"+tt.action, strings.NewReader(tt.body))
+			if tt.userID != "" {
+				r = r.WithContext(context.WithValue(r.Context(), "userID", tt.userID))
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantDetail != "" && !strings.Contains(rec.Body.String(), tt.wantDetail) {
+				t.Errorf("body = %s, want detail %q", rec.Body.String(), tt.wantDetail)
+			}
+			if offer.Status != tt.wantOffer {
+				t.Errorf("offer status = %q, want %q", offer.Status, tt.wantOffer)
+			}
+
+			if tt.wantStatus != http.StatusOK {
+				if offer.CounterAmount != nil || !offer.ExpiresAt.Equal(expiresAt) {
+					t.Errorf("rejected answer changed the offer: %+v", offer)
+				}
+				return
+			}
+
+			var response OfferResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Status != tt.wantOffer {
+				t.Errorf("response status = %q, want %q", response.Status, tt.wantOffer)
+			}
+			if response.AwaitingYou {
+				t.Error("response is awaiting the user who just answered")
+			}
+
+			if tt.wantCounter == 0 {
+				if response.CounterAmount != nil {
+					t.Errorf("counter amount = %v, want none", *response.CounterAmount)
+				}
+				return
+			}
+
+			if offer.CounterAmount == nil || *offer.CounterAmount != tt.wantCounter {
+				t.Fatalf("counter amount = %v, want %d", offer.CounterAmount, tt.wantCounter)
+			}
+			if response.CounterAmount == nil || *response.CounterAmount != float64(tt.wantCounter)/100 {
+				t.Errorf("response counter amount = %v, want %v", response.CounterAmount, float64(tt.wantCounter)/100)
+			}
+			if !offer.ExpiresAt.After(time.Now().Add(offercfg.TTL - time.Minute)) {
+				t.Errorf("expires at = %v, want extended by the TTL", offer.ExpiresAt)
+			}
+		})
+	}
+}
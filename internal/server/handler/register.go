@@ -12,6 +12,8 @@ import (
 	"vk-internship/internal/database"
 	"vk-internship/internal/database/model"
 	"vk-internship/internal/logger"
+	"vk-internship/internal/server/problem"
+	"vk-internship/internal/tracing"
 	"vk-internship/internal/utils"
 )
 
@@ -41,14 +43,16 @@ type RegistrationResponse struct {
 // @Produce json
 // @Param request body RegistrationRequest true "Данные для регистрации"
 // @Success 201 {object} RegistrationResponse
-// @Failure 400 {object} map[string]string "Неверный формат запроса или ошибки валидации"
-// @Failure 409 {string} string "Пользователь с таким именем уже существует"
-// @Failure 500 {string} string "Внутренняя ошибка сервера"
-// @Router /register [post]
+// @Failure 400 {object} problem.Problem "Неверный формат запроса или ошибки валидации"
+// @Failure 409 {object} problem.Problem "Пользователь с таким именем уже существует"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/register [post]
 func RegistrationHandler(cfg *config.ServerConfig, log logger.Logger, db database.Database) http.HandlerFunc {
 	validate := utils.NewValidator()
 
 	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
 		var currentUserID string
 		var isAuthorized bool
 
@@ -60,7 +64,7 @@ func RegistrationHandler(cfg *config.ServerConfig, log logger.Logger, db databas
 		var req RegistrationRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			log.Warnf("invalid request body", map[string]interface{}{"error": err.Error()})
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
 			return
 		}
 
@@ -68,17 +72,17 @@ func RegistrationHandler(cfg *config.ServerConfig, log logger.Logger, db databas
 			validationErrors := validate.FormatValidationErrors(err)
 			log.Warnf("validation failed", map[string]interface{}{"errors": validationErrors})
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(validationErrors)
+			problem.Validation(w, r, validationErrors.Errors)
 
 			return
 		}
 
+		_, span := tracing.Start(r.Context(), "bcrypt.GenerateFromPassword")
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		span.End()
 		if err != nil {
 			log.Error(err, "password hashing failed")
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
 			return
 		}
 
@@ -87,23 +91,23 @@ func RegistrationHandler(cfg *config.ServerConfig, log logger.Logger, db databas
 			Password: string(hashedPassword),
 		}
 
-		createdUser, err := db.CreateUser(user)
+		createdUser, err := db.CreateUser(r.Context(), user)
 		if err != nil {
 			if errors.Is(err, database.ErrUserExists) {
 				log.Warnf("username already taken", map[string]interface{}{"username": req.Username})
-				http.Error(w, "Username already exists", http.StatusConflict)
+				problem.Write(w, r, http.StatusConflict, "Username already exists")
 				return
 			}
 
 			log.Error(err, "failed to create user")
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
 			return
 		}
 
 		token, err := utils.GenerateJWTToken(cfg, createdUser.ID, createdUser.Username)
 		if err != nil {
 			log.Error(err, "failed to generate token")
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
 			return
 		}
 
@@ -0,0 +1,274 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+	"vk-internship/internal/logger"
+	"vk-internship/internal/server/problem"
+	"vk-internship/internal/utils"
+)
+
+// CreateReviewRequest представляет запрос на создание отзыва
+// @Description Оценка продавца от 1 до 5 и текст отзыва
+type CreateReviewRequest struct {
+	Rating int    `json:"rating" validate:"required,oneof=1 2 3 4 5"`
+	Text   string `json:"text" validate:"omitempty,max=2000"`
+}
+
+// ReplyReviewRequest представляет ответ продавца на отзыв
+// @Description Текст ответа продавца
+type ReplyReviewRequest struct {
+	Text string `json:"text" validate:"required,max=2000"`
+}
+
+// ReviewResponse представляет отзыв о продавце
+// @Description Отзыв покупателя. ad_id и ad_caption отсутствуют, если объявление удалено
+type ReviewResponse struct {
+	ID             string     `json:"id"`
+	AdID           *string    `json:"ad_id,omitempty"`
+	AdCaption      *string    `json:"ad_caption,omitempty"`
+	SellerUsername string     `json:"seller_username"`
+	AuthorUsername string     `json:"author_username"`
+	Rating         int        `json:"rating"`
+	Text           string     `json:"text"`
+	Reply          *string    `json:"reply,omitempty"`
+	RepliedAt      *time.Time `json:"replied_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ReviewsResponse представляет список отзывов о продавце
+// @Description Отзывы о продавце (от новых к старым) с пагинацией и средней оценкой
+type ReviewsResponse struct {
+	Reviews      []ReviewResponse `json:"reviews"`
+	Rating       *float64         `json:"rating,omitempty"`
+	ReviewsCount int              `json:"reviews_count"`
+	Page         int              `json:"page"`
+	PageSize     int              `json:"page_size"`
+	Total        int              `json:"total"`
+	TotalPages   int              `json:"total_pages"`
+}
+
+func toReviewResponse(review *model.Review) ReviewResponse {
+	return ReviewResponse{
+		ID:             review.ID,
+		AdID:           review.AdID,
+		AdCaption:      review.AdCaption,
+		SellerUsername: review.SellerUsername,
+		AuthorUsername: review.AuthorUsername,
+		Rating:         review.Rating,
+		Text:           review.Body,
+		Reply:          review.Reply,
+		RepliedAt:      review.RepliedAt,
+		CreatedAt:      review.CreatedAt,
+	}
+}
+
+// CreateReviewHandler создает отзыв о продавце
+// @Security BearerAuth
+// @Summary Оставить отзыв
+// @Description Оставляет отзыв о продавце по объявлению. Отзыв может оставить только покупатель, который писал продавцу по этому объявлению, один раз на объявление. Продавец получает уведомление
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "ID объявления"
+// @Param request body CreateReviewRequest true "Отзыв"
+// @Param Idempotency-Key header string false "Ключ идемпотентности"
+// @Success 201 {object} ReviewResponse
+// @Failure 400 {object} problem.Problem "Неверный формат запроса или ошибки валидации"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 403 {object} problem.Problem "Нельзя оставить отзыв"
+// @Failure 404 {object} problem.Problem "Объявление не найдено"
+// @Failure 409 {object} problem.Problem "Отзыв уже оставлен"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/ads/{id}/reviews [post]
+func CreateReviewHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	validate := utils.NewValidator()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		var req CreateReviewRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Warnf("invalid request body", map[string]interface{}{"error": err.Error()})
+			problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		req.Text = strings.TrimSpace(req.Text)
+
+		if err := validate.Validate(req); err != nil {
+			problem.Validation(w, r, validate.FormatValidationErrors(err).Errors)
+			return
+		}
+
+		adID := chi.URLParam(r, "id")
+
+		review, err := db.CreateReview(r.Context(), &model.Review{
+			AdID:     &adID,
+			AuthorID: userID,
+			Rating:   req.Rating,
+			Body:     req.Text,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, database.ErrAdNotFound):
+				problem.Write(w, r, http.StatusNotFound, "Ad not found")
+			case errors.Is(err, database.ErrCannotReviewOwnAd):
+				problem.Write(w, r, http.StatusForbidden, "Cannot review your own ad")
+			case errors.Is(err, database.ErrReviewNotAllowed):
+				problem.Write(w, r, http.StatusForbidden, "Only buyers who contacted the seller about the ad can review it")
+			case errors.Is(err, database.ErrReviewExists):
+				problem.Write(w, r, http.StatusConflict, "You have already reviewed this ad")
+			default:
+				log.Error(err, "failed to create review")
+				problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			}
+			return
+		}
+
+		log.Infof("review created", map[string]interface{}{
+			"review_id": review.ID,
+			"ad_id":     adID,
+			"seller_id": review.SellerID,
+			"author_id": userID,
+			"rating":    review.Rating,
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(toReviewResponse(review)); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// GetUserReviewsHandler возвращает отзывы о продавце
+// @Summary Отзывы о продавце
+// @Description Возвращает отзывы о пользователе как о продавце, его среднюю оценку и число отзывов
+// @Tags reviews
+// @Produce json
+// @Param username path string true "Имя пользователя"
+// @Param page query int false "Номер страницы" default(1) minimum(1)
+// @Param page_size query int false "Количество элементов на странице" default(10) minimum(1) maximum(100)
+// @Success 200 {object} ReviewsResponse
+// @Failure 404 {object} problem.Problem "Пользователь не найден"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/users/{username}/reviews [get]
+func GetUserReviewsHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		user, err := db.GetUserProfile(r.Context(), chi.URLParam(r, "username"))
+		if err != nil {
+			if errors.Is(err, database.ErrUserNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "User not found")
+				return
+			}
+
+			log.Error(err, "failed to get user profile")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		page, pageSize := parsePagination(r)
+
+		reviews, total, err := db.GetUserReviews(r.Context(), user.ID, page, pageSize)
+		if err != nil {
+			log.Error(err, "failed to get reviews")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		response := ReviewsResponse{
+			Reviews:      make([]ReviewResponse, 0, len(reviews)),
+			Rating:       user.Rating,
+			ReviewsCount: user.ReviewsCount,
+			Page:         page,
+			PageSize:     pageSize,
+			Total:        total,
+			TotalPages:   totalPages(total, pageSize),
+		}
+		for _, review := range reviews {
+			response.Reviews = append(response.Reviews, toReviewResponse(review))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// ReplyReviewHandler сохраняет ответ продавца на отзыв
+// @Security BearerAuth
+// @Summary Ответить на отзыв
+// @Description Сохраняет ответ продавца на отзыв о нем, повторный ответ заменяет предыдущий. Автор отзыва получает уведомление
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "ID отзыва"
+// @Param request body ReplyReviewRequest true "Ответ"
+// @Success 200 {object} ReviewResponse
+// @Failure 400 {object} problem.Problem "Неверный формат запроса или ошибки валидации"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 404 {object} problem.Problem "Отзыв не найден"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/reviews/{id}/reply [put]
+func ReplyReviewHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	validate := utils.NewValidator()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		var req ReplyReviewRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Warnf("invalid request body", map[string]interface{}{"error": err.Error()})
+			problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		req.Text = strings.TrimSpace(req.Text)
+
+		if err := validate.Validate(req); err != nil {
+			problem.Validation(w, r, validate.FormatValidationErrors(err).Errors)
+			return
+		}
+
+		review, err := db.ReplyToReview(r.Context(), chi.URLParam(r, "id"), userID, req.Text)
+		if err != nil {
+			if errors.Is(err, database.ErrReviewNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Review not found")
+				return
+			}
+
+			log.Error(err, "failed to reply to review")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(toReviewResponse(review)); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
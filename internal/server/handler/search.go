@@ -0,0 +1,333 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+	"vk-internship/internal/logger"
+	"vk-internship/internal/server/problem"
+	"vk-internship/internal/utils"
+)
+
+// SavedSearchRequest представляет запрос на сохранение поиска
+// @Description Фильтр ленты объявлений, о новых совпадениях с которым нужно уведомлять
+type SavedSearchRequest struct {
+	Name     string   `json:"name" validate:"required,max=64"`
+	Query    string   `json:"query" validate:"omitempty,max=128"`
+	MinPrice *float64 `json:"min_price" validate:"omitempty,min=0"`
+	MaxPrice *float64 `json:"max_price" validate:"omitempty,min=0"`
+	SortBy   string   `json:"sort_by" validate:"omitempty,oneof=created_at price"`
+	Order    string   `json:"order" validate:"omitempty,oneof=ASC DESC asc desc"`
+}
+
+// SavedSearchResponse представляет сохраненный поиск
+// @Description Сохраненный поиск пользователя
+type SavedSearchResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query,omitempty"`
+	MinPrice  *float64  `json:"min_price,omitempty"`
+	MaxPrice  *float64  `json:"max_price,omitempty"`
+	SortBy    string    `json:"sort_by"`
+	Order     string    `json:"order"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SavedSearchesResponse представляет список сохраненных поисков
+// @Description Сохраненные поиски пользователя
+type SavedSearchesResponse struct {
+	Searches []SavedSearchResponse `json:"searches"`
+}
+
+// GetSavedSearchesHandler возвращает сохраненные поиски пользователя
+// @Security BearerAuth
+// @Summary Список сохраненных поисков
+// @Description Возвращает все сохраненные поиски текущего пользователя
+// @Tags searches
+// @Produce json
+// @Success 200 {object} SavedSearchesResponse
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/me/searches [get]
+func GetSavedSearchesHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		searches, err := db.GetSavedSearches(r.Context(), userID)
+		if err != nil {
+			log.Error(err, "failed to get saved searches")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		response := SavedSearchesResponse{Searches: make([]SavedSearchResponse, 0, len(searches))}
+		for _, s := range searches {
+			response.Searches = append(response.Searches, toSavedSearchResponse(s))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// GetSavedSearchHandler возвращает сохраненный поиск
+// @Security BearerAuth
+// @Summary Получить сохраненный поиск
+// @Description Возвращает сохраненный поиск по ID (только для владельца)
+// @Tags searches
+// @Produce json
+// @Param id path string true "ID сохраненного поиска"
+// @Success 200 {object} SavedSearchResponse
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 404 {object} problem.Problem "Сохраненный поиск не найден"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/me/searches/{id} [get]
+func GetSavedSearchHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		search, err := db.GetSavedSearch(r.Context(), chi.URLParam(r, "id"), userID)
+		if err != nil {
+			if errors.Is(err, database.ErrSavedSearchNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Saved search not found")
+				return
+			}
+			log.Error(err, "failed to get saved search")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(toSavedSearchResponse(search)); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// CreateSavedSearchHandler сохраняет поиск
+// @Security BearerAuth
+// @Summary Сохранить поиск
+// @Description Сохраняет фильтр ленты. О новых объявлениях, подходящих под фильтр, пользователь получает уведомления
+// @Tags searches
+// @Accept json
+// @Produce json
+// @Param request body SavedSearchRequest true "Параметры поиска"
+// @Success 201 {object} SavedSearchResponse
+// @Failure 400 {object} problem.Problem "Неверный формат запроса или ошибки валидации"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/me/searches [post]
+func CreateSavedSearchHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	validate := utils.NewValidator()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		search, ok := decodeSavedSearch(w, r, log, validate)
+		if !ok {
+			return
+		}
+		search.UserID = userID
+
+		created, err := db.CreateSavedSearch(r.Context(), search)
+		if err != nil {
+			log.Error(err, "failed to create saved search")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(toSavedSearchResponse(created)); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// UpdateSavedSearchHandler обновляет сохраненный поиск
+// @Security BearerAuth
+// @Summary Обновить сохраненный поиск
+// @Description Полностью заменяет параметры сохраненного поиска (только для владельца)
+// @Tags searches
+// @Accept json
+// @Produce json
+// @Param id path string true "ID сохраненного поиска"
+// @Param request body SavedSearchRequest true "Параметры поиска"
+// @Success 200 {object} SavedSearchResponse
+// @Failure 400 {object} problem.Problem "Неверный формат запроса или ошибки валидации"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 404 {object} problem.Problem "Сохраненный поиск не найден"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/me/searches/{id} [put]
+func UpdateSavedSearchHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	validate := utils.NewValidator()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		search, ok := decodeSavedSearch(w, r, log, validate)
+		if !ok {
+			return
+		}
+		search.ID = chi.URLParam(r, "id")
+		search.UserID = userID
+
+		updated, err := db.UpdateSavedSearch(r.Context(), search)
+		if err != nil {
+			if errors.Is(err, database.ErrSavedSearchNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Saved search not found")
+				return
+			}
+			log.Error(err, "failed to update saved search")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(toSavedSearchResponse(updated)); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// DeleteSavedSearchHandler удаляет сохраненный поиск
+// @Security BearerAuth
+// @Summary Удалить сохраненный поиск
+// @Description Удаляет сохраненный поиск (только для владельца)
+// @Tags searches
+// @Param id path string true "ID сохраненного поиска"
+// @Success 204 "Сохраненный поиск удален"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 404 {object} problem.Problem "Сохраненный поиск не найден"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/me/searches/{id} [delete]
+func DeleteSavedSearchHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		if err := db.DeleteSavedSearch(r.Context(), chi.URLParam(r, "id"), userID); err != nil {
+			if errors.Is(err, database.ErrSavedSearchNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Saved search not found")
+				return
+			}
+			log.Error(err, "failed to delete saved search")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func decodeSavedSearch(w http.ResponseWriter, r *http.Request, log logger.Logger, validate *utils.Validator) (*model.SavedSearch, bool) {
+	var req SavedSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warnf("invalid request body", map[string]interface{}{"error": err.Error()})
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
+		return nil, false
+	}
+
+	if err := validate.Validate(req); err != nil {
+		validationErrors := validate.FormatValidationErrors(err)
+		log.Warnf("validation failed", map[string]interface{}{"errors": validationErrors})
+
+		problem.Validation(w, r, validationErrors.Errors)
+		return nil, false
+	}
+
+	search := &model.SavedSearch{
+		Name:   req.Name,
+		Query:  strings.TrimSpace(req.Query),
+		SortBy: req.SortBy,
+		Order:  strings.ToUpper(req.Order),
+	}
+
+	if search.SortBy == "" {
+		search.SortBy = "created_at"
+	}
+
+	if search.Order == "" {
+		search.Order = "DESC"
+	}
+
+	if req.MinPrice != nil {
+		minPrice := int(*req.MinPrice * 100)
+		search.MinPrice = &minPrice
+	}
+
+	if req.MaxPrice != nil {
+		maxPrice := int(*req.MaxPrice * 100)
+		search.MaxPrice = &maxPrice
+	}
+
+	if search.MinPrice != nil && search.MaxPrice != nil && *search.MinPrice > *search.MaxPrice {
+		problem.Write(w, r, http.StatusBadRequest, "min_price must be less than or equal to max_price")
+		return nil, false
+	}
+
+	return search, true
+}
+
+func toSavedSearchResponse(s *model.SavedSearch) SavedSearchResponse {
+	response := SavedSearchResponse{
+		ID:        s.ID,
+		Name:      s.Name,
+		Query:     s.Query,
+		SortBy:    s.SortBy,
+		Order:     s.Order,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+
+	if s.MinPrice != nil {
+		minPrice := float64(*s.MinPrice) / 100
+		response.MinPrice = &minPrice
+	}
+
+	if s.MaxPrice != nil {
+		maxPrice := float64(*s.MaxPrice) / 100
+		response.MaxPrice = &maxPrice
+	}
+
+	return response
+}
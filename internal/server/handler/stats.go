@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"vk-internship/internal/config"
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+	"vk-internship/internal/logger"
+	"vk-internship/internal/server/problem"
+)
+
+// DailyStatsResponse представляет статистику объявления за один день
+// @Description Статистика объявления за день
+type DailyStatsResponse struct {
+	Date          string `json:"date"`
+	Views         int64  `json:"views"`
+	Favorites     int64  `json:"favorites"`
+	ContactClicks int64  `json:"contact_clicks"`
+}
+
+// AdStatsResponse представляет статистику объявления за период
+// @Description Статистика просмотров, добавлений в избранное и обращений к продавцу
+type AdStatsResponse struct {
+	AdID               string               `json:"ad_id"`
+	From               string               `json:"from"`
+	To                 string               `json:"to"`
+	TotalViews         int64                `json:"total_views"`
+	TotalFavorites     int64                `json:"total_favorites"`
+	TotalContactClicks int64                `json:"total_contact_clicks"`
+	Days               []DailyStatsResponse `json:"days"`
+}
+
+// GetAdStatsHandler возвращает статистику объявления
+// @Security BearerAuth
+// @Summary Статистика объявления
+// @Description Возвращает статистику объявления по дням за период (только для автора объявления). По умолчанию - последние 30 дней
+// @Tags ads
+// @Accept json
+// @Produce json
+// @Param id path string true "ID объявления"
+// @Param from query string false "Начало периода (YYYY-MM-DD)"
+// @Param to query string false "Конец периода (YYYY-MM-DD)"
+// @Success 200 {object} AdStatsResponse
+// @Failure 400 {object} problem.Problem "Неверные параметры запроса"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 403 {object} problem.Problem "Нет прав на просмотр статистики"
+// @Failure 404 {object} problem.Problem "Объявление не найдено"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/ads/{id}/stats [get]
+func GetAdStatsHandler(cfg *config.StatsConfig, log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		adID := chi.URLParam(r, "id")
+		if adID == "" {
+			problem.Write(w, r, http.StatusBadRequest, "Ad ID is required")
+			return
+		}
+
+		query := r.URL.Query()
+
+		to := time.Now().UTC().Truncate(24 * time.Hour)
+		if toStr := query.Get("to"); toStr != "" {
+			parsed, err := time.Parse(time.DateOnly, toStr)
+			if err != nil {
+				problem.Write(w, r, http.StatusBadRequest, "to must be a date in YYYY-MM-DD format")
+				return
+			}
+			to = parsed
+		}
+
+		from := to.AddDate(0, 0, -29)
+		if fromStr := query.Get("from"); fromStr != "" {
+			parsed, err := time.Parse(time.DateOnly, fromStr)
+			if err != nil {
+				problem.Write(w, r, http.StatusBadRequest, "from must be a date in YYYY-MM-DD format")
+				return
+			}
+			from = parsed
+		}
+
+		if from.After(to) {
+			problem.Write(w, r, http.StatusBadRequest, "from must be less than or equal to to")
+			return
+		}
+
+		days := int(to.Sub(from).Hours()/24) + 1
+		if days > cfg.MaxRangeDays {
+			problem.Write(w, r, http.StatusBadRequest, "date range is too large")
+			return
+		}
+
+		ad, err := db.GetAd(r.Context(), adID)
+		if err != nil {
+			if errors.Is(err, database.ErrAdNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Ad not found")
+				return
+			}
+			log.Error(err, "failed to get ad")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		if ad.AuthorID != userID {
+			problem.Write(w, r, http.StatusForbidden, "Forbidden")
+			return
+		}
+
+		stats, err := db.GetAdStats(r.Context(), adID, from, to)
+		if err != nil {
+			log.Error(err, "failed to get ad stats")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		byDay := make(map[string]*model.AdDailyStats, len(stats))
+		for _, s := range stats {
+			byDay[s.Day.Format(time.DateOnly)] = s
+		}
+
+		response := AdStatsResponse{
+			AdID: adID,
+			From: from.Format(time.DateOnly),
+			To:   to.Format(time.DateOnly),
+			Days: make([]DailyStatsResponse, 0, days),
+		}
+
+		for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+			date := day.Format(time.DateOnly)
+			daily := DailyStatsResponse{Date: date}
+
+			if s, ok := byDay[date]; ok {
+				daily.Views = s.Views
+				daily.Favorites = s.Favorites
+				daily.ContactClicks = s.ContactClicks
+			}
+
+			response.TotalViews += daily.Views
+			response.TotalFavorites += daily.Favorites
+			response.TotalContactClicks += daily.ContactClicks
+			response.Days = append(response.Days, daily)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+func viewerID(r *http.Request, userID string) string {
+	if userID != "" {
+		return "user:" + userID
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"vk-internship/internal/broker"
+	"vk-internship/internal/config"
+	"vk-internship/internal/logger"
+	"vk-internship/internal/server/problem"
+)
+
+// AdEventResponse представляет событие об изменении объявления
+// @Description Данные SSE-события. Для ad.deleted передается только ad_id
+type AdEventResponse struct {
+	AdID       string      `json:"ad_id"`
+	Ad         *AdResponse `json:"ad,omitempty"`
+	OccurredAt time.Time   `json:"occurred_at"`
+}
+
+// StreamAdsHandler отправляет изменения ленты объявлений через Server-Sent Events
+// @Summary Поток изменений объявлений
+// @Description Отправляет события ad.created, ad.updated и ad.deleted в формате text/event-stream. Фильтр по цене применяется к созданным и измененным объявлениям. Для продолжения после разрыва передайте заголовок Last-Event-ID (или параметр last_event_id)
+// @Tags ads
+// @Produce text/event-stream
+// @Param min_price query number false "Минимальная цена"
+// @Param max_price query number false "Максимальная цена"
+// @Param last_event_id query int false "ID последнего полученного события"
+// @Param Last-Event-ID header int false "ID последнего полученного события"
+// @Success 200 {object} AdEventResponse
+// @Failure 400 {object} problem.Problem "Неверные параметры запроса"
+// @Failure 500 {object} problem.Problem "Потоковая передача не поддерживается"
+// @Router /api/v1/ads/stream [get]
+func StreamAdsHandler(cfg *config.BrokerConfig, log logger.Logger, b *broker.Broker, mapper ResponseMapper) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		query := r.URL.Query()
+
+		minPrice, maxPrice, err := parsePriceRange(query)
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		lastEventID := r.Header.Get("Last-Event-ID")
+		if lastEventID == "" {
+			lastEventID = query.Get("last_event_id")
+		}
+
+		var lastID uint64
+		if lastEventID != "" {
+			lastID, err = strconv.ParseUint(lastEventID, 10, 64)
+			if err != nil {
+				problem.Write(w, r, http.StatusBadRequest, "Invalid Last-Event-ID")
+				return
+			}
+		}
+
+		rc := http.NewResponseController(w)
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+			log.Warnf("failed to disable write deadline", map[string]interface{}{"error": err.Error()})
+		}
+
+		sub, missed := b.Subscribe(lastID)
+		defer b.Unsubscribe(sub)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		fmt.Fprintf(w, "retry: %d\n\n", (3 * time.Second).Milliseconds())
+
+		matches := func(event broker.Event) bool {
+			if event.Ad == nil {
+				return true
+			}
+			if minPrice != nil && event.Ad.Price < *minPrice {
+				return false
+			}
+			if maxPrice != nil && event.Ad.Price > *maxPrice {
+				return false
+			}
+			return true
+		}
+
+		send := func(event broker.Event) error {
+			if !matches(event) {
+				return nil
+			}
+
+			payload, err := json.Marshal(mapper.AdEvent(event))
+			if err != nil {
+				return err
+			}
+
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, payload); err != nil {
+				return err
+			}
+
+			return rc.Flush()
+		}
+
+		for _, event := range missed {
+			if err := send(event); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			log.Error(err, "streaming is not supported")
+			return
+		}
+
+		log.Debugf("sse client connected", map[string]interface{}{"last_event_id": lastID, "replayed": len(missed)})
+
+		heartbeat := time.NewTicker(cfg.HeartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				log.Debug("sse client disconnected")
+				return
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+				if err := rc.Flush(); err != nil {
+					return
+				}
+			case event, ok := <-sub.Events:
+				if !ok {
+					log.Debug("sse subscription dropped")
+					return
+				}
+				if err := send(event); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
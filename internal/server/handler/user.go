@@ -0,0 +1,367 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+	"vk-internship/internal/logger"
+	"vk-internship/internal/server/problem"
+	"vk-internship/internal/utils"
+)
+
+// AuthorResponse представляет автора объявления
+// @Description Публичная часть профиля автора. Телефон указывается, если автор разрешил его показывать
+type AuthorResponse struct {
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name,omitempty"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+	City        string `json:"city,omitempty"`
+	Phone       string `json:"phone,omitempty"`
+}
+
+// UserProfileResponse представляет публичный профиль продавца
+// @Description Публичная информация о пользователе
+type UserProfileResponse struct {
+	Username       string    `json:"username"`
+	DisplayName    string    `json:"display_name,omitempty"`
+	Bio            string    `json:"bio,omitempty"`
+	AvatarURL      string    `json:"avatar_url,omitempty"`
+	City           string    `json:"city,omitempty"`
+	Phone          string    `json:"phone,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	ActiveAdsCount int       `json:"active_ads_count"`
+	Rating         *float64  `json:"rating,omitempty"`
+	ReviewsCount   int       `json:"reviews_count"`
+}
+
+// MeResponse представляет профиль текущего пользователя
+// @Description Профиль текущего пользователя, ads_count учитывает черновики и снятые с публикации объявления
+type MeResponse struct {
+	ID              string    `json:"id"`
+	Username        string    `json:"username"`
+	DisplayName     string    `json:"display_name"`
+	Bio             string    `json:"bio"`
+	AvatarURL       string    `json:"avatar_url"`
+	City            string    `json:"city"`
+	Phone           string    `json:"phone"`
+	PhoneVisibility string    `json:"phone_visibility"`
+	CreatedAt       time.Time `json:"created_at"`
+	AdsCount        int       `json:"ads_count"`
+	ActiveAdsCount  int       `json:"active_ads_count"`
+	Rating          *float64  `json:"rating,omitempty"`
+	ReviewsCount    int       `json:"reviews_count"`
+}
+
+// UpdateProfileRequest представляет запрос на изменение профиля
+// @Description Профиль заменяется целиком, пустые поля очищаются. phone_visibility: hidden - телефон виден только вам, registered - авторизованным пользователям, public - всем
+type UpdateProfileRequest struct {
+	DisplayName     string `json:"display_name" validate:"omitempty,max=64"`
+	Bio             string `json:"bio" validate:"omitempty,max=512"`
+	AvatarURL       string `json:"avatar_url" validate:"omitempty,max=1024,http_url"`
+	City            string `json:"city" validate:"omitempty,max=64"`
+	Phone           string `json:"phone" validate:"omitempty,e164"`
+	PhoneVisibility string `json:"phone_visibility" validate:"omitempty,oneof=hidden registered public"`
+}
+
+func toMeResponse(user *model.User) MeResponse {
+	return MeResponse{
+		ID:              user.ID,
+		Username:        user.Username,
+		DisplayName:     user.DisplayName,
+		Bio:             user.Bio,
+		AvatarURL:       user.AvatarURL,
+		City:            user.City,
+		Phone:           user.Phone,
+		PhoneVisibility: user.PhoneVisibility,
+		CreatedAt:       user.CreatedAt,
+		AdsCount:        user.AdsCount,
+		ActiveAdsCount:  user.ActiveAdsCount,
+		Rating:          user.Rating,
+		ReviewsCount:    user.ReviewsCount,
+	}
+}
+
+// GetUserProfileHandler возвращает публичный профиль пользователя
+// @Summary Профиль продавца
+// @Description Возвращает профиль пользователя, дату регистрации, число его опубликованных объявлений и среднюю оценку по отзывам. Телефон указывается, если пользователь разрешил его показывать
+// @Tags users
+// @Produce json
+// @Param username path string true "Имя пользователя"
+// @Security ApiKeyAuth
+// @Success 200 {object} UserProfileResponse
+// @Failure 404 {object} problem.Problem "Пользователь не найден"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/users/{username} [get]
+func GetUserProfileHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		user, err := db.GetUserProfile(r.Context(), chi.URLParam(r, "username"))
+		if err != nil {
+			if errors.Is(err, database.ErrUserNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "User not found")
+				return
+			}
+
+			log.Error(err, "failed to get user profile")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		viewerID, _ := r.Context().Value("userID").(string)
+
+		response := UserProfileResponse{
+			Username:       user.Username,
+			DisplayName:    user.DisplayName,
+			Bio:            user.Bio,
+			AvatarURL:      user.AvatarURL,
+			City:           user.City,
+			Phone:          user.VisiblePhone(user.ID, viewerID),
+			CreatedAt:      user.CreatedAt,
+			ActiveAdsCount: user.ActiveAdsCount,
+			Rating:         user.Rating,
+			ReviewsCount:   user.ReviewsCount,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// GetUserAdsHandler возвращает объявления пользователя
+// @Summary Объявления продавца
+// @Description Возвращает опубликованные объявления пользователя с теми же пагинацией, фильтрами и сортировкой, что и список объявлений
+// @Tags users
+// @Produce json
+// @Param username path string true "Имя пользователя"
+// @Param page query int false "Номер страницы" default(1) minimum(1)
+// @Param page_size query int false "Количество элементов на странице" default(10) minimum(1) maximum(100)
+// @Param sort_by query string false "Поле для сортировки (created_at, price)" default(created_at) Enums(created_at, price)
+// @Param order query string false "Порядок сортировки (ASC, DESC)" default(DESC) Enums(ASC, DESC)
+// @Param min_price query number false "Минимальная цена"
+// @Param max_price query number false "Максимальная цена"
+// @Security ApiKeyAuth
+// @Success 200 {object} FeedResponse
+// @Failure 400 {object} problem.Problem "Неверные параметры запроса"
+// @Failure 404 {object} problem.Problem "Пользователь не найден"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/users/{username}/ads [get]
+func GetUserAdsHandler(log logger.Logger, db database.Database, mapper ResponseMapper) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		filter, err := parseAdFilter(r.URL.Query())
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "min_price must be less than or equal to max_price")
+			return
+		}
+
+		user, err := db.GetUserByUsername(r.Context(), chi.URLParam(r, "username"))
+		if err != nil {
+			if errors.Is(err, database.ErrUserNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "User not found")
+				return
+			}
+
+			log.Error(err, "failed to get user")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		filter.AuthorID = user.ID
+
+		writeAdsPage(w, r, log, db, mapper, filter)
+	}
+}
+
+// GetMeHandler возвращает профиль текущего пользователя
+// @Security BearerAuth
+// @Summary Мой профиль
+// @Description Возвращает профиль текущего пользователя
+// @Tags users
+// @Produce json
+// @Success 200 {object} MeResponse
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 404 {object} problem.Problem "Пользователь не найден"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/me [get]
+func GetMeHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		username, ok := r.Context().Value("username").(string)
+		if !ok || username == "" {
+			log.Warn("username not found in context")
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		user, err := db.GetUserProfile(r.Context(), username)
+		if err != nil {
+			if errors.Is(err, database.ErrUserNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "User not found")
+				return
+			}
+
+			log.Error(err, "failed to get user profile")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(toMeResponse(user)); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// UpdateProfileHandler изменяет профиль текущего пользователя
+// @Security BearerAuth
+// @Summary Изменить профиль
+// @Description Заменяет профиль текущего пользователя. Имя, аватар и город показываются в объявлениях, телефон - в зависимости от phone_visibility
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body UpdateProfileRequest true "Профиль"
+// @Success 200 {object} MeResponse
+// @Failure 400 {object} problem.Problem "Неверный формат запроса или ошибки валидации"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 404 {object} problem.Problem "Пользователь не найден"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/me/profile [put]
+func UpdateProfileHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	validate := utils.NewValidator()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			log.Warn("userID not found in context")
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		var req UpdateProfileRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Warnf("invalid request body", map[string]interface{}{"error": err.Error()})
+			problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		for _, field := range []*string{&req.DisplayName, &req.Bio, &req.AvatarURL, &req.City, &req.Phone} {
+			*field = strings.TrimSpace(*field)
+		}
+
+		if err := validate.Validate(req); err != nil {
+			validationErrors := validate.FormatValidationErrors(err)
+			log.Warnf("validation failed", map[string]interface{}{"errors": validationErrors})
+
+			problem.Validation(w, r, validationErrors.Errors)
+			return
+		}
+
+		if req.PhoneVisibility == "" {
+			req.PhoneVisibility = model.PhoneVisibilityHidden
+		}
+
+		user, err := db.UpdateUserProfile(r.Context(), userID, model.UserProfile{
+			DisplayName:     req.DisplayName,
+			Bio:             req.Bio,
+			AvatarURL:       req.AvatarURL,
+			City:            req.City,
+			Phone:           req.Phone,
+			PhoneVisibility: req.PhoneVisibility,
+		})
+		if err != nil {
+			if errors.Is(err, database.ErrUserNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "User not found")
+				return
+			}
+
+			log.Error(err, "failed to update user profile")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		log.Infof("user profile updated", map[string]interface{}{"user_id": userID})
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(toMeResponse(user)); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// GetMyAdsHandler возвращает объявления текущего пользователя
+// @Security BearerAuth
+// @Summary Мои объявления
+// @Description Возвращает все объявления текущего пользователя, включая черновики и снятые с публикации. В ответе у каждого объявления есть status
+// @Tags users
+// @Produce json
+// @Param status query []string false "Статусы объявлений, по умолчанию все" collectionFormat(multi) Enums(active, draft, inactive, sold, hidden)
+// @Param page query int false "Номер страницы" default(1) minimum(1)
+// @Param page_size query int false "Количество элементов на странице" default(10) minimum(1) maximum(100)
+// @Param sort_by query string false "Поле для сортировки (created_at, price)" default(created_at) Enums(created_at, price)
+// @Param order query string false "Порядок сортировки (ASC, DESC)" default(DESC) Enums(ASC, DESC)
+// @Param min_price query number false "Минимальная цена"
+// @Param max_price query number false "Максимальная цена"
+// @Success 200 {object} FeedResponse
+// @Failure 400 {object} problem.Problem "Неверные параметры запроса"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/me/ads [get]
+func GetMyAdsHandler(log logger.Logger, db database.Database, mapper ResponseMapper) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			log.Warn("userID not found in context")
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		filter, err := parseOwnAdFilter(r.URL.Query(), userID)
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		writeAdsPage(w, r, log, db, mapper, filter)
+	}
+}
+
+// parseOwnAdFilter is parseAdFilter for the ads of the current user, who
+// also sees drafts, inactive and sold ads and may select them by status.
+func parseOwnAdFilter(query url.Values, userID string) (model.AdFilter, error) {
+	filter, err := parseAdFilter(query)
+	if err != nil {
+		return filter, err
+	}
+
+	filter.AuthorID = userID
+	filter.Statuses = model.AdStatuses
+
+	if statuses := query["status"]; len(statuses) > 0 {
+		for _, status := range statuses {
+			if !slices.Contains(model.AdStatuses, status) {
+				return filter, errors.New("status must be one of active, draft, inactive, sold, hidden")
+			}
+		}
+		filter.Statuses = statuses
+	}
+
+	return filter, nil
+}
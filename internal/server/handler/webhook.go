@@ -0,0 +1,347 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"vk-internship/internal/config"
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+	"vk-internship/internal/logger"
+	"vk-internship/internal/server/problem"
+	"vk-internship/internal/utils"
+	"vk-internship/internal/webhook"
+)
+
+// WebhookRequest представляет запрос на создание подписки
+// @Description Подписка на события объявлений. Если secret не указан, он будет сгенерирован
+type WebhookRequest struct {
+	URL        string   `json:"url" validate:"required,http_url,max=2048"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,oneof=ad.created ad.updated ad.deleted"`
+	Secret     string   `json:"secret" validate:"omitempty,min=16,max=128"`
+}
+
+// WebhookResponse представляет подписку на вебхуки
+// @Description Подписка на вебхуки. Secret возвращается только при создании
+type WebhookResponse struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret,omitempty"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhooksResponse представляет список подписок
+// @Description Подписки пользователя на вебхуки
+type WebhooksResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+// WebhookDeliveryResponse представляет доставку вебхука
+// @Description Доставка события, исчерпавшая все попытки
+type WebhookDeliveryResponse struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscription_id"`
+	URL            string          `json:"url"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Attempts       int             `json:"attempts"`
+	LastStatusCode *int            `json:"last_status_code,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// WebhookDeliveriesResponse представляет список доставок
+// @Description Недоставленные события (dead letters) с пагинацией
+type WebhookDeliveriesResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	Page       int                       `json:"page"`
+	PageSize   int                       `json:"page_size"`
+	Total      int                       `json:"total"`
+	TotalPages int                       `json:"total_pages"`
+}
+
+// CreateWebhookHandler создает подписку на вебхуки
+// @Security BearerAuth
+// @Summary Создать подписку на вебхуки
+// @Description Подписывает URL на события объявлений. URL, указывающие на локальные и частные адреса, отклоняются. Каждый запрос подписывается заголовком X-Webhook-Signature: sha256=HMAC-SHA256(secret, "<X-Webhook-Timestamp>.<тело запроса>")
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body WebhookRequest true "Параметры подписки"
+// @Success 201 {object} WebhookResponse
+// @Failure 400 {object} problem.Problem "Неверный формат запроса или ошибки валидации"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/webhooks [post]
+func CreateWebhookHandler(webhookcfg *config.WebhookConfig, log logger.Logger, db database.Database) http.HandlerFunc {
+	validate := utils.NewValidator()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		var req WebhookRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Warnf("invalid request body", map[string]interface{}{"error": err.Error()})
+			problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		if err := validate.Validate(req); err != nil {
+			validationErrors := validate.FormatValidationErrors(err)
+			log.Warnf("validation failed", map[string]interface{}{"errors": validationErrors})
+
+			problem.Validation(w, r, validationErrors.Errors)
+			return
+		}
+
+		if !webhookcfg.AllowPrivateTargets {
+			if err := webhook.CheckTarget(r.Context(), req.URL); err != nil {
+				log.Warnf("webhook target rejected", map[string]interface{}{"url": req.URL, "error": err.Error()})
+				problem.Validation(w, r, []utils.ValidationError{{Field: "url", Message: err.Error()}})
+				return
+			}
+		}
+
+		secret := req.Secret
+		if secret == "" {
+			buf := make([]byte, 32)
+			if _, err := rand.Read(buf); err != nil {
+				log.Error(err, "failed to generate webhook secret")
+				problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+				return
+			}
+			secret = hex.EncodeToString(buf)
+		}
+
+		created, err := db.CreateWebhook(r.Context(), &model.Webhook{
+			UserID:     userID,
+			URL:        req.URL,
+			EventTypes: uniqueStrings(req.EventTypes),
+			Secret:     secret,
+		})
+		if err != nil {
+			log.Error(err, "failed to create webhook")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		response := toWebhookResponse(created)
+		response.Secret = created.Secret
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// GetWebhooksHandler возвращает подписки пользователя
+// @Security BearerAuth
+// @Summary Список подписок на вебхуки
+// @Description Возвращает подписки текущего пользователя (без секретов)
+// @Tags webhooks
+// @Produce json
+// @Success 200 {object} WebhooksResponse
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/webhooks [get]
+func GetWebhooksHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		webhooks, err := db.GetWebhooks(r.Context(), userID)
+		if err != nil {
+			log.Error(err, "failed to get webhooks")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		response := WebhooksResponse{Webhooks: make([]WebhookResponse, 0, len(webhooks))}
+		for _, webhook := range webhooks {
+			response.Webhooks = append(response.Webhooks, toWebhookResponse(webhook))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// DeleteWebhookHandler удаляет подписку
+// @Security BearerAuth
+// @Summary Удалить подписку на вебхуки
+// @Description Удаляет подписку вместе с ее очередью доставок (только для владельца)
+// @Tags webhooks
+// @Param id path string true "ID подписки"
+// @Success 204 "Подписка удалена"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 404 {object} problem.Problem "Подписка не найдена"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/webhooks/{id} [delete]
+func DeleteWebhookHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		if err := db.DeleteWebhook(r.Context(), chi.URLParam(r, "id"), userID); err != nil {
+			if errors.Is(err, database.ErrWebhookNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Webhook not found")
+				return
+			}
+			log.Error(err, "failed to delete webhook")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GetDeadWebhookDeliveriesHandler возвращает недоставленные события
+// @Security BearerAuth
+// @Summary Недоставленные вебхуки
+// @Description Возвращает доставки подписок пользователя, исчерпавшие все попытки
+// @Tags webhooks
+// @Produce json
+// @Param page query int false "Номер страницы" default(1) minimum(1)
+// @Param page_size query int false "Количество элементов на странице" default(10) minimum(1) maximum(100)
+// @Success 200 {object} WebhookDeliveriesResponse
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/webhooks/dead-letters [get]
+func GetDeadWebhookDeliveriesHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		page, pageSize := parsePagination(r)
+
+		deliveries, total, err := db.GetDeadWebhookDeliveries(r.Context(), userID, page, pageSize)
+		if err != nil {
+			log.Error(err, "failed to get dead webhook deliveries")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		response := WebhookDeliveriesResponse{
+			Deliveries: make([]WebhookDeliveryResponse, 0, len(deliveries)),
+			Page:       page,
+			PageSize:   pageSize,
+			Total:      total,
+			TotalPages: totalPages(total, pageSize),
+		}
+
+		for _, d := range deliveries {
+			response.Deliveries = append(response.Deliveries, WebhookDeliveryResponse{
+				ID:             d.ID,
+				SubscriptionID: d.SubscriptionID,
+				URL:            d.URL,
+				EventID:        d.EventID,
+				EventType:      d.EventType,
+				Payload:        d.Payload,
+				Attempts:       d.Attempts,
+				LastStatusCode: d.LastStatusCode,
+				LastError:      d.LastError,
+				CreatedAt:      d.CreatedAt,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error(err, "failed to encode response")
+		}
+	}
+}
+
+// RetryWebhookDeliveryHandler ставит недоставленное событие в очередь повторно
+// @Security BearerAuth
+// @Summary Повторить доставку вебхука
+// @Description Возвращает недоставленное событие в очередь со сброшенным счетчиком попыток
+// @Tags webhooks
+// @Param id path string true "ID доставки"
+// @Success 202 "Доставка поставлена в очередь"
+// @Failure 401 {object} problem.Problem "Не авторизован"
+// @Failure 404 {object} problem.Problem "Доставка не найдена"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /api/v1/webhooks/deliveries/{id}/retry [post]
+func RetryWebhookDeliveryHandler(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithContext(r.Context())
+
+		userID, ok := r.Context().Value("userID").(string)
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		if err := db.RetryWebhookDelivery(r.Context(), chi.URLParam(r, "id"), userID); err != nil {
+			if errors.Is(err, database.ErrWebhookDeliveryNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Webhook delivery not found")
+				return
+			}
+			log.Error(err, "failed to retry webhook delivery")
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func toWebhookResponse(w *model.Webhook) WebhookResponse {
+	return WebhookResponse{
+		ID:         w.ID,
+		URL:        w.URL,
+		EventTypes: w.EventTypes,
+		Active:     w.Active,
+		CreatedAt:  w.CreatedAt,
+	}
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	result := make([]string, 0, len(values))
+
+	for _, v := range values {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		result = append(result, v)
+	}
+
+	return result
+}
@@ -6,24 +6,28 @@ import (
 	"strings"
 
 	"vk-internship/internal/config"
+	"vk-internship/internal/database"
 	"vk-internship/internal/logger"
+	"vk-internship/internal/server/problem"
 	"vk-internship/internal/utils"
 )
 
 func AuthRequiredMiddleware(cfg *config.ServerConfig, log logger.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			log := log.WithContext(r.Context())
+
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
 				log.Warn("missing authorization header")
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
 				return
 			}
 
 			parts := strings.Split(authHeader, " ")
 			if len(parts) != 2 || parts[0] != "Bearer" {
 				log.Warnf("invalid authorization format", map[string]interface{}{"auth_header": authHeader})
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
 				return
 			}
 
@@ -32,7 +36,7 @@ func AuthRequiredMiddleware(cfg *config.ServerConfig, log logger.Logger) func(ht
 			claims, err := utils.VerifyJWTToken(cfg, token)
 			if err != nil {
 				log.Warnf("invalid token", map[string]interface{}{"error": err.Error()})
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
 				return
 			}
 
@@ -44,9 +48,14 @@ func AuthRequiredMiddleware(cfg *config.ServerConfig, log logger.Logger) func(ht
 	}
 }
 
-func AuthOptionalMiddleware(cfg *config.ServerConfig, log logger.Logger) func(http.Handler) http.Handler {
+// AuthOptionalMiddleware authenticates the request if it carries a valid
+// token. Tokens of disabled and deleted users are treated as no token, so they
+// see only what anonymous users see.
+func AuthOptionalMiddleware(cfg *config.ServerConfig, log logger.Logger, db database.Database) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			log := log.WithContext(r.Context())
+
 			ctx := r.Context()
 
 			authHeader := r.Header.Get("Authorization")
@@ -57,8 +66,16 @@ func AuthOptionalMiddleware(cfg *config.ServerConfig, log logger.Logger) func(ht
 					token := parts[1]
 
 					if claims, err := utils.VerifyJWTToken(cfg, token); err == nil {
-						ctx = context.WithValue(ctx, "userID", claims.UserID)
-						ctx = context.WithValue(ctx, "username", claims.Username)
+						active, err := db.IsUserActive(ctx, claims.UserID)
+						switch {
+						case err != nil:
+							log.Error(err, "failed to check user")
+						case !active:
+							log.Warnf("inactive user treated as anonymous", map[string]interface{}{"user_id": claims.UserID})
+						default:
+							ctx = context.WithValue(ctx, "userID", claims.UserID)
+							ctx = context.WithValue(ctx, "username", claims.Username)
+						}
 					} else {
 						log.Warnf("invalid token", map[string]interface{}{"error": err.Error()})
 					}
@@ -71,3 +88,59 @@ func AuthOptionalMiddleware(cfg *config.ServerConfig, log logger.Logger) func(ht
 		})
 	}
 }
+
+// ActiveUserMiddleware rejects tokens of disabled and deleted users. It runs
+// after AuthRequiredMiddleware, so disabling an account takes effect before
+// its tokens expire.
+func ActiveUserMiddleware(log logger.Logger, db database.Database) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			log := log.WithContext(r.Context())
+
+			userID, _ := r.Context().Value("userID").(string)
+
+			active, err := db.IsUserActive(r.Context(), userID)
+			if err != nil {
+				log.Error(err, "failed to check user")
+				problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+				return
+			}
+
+			if !active {
+				log.Warnf("inactive user rejected", map[string]interface{}{"user_id": userID})
+				problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ModeratorMiddleware lets through only moderators. It runs after
+// AuthRequiredMiddleware and checks the flag on every request, so a revoked
+// moderator loses access at once.
+func ModeratorMiddleware(log logger.Logger, db database.Database) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			log := log.WithContext(r.Context())
+
+			userID, _ := r.Context().Value("userID").(string)
+
+			moderator, err := db.IsModerator(r.Context(), userID)
+			if err != nil {
+				log.Error(err, "failed to check moderator")
+				problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+				return
+			}
+
+			if !moderator {
+				log.Warnf("moderator access denied", map[string]interface{}{"user_id": userID})
+				problem.Write(w, r, http.StatusForbidden, "Forbidden")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
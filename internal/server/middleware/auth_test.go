@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"vk-internship/internal/config"
+	"vk-internship/internal/database"
+	zerologger "vk-internship/internal/logger/zerolog"
+	"vk-internship/internal/utils"
+)
+
+// usersDB answers IsUserActive from a map, the other methods panic through
+// the nil embedded interface.
+type usersDB struct {
+	database.Database
+	active map[string]bool
+	err    error
+}
+
+func (db *usersDB) IsUserActive(_ context.Context, userID string) (bool, error) {
+	return db.active[userID], db.err
+}
+
+func TestAuthOptionalMiddleware(t *testing.T) {
+	cfg := &config.ServerConfig{JWTSecret: "secret", JWTTTL: time.Hour, JWTIssuer: "test"}
+	log := zerologger.NewWriter(&config.LoggerConfig{Level: "disabled"}, io.Discard)
+
+	token := func(userID string) string {
+		token, err := utils.GenerateJWTToken(cfg, userID, "name-"+userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + token
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		dbErr  error
+		want   string
+	}{
+		{name: "active user is authenticated", header: token("active"), want: "active"},
+		{name: "disabled user is anonymous", header: token("disabled")},
+		{name: "database error is anonymous", header: token("active"), dbErr: errors.New("connection refused")},
+		{name: "invalid token is anonymous", header: "Bearer garbage"},
+		{name: "no token is anonymous"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &usersDB{active: map[string]bool{"active": true}, err: tt.dbErr}
+
+			var got string
+			called := false
+			h := AuthOptionalMiddleware(cfg, log, db)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				got, _ = r.Context().Value("userID").(string)
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/ads", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			if !called {
+				t.Fatal("request was not passed on")
+			}
+			if got != tt.want {
+				t.Errorf("userID = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
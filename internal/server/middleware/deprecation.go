@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DeprecationMiddleware marks responses of unversioned aliases as deprecated
+// (RFC 9745, RFC 8594) and links to the same path under successor.
+func DeprecationMiddleware(deprecation, sunset time.Time, successor string) func(next http.Handler) http.Handler {
+	deprecationValue := fmt.Sprintf("@%d", deprecation.Unix())
+	sunsetValue := sunset.UTC().Format(http.TimeFormat)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", deprecationValue)
+			w.Header().Set("Sunset", sunsetValue)
+			w.Header().Add("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, successor, r.URL.Path))
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
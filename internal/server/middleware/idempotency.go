@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"vk-internship/internal/config"
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+	"vk-internship/internal/logger"
+	"vk-internship/internal/server/problem"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// replayedHeaders are the response headers stored with the key and sent
+// again on replay.
+var replayedHeaders = []string{"Content-Type", "Location"}
+
+// IdempotencyMiddleware makes POST requests with an Idempotency-Key header
+// safe to retry. The key is scoped to the user, so it must run after the auth
+// middleware. The first request is executed and its response stored; retries
+// with the same body get the stored response, retries with a different body
+// get 422 and retries while the first request is still running get 409.
+func IdempotencyMiddleware(cfg *config.IdempotencyConfig, db database.Database, log logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			log := log.WithContext(r.Context())
+
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			userID, ok := r.Context().Value("userID").(string)
+			if !ok || userID == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) > maxIdempotencyKeyLength {
+				problem.Write(w, r, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, cfg.MaxBodyBytes))
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					problem.Write(w, r, http.StatusRequestEntityTooLarge, "Request body too large")
+					return
+				}
+				problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			fingerprint := requestFingerprint(r, body)
+
+			record, acquired, err := db.AcquireIdempotencyKey(r.Context(), userID, key, fingerprint, cfg.LockTimeout, cfg.TTL)
+			if err != nil {
+				log.Error(err, "failed to acquire idempotency key")
+				problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+				return
+			}
+
+			if !acquired {
+				switch {
+				case record.Fingerprint != fingerprint:
+					log.Warnf("idempotency key reused with a different request", map[string]interface{}{"user_id": userID, "key": key})
+					problem.Write(w, r, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request")
+				case record.Status != model.IdempotencyCompleted:
+					w.Header().Set("Retry-After", strconv.Itoa(1))
+					problem.Write(w, r, http.StatusConflict, "A request with this Idempotency-Key is already in progress")
+				default:
+					replayResponse(w, record, log)
+				}
+				return
+			}
+
+			completed := false
+			defer func() {
+				if completed {
+					return
+				}
+
+				// the request failed or panicked, let the client retry
+				if err := db.ReleaseIdempotencyKey(context.Background(), userID, key); err != nil {
+					log.Error(err, "failed to release idempotency key")
+				}
+			}()
+
+			var buf bytes.Buffer
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&buf)
+
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			// server errors are not final, the retry should run the request again
+			if status >= http.StatusInternalServerError {
+				return
+			}
+
+			headers := make(map[string][]string)
+			for _, name := range replayedHeaders {
+				if values := w.Header().Values(name); len(values) > 0 {
+					headers[name] = values
+				}
+			}
+
+			if err := db.CompleteIdempotencyKey(context.Background(), userID, key, status, headers, buf.Bytes()); err != nil {
+				log.Error(err, "failed to store idempotent response")
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{'\n'})
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func replayResponse(w http.ResponseWriter, record *model.IdempotencyRecord, log logger.Logger) {
+	for name, values := range record.ResponseHeaders {
+		for _, v := range values {
+			w.Header().Add(name, v)
+		}
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+
+	status := http.StatusOK
+	if record.ResponseStatus != nil {
+		status = *record.ResponseStatus
+	}
+
+	w.WriteHeader(status)
+	if _, err := w.Write(record.ResponseBody); err != nil {
+		log.Error(err, "failed to write replayed response")
+	}
+}
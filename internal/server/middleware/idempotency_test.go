@@ -0,0 +1,213 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"vk-internship/internal/config"
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+	zerologger "vk-internship/internal/logger/zerolog"
+)
+
+// idempotencyDB keeps idempotency keys in memory, the other methods panic
+// through the nil embedded interface.
+type idempotencyDB struct {
+	database.Database
+
+	mu      sync.Mutex
+	records map[string]*model.IdempotencyRecord
+}
+
+func newIdempotencyDB() *idempotencyDB {
+	return &idempotencyDB{records: make(map[string]*model.IdempotencyRecord)}
+}
+
+func (db *idempotencyDB) AcquireIdempotencyKey(_ context.Context, userID, key, fingerprint string, _, _ time.Duration) (*model.IdempotencyRecord, bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if record, ok := db.records[userID+":"+key]; ok {
+		copied := *record
+		return &copied, false, nil
+	}
+
+	db.records[userID+":"+key] = &model.IdempotencyRecord{
+		UserID:      userID,
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      model.IdempotencyInProgress,
+	}
+	return nil, true, nil
+}
+
+func (db *idempotencyDB) CompleteIdempotencyKey(_ context.Context, userID, key string, status int, headers map[string][]string, body []byte) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	record := db.records[userID+":"+key]
+	record.Status = model.IdempotencyCompleted
+	record.ResponseStatus = &status
+	record.ResponseHeaders = headers
+	record.ResponseBody = body
+	return nil
+}
+
+func (db *idempotencyDB) ReleaseIdempotencyKey(_ context.Context, userID, key string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	delete(db.records, userID+":"+key)
+	return nil
+}
+
+type idempotentRequest struct {
+	key  string
+	body string
+}
+
+func newIdempotentRequest(req idempotentRequest) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/ads", strings.NewReader(req.body))
+	if req.key != "" {
+		r.Header.Set(IdempotencyKeyHeader, req.key)
+	}
+	return r.WithContext(context.WithValue(r.Context(), "userID", "user-1"))
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	tests := []struct {
+		name string
+		// status is the status of the wrapped handler for each call
+		status       []int
+		requests     []idempotentRequest
+		wantStatus   int
+		wantReplayed bool
+		wantCalls    int
+	}{
+		{
+			name:         "retry replays the stored response",
+			requests:     []idempotentRequest{{key: "k1", body: `{"caption":"a"}`}, {key: "k1", body: `{"caption":"a"}`}},
+			wantStatus:   http.StatusCreated,
+			wantReplayed: true,
+			wantCalls:    1,
+		},
+		{
+			name:       "same key with another body is rejected",
+			requests:   []idempotentRequest{{key: "k1", body: `{"caption":"a"}`}, {key: "k1", body: `{"caption":"b"}`}},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCalls:  1,
+		},
+		{
+			name:         "client errors are replayed too",
+			status:       []int{http.StatusBadRequest},
+			requests:     []idempotentRequest{{key: "k1", body: `{}`}, {key: "k1", body: `{}`}},
+			wantStatus:   http.StatusBadRequest,
+			wantReplayed: true,
+			wantCalls:    1,
+		},
+		{
+			name:       "server errors release the key",
+			status:     []int{http.StatusInternalServerError, http.StatusCreated},
+			requests:   []idempotentRequest{{key: "k1", body: `{}`}, {key: "k1", body: `{}`}},
+			wantStatus: http.StatusCreated,
+			wantCalls:  2,
+		},
+		{
+			name:       "body over the limit",
+			requests:   []idempotentRequest{{key: "k1", body: strings.Repeat("x", 65)}},
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "requests without a key pass through",
+			requests:   []idempotentRequest{{body: strings.Repeat("x", 65)}, {body: strings.Repeat("x", 65)}},
+			wantStatus: http.StatusCreated,
+			wantCalls:  2,
+		},
+	}
+
+	log := zerologger.NewWriter(&config.LoggerConfig{Level: "disabled"}, io.Discard)
+	cfg := &config.IdempotencyConfig{TTL: time.Hour, LockTimeout: time.Minute, MaxBodyBytes: 64}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			h := IdempotencyMiddleware(cfg, newIdempotencyDB(), log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := http.StatusCreated
+				if calls < len(tt.status) {
+					status = tt.status[calls]
+				}
+				calls++
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Location", "/api/v1/ads/1")
+				w.WriteHeader(status)
+				io.WriteString(w, `{"id":"1"}`)
+			}))
+
+			var rec *httptest.ResponseRecorder
+			for _, req := range tt.requests {
+				rec = httptest.NewRecorder()
+				h.ServeHTTP(rec, newIdempotentRequest(req))
+			}
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if replayed := rec.Header().Get(IdempotentReplayedHeader) == "true"; replayed != tt.wantReplayed {
+				t.Errorf("replayed = %v, want %v", replayed, tt.wantReplayed)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("handler calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantReplayed {
+				if got := rec.Body.String(); got != `{"id":"1"}` {
+					t.Errorf("replayed body = %q", got)
+				}
+				if got := rec.Header().Get("Location"); got != "/api/v1/ads/1" {
+					t.Errorf("replayed Location = %q", got)
+				}
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewareInFlight(t *testing.T) {
+	log := zerologger.NewWriter(&config.LoggerConfig{Level: "disabled"}, io.Discard)
+	cfg := &config.IdempotencyConfig{TTL: time.Hour, LockTimeout: time.Minute, MaxBodyBytes: 64}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h := IdempotencyMiddleware(cfg, newIdempotencyDB(), log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	req := idempotentRequest{key: "k1", body: `{}`}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest(req))
+	}()
+	<-started
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newIdempotentRequest(req))
+
+	close(release)
+	<-done
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Retry-After is not set")
+	}
+}
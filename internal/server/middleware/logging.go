@@ -20,7 +20,7 @@ func LoggingMiddleware(log logger.Logger) func(next http.Handler) http.Handler {
 
 			duration := time.Since(start)
 
-			log.Infof("HTTP request",
+			log.WithContext(r.Context()).Infof("HTTP request",
 				map[string]interface{}{
 					"method":     r.Method,
 					"path":       r.URL.Path,
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"vk-internship/internal/logger"
+	"vk-internship/internal/metrics"
+	"vk-internship/internal/ratelimit"
+	"vk-internship/internal/server/problem"
+)
+
+// RateLimitMiddleware limits requests per identity: the user from the auth
+// middlewares if there is one, otherwise the client IP. With an empty group
+// GET and HEAD requests count as reads and everything else as writes.
+func RateLimitMiddleware(limiter *ratelimit.Limiter, log logger.Logger, group ratelimit.Group) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			log := log.WithContext(r.Context())
+
+			if !limiter.Enabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			g := group
+			if g == "" {
+				g = ratelimit.GroupWrite
+				if r.Method == http.MethodGet || r.Method == http.MethodHead {
+					g = ratelimit.GroupRead
+				}
+			}
+
+			result, err := limiter.Take(r.Context(), g, clientIdentity(r))
+			if err != nil {
+				log.Error(err, "rate limiter failed")
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+			if !result.Allowed {
+				metrics.RateLimited.WithLabelValues(string(g)).Inc()
+
+				w.Header().Set("Retry-After", strconv.Itoa(max(ceilSeconds(result.RetryAfter), 1)))
+				problem.Write(w, r, http.StatusTooManyRequests, "Too many requests")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func clientIdentity(r *http.Request) string {
+	if userID, ok := r.Context().Value("userID").(string); ok && userID != "" {
+		return "user:" + userID
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RealIPMiddleware replaces RemoteAddr with the client address from
+// X-Forwarded-For or X-Real-IP, but only for requests that come from one of
+// the trusted proxies (IPs or CIDRs). Anyone else could put any address in
+// these headers, so their socket address is kept.
+func RealIPMiddleware(trustedProxies []string) func(next http.Handler) http.Handler {
+	trusted := parseProxies(trustedProxies)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(trusted) > 0 && trusted.contains(socketIP(r.RemoteAddr)) {
+				if ip := trusted.clientIP(r); ip != "" {
+					r.RemoteAddr = ip
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type proxies []*net.IPNet
+
+// parseProxies reads IPs and CIDRs, the config validation has already
+// rejected anything else.
+func parseProxies(values []string) proxies {
+	var nets proxies
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+
+		if _, ipNet, err := net.ParseCIDR(value); err == nil {
+			nets = append(nets, ipNet)
+			continue
+		}
+
+		if ip := net.ParseIP(value); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		}
+	}
+
+	return nets
+}
+
+func (p proxies) contains(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	for _, ipNet := range p {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// clientIP walks X-Forwarded-For from the nearest hop and returns the first
+// address that is not a trusted proxy, falling back to X-Real-IP.
+func (p proxies) clientIP(r *http.Request) string {
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				return ""
+			}
+			if !p.contains(ip) {
+				return ip.String()
+			}
+		}
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+
+	return ""
+}
+
+func socketIP(remoteAddr string) net.IP {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	return net.ParseIP(host)
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIPMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		trusted    []string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		want       string
+	}{
+		{
+			name:       "no trusted proxies ignores headers",
+			remoteAddr: "203.0.113.7:5555",
+			forwarded:  "198.51.100.1",
+			realIP:     "198.51.100.2",
+			want:       "203.0.113.7:5555",
+		},
+		{
+			name:       "untrusted peer ignores headers",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "203.0.113.7:5555",
+			forwarded:  "198.51.100.1",
+			want:       "203.0.113.7:5555",
+		},
+		{
+			name:       "trusted peer uses forwarded client",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.1.2.3:5555",
+			forwarded:  "198.51.100.1",
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "spoofed leftmost hop is skipped",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.1.2.3:5555",
+			forwarded:  "1.2.3.4, 198.51.100.1, 10.9.9.9",
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "single trusted IP",
+			trusted:    []string{"192.0.2.10"},
+			remoteAddr: "192.0.2.10:5555",
+			realIP:     "198.51.100.2",
+			want:       "198.51.100.2",
+		},
+		{
+			name:       "garbage header keeps socket address",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.1.2.3:5555",
+			forwarded:  "not-an-ip",
+			want:       "10.1.2.3:5555",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := RealIPMiddleware(tt.trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tt.want {
+				t.Errorf("RemoteAddr = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"vk-internship/internal/logger"
+	"vk-internship/internal/server/problem"
+)
+
+// RecoverMiddleware replaces chi's Recoverer so panics are logged and
+// answered with a problem+json body instead of an empty 500.
+func RecoverMiddleware(log logger.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+
+				log.WithContext(r.Context()).With(map[string]interface{}{
+					"method": r.Method,
+					"path":   r.URL.Path,
+					"stack":  string(debug.Stack()),
+				}).Error(fmt.Errorf("%v", rec), "panic while handling request")
+
+				problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
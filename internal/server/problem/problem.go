@@ -0,0 +1,69 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"vk-internship/internal/utils"
+)
+
+const (
+	ContentType = "application/problem+json"
+
+	TypeDefault    = "about:blank"
+	TypeValidation = "/problems/validation-error"
+)
+
+// Problem представляет описание ошибки в формате RFC 7807
+// @Description Описание ошибки (application/problem+json)
+type Problem struct {
+	Type     string                  `json:"type" example:"about:blank"`
+	Title    string                  `json:"title" example:"Not Found"`
+	Status   int                     `json:"status" example:"404"`
+	Detail   string                  `json:"detail,omitempty" example:"Ad not found"`
+	Instance string                  `json:"instance,omitempty" example:"marketplace/abcdef-000001"`
+	Errors   []utils.ValidationError `json:"errors,omitempty"`
+}
+
+func New(r *http.Request, status int, detail string) *Problem {
+	return &Problem{
+		Type:     TypeDefault,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: middleware.GetReqID(r.Context()),
+	}
+}
+
+// Write renders a problem with the status text as its title.
+func Write(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	Render(w, New(r, status, detail))
+}
+
+// Validation renders field-level validation errors as a 400 problem.
+func Validation(w http.ResponseWriter, r *http.Request, errs []utils.ValidationError) {
+	p := New(r, http.StatusBadRequest, "Request validation failed")
+	p.Type = TypeValidation
+	p.Title = "Validation error"
+	p.Errors = errs
+
+	Render(w, p)
+}
+
+func Render(w http.ResponseWriter, p *Problem) {
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// NotFound and MethodNotAllowed replace the router's plain text defaults.
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	Write(w, r, http.StatusNotFound, "Resource not found")
+}
+
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	Write(w, r, http.StatusMethodNotAllowed, "Method not allowed")
+}
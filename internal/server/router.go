@@ -5,47 +5,197 @@ import (
 	"github.com/swaggo/http-swagger"
 
 	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"vk-internship/internal/broker"
 	"vk-internship/internal/cache"
 	"vk-internship/internal/config"
 	"vk-internship/internal/database"
+	"vk-internship/internal/health"
+	"vk-internship/internal/importer"
 	"vk-internship/internal/logger"
+	"vk-internship/internal/matcher"
+	"vk-internship/internal/metrics"
+	"vk-internship/internal/ratelimit"
 	"vk-internship/internal/server/handler"
 	"vk-internship/internal/server/middleware"
+	"vk-internship/internal/server/problem"
 )
 
+const apiV1Prefix = "/api/v1"
+
+// api holds the dependencies of the versioned routes, so every version (and
+// the deprecated unversioned aliases) mounts the same handlers.
+type api struct {
+	cfg            *config.ServerConfig
+	statscfg       *config.StatsConfig
+	brokercfg      *config.BrokerConfig
+	idempotencycfg *config.IdempotencyConfig
+	importcfg      *config.ImportConfig
+	offercfg       *config.OfferConfig
+	moderationcfg  *config.ModerationConfig
+	webhookcfg     *config.WebhookConfig
+	log            logger.Logger
+	db             database.Database
+	cache          cache.Cache
+	events         *broker.Broker
+	searches       *matcher.Matcher
+	limiter        *ratelimit.Limiter
+	imports        *importer.Importer
+}
+
 // @title VK Internship API
 // @version 1.0
-// @description API для управления объявлениями
+// @description API для управления объявлениями. Все маршруты доступны под /api/v1; маршруты без префикса устарели и отвечают с заголовками Deprecation и Sunset
 // @host localhost:8080
 // @BasePath /
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token
-func NewRouter(cfg *config.ServerConfig, log logger.Logger, db database.Database, cache cache.Cache) *chi.Mux {
+func NewRouter(cfg *config.ServerConfig, statscfg *config.StatsConfig, brokercfg *config.BrokerConfig, idempotencycfg *config.IdempotencyConfig, importcfg *config.ImportConfig, offercfg *config.OfferConfig, moderationcfg *config.ModerationConfig, webhookcfg *config.WebhookConfig, log logger.Logger, db database.Database, cache cache.Cache, events *broker.Broker, searches *matcher.Matcher, checker *health.Checker, limiter *ratelimit.Limiter, imports *importer.Importer) *chi.Mux {
 	router := chi.NewMux()
 	router.Use(chimiddleware.RequestID)
-	router.Use(chimiddleware.RealIP)
-	router.Use(chimiddleware.Recoverer)
+	router.Use(middleware.RealIPMiddleware(cfg.TrustedProxies))
+	router.Use(middleware.TracingMiddleware)
+	router.Use(middleware.RecoverMiddleware(log))
 	router.Use(middleware.LoggingMiddleware(log))
+	router.Use(middleware.MetricsMiddleware)
+
+	router.NotFound(problem.NotFound)
+	router.MethodNotAllowed(problem.MethodNotAllowed)
 
 	router.Get("/swagger/*", httpSwagger.Handler(
 		httpSwagger.URL("/swagger/doc.json"),
 	))
 
 	router.Get("/", handler.Home)
-	router.With(middleware.AuthOptionalMiddleware(cfg, log)).Post("/register", handler.RegistrationHandler(cfg, log, db))
-	router.With(middleware.AuthOptionalMiddleware(cfg, log)).Post("/login", handler.LoginHandler(cfg, log, db))
+	router.Get("/metrics", metrics.Handler().ServeHTTP)
+	router.Get("/healthz", handler.LivenessHandler(log))
+	router.Get("/readyz", handler.ReadinessHandler(log, checker))
 
-	router.With(middleware.AuthOptionalMiddleware(cfg, log)).Get("/ads", handler.GetAdsHandler(log, db))
-	router.With(middleware.AuthOptionalMiddleware(cfg, log)).Get("/ads/{id}", handler.GetAdHandler(log, db))
+	a := &api{
+		cfg:            cfg,
+		statscfg:       statscfg,
+		brokercfg:      brokercfg,
+		idempotencycfg: idempotencycfg,
+		importcfg:      importcfg,
+		offercfg:       offercfg,
+		moderationcfg:  moderationcfg,
+		webhookcfg:     webhookcfg,
+		log:            log,
+		db:             db,
+		cache:          cache,
+		events:         events,
+		searches:       searches,
+		limiter:        limiter,
+		imports:        imports,
+	}
+
+	router.Route(apiV1Prefix, func(r chi.Router) {
+		a.mount(r, handler.V1)
+	})
 
 	router.Group(func(r chi.Router) {
-		r.Use(middleware.AuthRequiredMiddleware(cfg, log))
-		r.Post("/ads", handler.CreateAdHandler(log, db, cache))
-		r.Delete("/ads/{id}", handler.DeleteAdHandler(log, db))
-		r.Put("/ads/{id}", handler.UpdateAdHandler(log, db))
+		r.Use(middleware.DeprecationMiddleware(cfg.LegacyDeprecation, cfg.LegacySunset, apiV1Prefix))
+		a.mount(r, handler.V1)
 	})
 
 	return router
 }
+
+func (a *api) mount(router chi.Router, mapper handler.ResponseMapper) {
+	cfg, log, db, limiter := a.cfg, a.log, a.db, a.limiter
+
+	router.With(middleware.AuthOptionalMiddleware(cfg, log, db), middleware.RateLimitMiddleware(limiter, log, ratelimit.GroupAuth)).Post("/register", handler.RegistrationHandler(cfg, log, db))
+	router.With(middleware.AuthOptionalMiddleware(cfg, log, db), middleware.RateLimitMiddleware(limiter, log, ratelimit.GroupAuth)).Post("/login", handler.LoginHandler(cfg, log, db))
+
+	router.With(middleware.AuthOptionalMiddleware(cfg, log, db), middleware.RateLimitMiddleware(limiter, log, ratelimit.GroupRead)).Get("/ads", handler.GetAdsHandler(log, db, mapper))
+	router.With(middleware.AuthOptionalMiddleware(cfg, log, db), middleware.RateLimitMiddleware(limiter, log, ratelimit.GroupRead)).Get("/ads/export", handler.ExportAdsHandler(log, db, mapper))
+	router.With(middleware.AuthOptionalMiddleware(cfg, log, db), middleware.RateLimitMiddleware(limiter, log, ratelimit.GroupRead)).Get("/ads/stream", handler.StreamAdsHandler(a.brokercfg, log, a.events, mapper))
+	router.With(middleware.AuthOptionalMiddleware(cfg, log, db), middleware.RateLimitMiddleware(limiter, log, ratelimit.GroupRead)).Get("/ads/{id}", handler.GetAdHandler(a.statscfg, log, db, a.cache, mapper))
+
+	router.With(middleware.AuthOptionalMiddleware(cfg, log, db), middleware.RateLimitMiddleware(limiter, log, ratelimit.GroupRead)).Get("/users/{username}", handler.GetUserProfileHandler(log, db))
+	router.With(middleware.AuthOptionalMiddleware(cfg, log, db), middleware.RateLimitMiddleware(limiter, log, ratelimit.GroupRead)).Get("/users/{username}/ads", handler.GetUserAdsHandler(log, db, mapper))
+	router.With(middleware.AuthOptionalMiddleware(cfg, log, db), middleware.RateLimitMiddleware(limiter, log, ratelimit.GroupRead)).Get("/users/{username}/reviews", handler.GetUserReviewsHandler(log, db))
+
+	router.Group(func(r chi.Router) {
+		r.Use(middleware.AuthRequiredMiddleware(cfg, log))
+		r.Use(middleware.ActiveUserMiddleware(log, db))
+		r.Use(middleware.RateLimitMiddleware(limiter, log, ""))
+		r.Use(middleware.IdempotencyMiddleware(a.idempotencycfg, db, log))
+		r.Post("/ads", handler.CreateAdHandler(log, db, a.cache, a.events, a.searches, mapper))
+		r.Get("/ads/import/{id}", handler.GetImportJobHandler(log, db))
+		r.Delete("/ads/{id}", handler.DeleteAdHandler(log, db, a.events))
+		r.Put("/ads/{id}", handler.UpdateAdHandler(log, db, a.events, a.searches, mapper))
+		r.Get("/ads/{id}/stats", handler.GetAdStatsHandler(a.statscfg, log, db))
+
+		r.Post("/ads/{id}/messages", handler.StartConversationHandler(log, db))
+		r.Get("/conversations", handler.GetConversationsHandler(log, db))
+		r.Get("/conversations/unread", handler.GetUnreadCountHandler(log, db))
+		r.Get("/conversations/{id}/messages", handler.GetMessagesHandler(log, db))
+		r.Post("/conversations/{id}/messages", handler.SendMessageHandler(log, db))
+		r.Post("/conversations/{id}/read", handler.MarkConversationReadHandler(log, db))
+
+		r.Post("/ads/{id}/reviews", handler.CreateReviewHandler(log, db))
+		r.Put("/reviews/{id}/reply", handler.ReplyReviewHandler(log, db))
+
+		r.Post("/ads/{id}/offers", handler.CreateOfferHandler(a.offercfg, log, db))
+		r.Get("/me/offers", handler.GetOffersHandler(log, db))
+		r.Get("/offers/{id}", handler.GetOfferHandler(log, db))
+		r.Post("/offers/{id}/accept", handler.AcceptOfferHandler(log, db))
+		r.Post("/offers/{id}/reject", handler.RejectOfferHandler(log, db))
+		r.Post("/offers/{id}/counter", handler.CounterOfferHandler(a.offercfg, log, db))
+
+		r.Post("/ads/{id}/report", handler.ReportAdHandler(a.moderationcfg, log, db, a.events))
+
+		r.Post("/users/{username}/block", handler.BlockUserHandler(log, db))
+		r.Delete("/users/{username}/block", handler.UnblockUserHandler(log, db))
+
+		r.Get("/me", handler.GetMeHandler(log, db))
+		r.Put("/me/profile", handler.UpdateProfileHandler(log, db))
+		r.Get("/me/ads", handler.GetMyAdsHandler(log, db, mapper))
+		r.Get("/me/ads/export", handler.ExportMyAdsHandler(log, db, mapper))
+
+		r.Get("/me/searches", handler.GetSavedSearchesHandler(log, db))
+		r.Post("/me/searches", handler.CreateSavedSearchHandler(log, db))
+		r.Get("/me/searches/{id}", handler.GetSavedSearchHandler(log, db))
+		r.Put("/me/searches/{id}", handler.UpdateSavedSearchHandler(log, db))
+		r.Delete("/me/searches/{id}", handler.DeleteSavedSearchHandler(log, db))
+
+		r.Get("/me/notifications", handler.GetNotificationsHandler(log, db))
+		r.Post("/me/notifications/read", handler.MarkAllNotificationsReadHandler(log, db))
+		r.Post("/me/notifications/{id}/read", handler.MarkNotificationReadHandler(log, db))
+
+		r.Get("/webhooks", handler.GetWebhooksHandler(log, db))
+		r.Post("/webhooks", handler.CreateWebhookHandler(a.webhookcfg, log, db))
+		r.Delete("/webhooks/{id}", handler.DeleteWebhookHandler(log, db))
+		r.Get("/webhooks/dead-letters", handler.GetDeadWebhookDeliveriesHandler(log, db))
+		r.Post("/webhooks/deliveries/{id}/retry", handler.RetryWebhookDeliveryHandler(log, db))
+	})
+
+	// the idempotency middleware reads the whole body, so imports need a cap
+	// of their own
+	importIdempotency := *a.idempotencycfg
+	importIdempotency.MaxBodyBytes = max(importIdempotency.MaxBodyBytes, a.importcfg.MaxBytes)
+
+	router.Group(func(r chi.Router) {
+		r.Use(middleware.AuthRequiredMiddleware(cfg, log))
+		r.Use(middleware.ActiveUserMiddleware(log, db))
+		r.Use(middleware.RateLimitMiddleware(limiter, log, ""))
+		r.Use(middleware.IdempotencyMiddleware(&importIdempotency, db, log))
+		r.Post("/ads/import", handler.ImportAdsHandler(a.importcfg, log, db, a.imports))
+	})
+
+	router.Group(func(r chi.Router) {
+		r.Use(middleware.AuthRequiredMiddleware(cfg, log))
+		r.Use(middleware.ActiveUserMiddleware(log, db))
+		r.Use(middleware.ModeratorMiddleware(log, db))
+		r.Use(middleware.RateLimitMiddleware(limiter, log, ""))
+		r.Use(middleware.IdempotencyMiddleware(a.idempotencycfg, db, log))
+		r.Get("/moderation/cases", handler.GetModerationCasesHandler(log, db))
+		r.Get("/moderation/cases/{id}", handler.GetModerationCaseHandler(log, db))
+		r.Post("/moderation/cases/{id}/claim", handler.ClaimModerationCaseHandler(log, db))
+		r.Post("/moderation/cases/{id}/resolve", handler.ResolveModerationCaseHandler(log, db, a.events))
+		r.Get("/moderation/actions", handler.GetModerationActionsHandler(log, db))
+		r.Post("/moderation/reviews/{id}/remove", handler.RemoveReviewHandler(log, db))
+	})
+}
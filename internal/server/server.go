@@ -41,6 +41,10 @@ func (s *Server) Start() error {
 	return s.server.ListenAndServe()
 }
 
+func (s *Server) RegisterOnShutdown(f func()) {
+	s.server.RegisterOnShutdown(f)
+}
+
 func (s *Server) Stop(ctx context.Context) error {
 	s.log.Info("shutting down server")
 	return s.server.Shutdown(ctx)
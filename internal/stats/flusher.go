@@ -0,0 +1,71 @@
+package stats
+
+import (
+	"context"
+	"time"
+
+	"vk-internship/internal/cache"
+	"vk-internship/internal/config"
+	"vk-internship/internal/database"
+	"vk-internship/internal/logger"
+)
+
+type Flusher struct {
+	cache    cache.Cache
+	db       database.Database
+	interval time.Duration
+	log      logger.Logger
+}
+
+func NewFlusher(cfg *config.StatsConfig, cache cache.Cache, db database.Database, log logger.Logger) *Flusher {
+	return &Flusher{
+		cache:    cache,
+		db:       db,
+		interval: cfg.FlushInterval,
+		log:      log.Component("stats"),
+	}
+}
+
+func (f *Flusher) Run(ctx context.Context) {
+	f.log.Debugf("starting views flusher", map[string]interface{}{"interval": f.interval.String()})
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), f.interval)
+			f.flush(flushCtx)
+			cancel()
+
+			f.log.Info("views flusher stopped")
+			return
+		case <-ticker.C:
+			f.flush(ctx)
+		}
+	}
+}
+
+func (f *Flusher) flush(ctx context.Context) {
+	views, err := f.cache.PopPendingViews(ctx)
+	if err != nil {
+		f.log.Warnf("failed to pop pending views", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	if len(views) == 0 {
+		return
+	}
+
+	if err := f.db.AddAdViews(ctx, views); err != nil {
+		f.log.Error(err, "failed to flush views")
+
+		if err := f.cache.PushPendingViews(context.Background(), views); err != nil {
+			f.log.Error(err, "failed to return views to cache, views are lost")
+		}
+		return
+	}
+
+	f.log.Debugf("flushed views", map[string]interface{}{"count": len(views)})
+}
@@ -71,6 +71,14 @@ func generateErrorMessage(field, tag, param string) string {
 		return fmt.Sprintf("%s must be at most %s characters", field, param)
 	case "alphanum":
 		return fmt.Sprintf("%s must contain only letters and numbers", field)
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, param)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, param)
+	case "http_url":
+		return fmt.Sprintf("%s must be a valid http or https URL", field)
+	case "e164":
+		return fmt.Sprintf("%s must be a phone number in international format, e.g. +79991234567", field)
 	default:
 		return fmt.Sprintf("%s is invalid", field)
 	}
@@ -0,0 +1,183 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"vk-internship/internal/config"
+	"vk-internship/internal/database"
+	"vk-internship/internal/database/model"
+	"vk-internship/internal/logger"
+)
+
+const userAgent = "vk-internship-webhooks/1.0"
+
+type Dispatcher struct {
+	cfg    *config.WebhookConfig
+	db     database.Database
+	client *http.Client
+	log    logger.Logger
+}
+
+func New(cfg *config.WebhookConfig, db database.Database, log logger.Logger) *Dispatcher {
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivateTargets {
+		dialer.Control = dialControl
+	}
+
+	// no proxy, the dialer has to see the subscriber's address
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Dispatcher{
+		cfg: cfg,
+		db:  db,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   cfg.Timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		log: log.Component("webhook"),
+	}
+}
+
+func (d *Dispatcher) Run(ctx context.Context) {
+	d.log.Debugf("starting webhook dispatcher", map[string]interface{}{
+		"interval": d.cfg.PollInterval.String(),
+		"workers":  d.cfg.Workers,
+	})
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.log.Info("webhook dispatcher stopped")
+			return
+		case <-ticker.C:
+			d.dispatch(ctx)
+		}
+	}
+}
+
+// dispatch claims a batch of due deliveries and sends them with a bounded
+// number of workers. Claimed rows stay leased until the batch is done, so an
+// interrupted batch is picked up again after the lease expires.
+func (d *Dispatcher) dispatch(ctx context.Context) {
+	deliveries, err := d.db.ClaimWebhookDeliveries(ctx, d.cfg.BatchSize, d.cfg.Lease)
+	if err != nil {
+		d.log.Warnf("failed to claim webhook deliveries", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	if len(deliveries) == 0 {
+		return
+	}
+
+	sem := make(chan struct{}, d.cfg.Workers)
+	var wg sync.WaitGroup
+
+	for _, delivery := range deliveries {
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func(delivery *model.WebhookDelivery) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			d.deliver(ctx, delivery)
+		}(delivery)
+	}
+
+	wg.Wait()
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery *model.WebhookDelivery) {
+	statusCode, err := d.send(ctx, delivery)
+
+	// results are stored even if the dispatcher is stopping
+	storeCtx, cancel := context.WithTimeout(context.Background(), d.cfg.Timeout)
+	defer cancel()
+
+	if err == nil {
+		if err := d.db.MarkWebhookDelivered(storeCtx, delivery.ID, statusCode); err != nil {
+			d.log.Error(err, "failed to mark webhook delivered")
+		}
+		return
+	}
+
+	var code *int
+	if statusCode != 0 {
+		code = &statusCode
+	}
+
+	dead := delivery.Attempts >= d.cfg.MaxAttempts
+	nextAttemptAt := time.Now().Add(d.backoff(delivery.Attempts))
+
+	d.log.Warnf("webhook delivery failed", map[string]interface{}{
+		"delivery_id": delivery.ID,
+		"attempt":     delivery.Attempts,
+		"dead":        dead,
+		"error":       err.Error(),
+	})
+
+	if err := d.db.MarkWebhookFailed(storeCtx, delivery.ID, code, err.Error(), nextAttemptAt, dead); err != nil {
+		d.log.Error(err, "failed to mark webhook failed")
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery *model.WebhookDelivery) (int, error) {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderID, delivery.ID)
+	req.Header.Set(HeaderEventID, delivery.EventID)
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.cfg.BackoffBase
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= d.cfg.BackoffMax {
+			return d.cfg.BackoffMax
+		}
+	}
+
+	return delay
+}
@@ -0,0 +1,69 @@
+package webhook
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	const (
+		secret    = "whsec_test"
+		timestamp = int64(1700000000)
+	)
+	body := []byte(`{"type":"ad.created"}`)
+	valid := Sign(secret, timestamp, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{name: "valid signature", secret: secret, timestamp: timestamp, body: body, signature: valid, want: true},
+		{name: "wrong secret", secret: "other", timestamp: timestamp, body: body, signature: valid},
+		{name: "replayed with another timestamp", secret: secret, timestamp: timestamp + 1, body: body, signature: valid},
+		{name: "tampered body", secret: secret, timestamp: timestamp, body: []byte(`{"type":"ad.deleted"}`), signature: valid},
+		{name: "missing prefix", secret: secret, timestamp: timestamp, body: body, signature: strings.TrimPrefix(valid, signaturePrefix)},
+		{name: "uppercase hex", secret: secret, timestamp: timestamp, body: body, signature: signaturePrefix + strings.ToUpper(strings.TrimPrefix(valid, signaturePrefix))},
+		{name: "empty signature", secret: secret, timestamp: timestamp, body: body},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.timestamp, tt.body, tt.signature); got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForbiddenIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "127.0.0.1", want: true},
+		{ip: "::1", want: true},
+		{ip: "10.1.2.3", want: true},
+		{ip: "172.16.0.1", want: true},
+		{ip: "192.168.1.1", want: true},
+		{ip: "169.254.169.254", want: true},
+		{ip: "fe80::1", want: true},
+		{ip: "fd00::1", want: true},
+		{ip: "0.0.0.0", want: true},
+		{ip: "::", want: true},
+		{ip: "93.184.216.34", want: false},
+		{ip: "2606:2800:220:1:248:1893:25c8:1946", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := forbiddenIP(net.ParseIP(tt.ip)); got != tt.want {
+				t.Errorf("forbiddenIP(%s) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
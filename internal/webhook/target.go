@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+)
+
+// ErrForbiddenTarget is returned for webhook URLs that resolve to loopback,
+// private, link-local or unspecified addresses.
+var ErrForbiddenTarget = errors.New("webhook url must not point to a local or private address")
+
+// forbiddenIP reports whether deliveries to the address could reach the host
+// itself or the internal network.
+func forbiddenIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsUnspecified()
+}
+
+// CheckTarget resolves the host of a webhook URL and rejects it if any of its
+// addresses is forbidden. The dispatcher checks the address again when it
+// dials, since DNS answers can change after the check.
+func CheckTarget(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook url: %w", err)
+	}
+
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if forbiddenIP(ip) {
+			return ErrForbiddenTarget
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve webhook host %q: %w", host, err)
+	}
+
+	for _, addr := range addrs {
+		if forbiddenIP(addr.IP) {
+			return ErrForbiddenTarget
+		}
+	}
+
+	return nil
+}
+
+// dialControl refuses connections to forbidden addresses. It runs after name
+// resolution, so it sees the address actually dialed.
+func dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || forbiddenIP(ip) {
+		return fmt.Errorf("dial %s: %w", address, ErrForbiddenTarget)
+	}
+
+	return nil
+}
@@ -0,0 +1,8 @@
+// Package migrations embeds the SQL migrations so the binary can apply them
+// itself (see the migrate subcommand and AUTO_MIGRATE).
+package migrations
+
+import "embed"
+
+//go:embed postgres/*.sql
+var Postgres embed.FS
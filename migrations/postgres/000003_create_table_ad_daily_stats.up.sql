@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS ad_daily_stats (
+  ad_id UUID NOT NULL,
+  day DATE NOT NULL,
+  views BIGINT NOT NULL DEFAULT 0 CHECK(views >= 0),
+  favorites BIGINT NOT NULL DEFAULT 0 CHECK(favorites >= 0),
+  contact_clicks BIGINT NOT NULL DEFAULT 0 CHECK(contact_clicks >= 0),
+  PRIMARY KEY (ad_id, day),
+  FOREIGN KEY (ad_id) REFERENCES advertisements(id) ON DELETE CASCADE
+);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS conversations (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  ad_id UUID,
+  buyer_id UUID NOT NULL,
+  seller_id UUID NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  last_message_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (ad_id, buyer_id),
+  CHECK(buyer_id <> seller_id),
+  FOREIGN KEY (ad_id) REFERENCES advertisements(id) ON DELETE SET NULL,
+  FOREIGN KEY (buyer_id) REFERENCES users(id) ON DELETE CASCADE,
+  FOREIGN KEY (seller_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS conversations_buyer_id_idx ON conversations (buyer_id, last_message_at DESC);
+CREATE INDEX IF NOT EXISTS conversations_seller_id_idx ON conversations (seller_id, last_message_at DESC);
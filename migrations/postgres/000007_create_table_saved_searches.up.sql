@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS saved_searches (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL,
+  name VARCHAR(64) NOT NULL CHECK(length(name) BETWEEN 1 AND 64),
+  query VARCHAR(128) NOT NULL DEFAULT '',
+  min_price INTEGER CHECK(min_price >= 0),
+  max_price INTEGER CHECK(max_price >= 0),
+  sort_by VARCHAR(16) NOT NULL DEFAULT 'created_at' CHECK(sort_by IN ('created_at', 'price')),
+  sort_order VARCHAR(4) NOT NULL DEFAULT 'DESC' CHECK(sort_order IN ('ASC', 'DESC')),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CHECK(min_price IS NULL OR max_price IS NULL OR min_price <= max_price),
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS saved_searches_user_id_idx ON saved_searches (user_id);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS import_jobs (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL,
+  format VARCHAR(16) NOT NULL CHECK(format IN ('csv', 'ndjson')),
+  mode VARCHAR(16) NOT NULL CHECK(mode IN ('atomic', 'partial')),
+  status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK(status IN ('pending', 'running', 'completed', 'failed')),
+  total_rows INTEGER NOT NULL,
+  created_rows INTEGER NOT NULL DEFAULT 0,
+  failed_rows INTEGER NOT NULL DEFAULT 0,
+  rows JSONB NOT NULL,
+  error TEXT,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  lease_until TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  finished_at TIMESTAMPTZ,
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS import_jobs_queue_idx ON import_jobs (created_at) WHERE status IN ('pending', 'running');
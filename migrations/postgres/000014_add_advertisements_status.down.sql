@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS advertisements_author_id_idx;
+ALTER TABLE advertisements DROP COLUMN IF EXISTS status;
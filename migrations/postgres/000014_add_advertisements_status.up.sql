@@ -0,0 +1,3 @@
+ALTER TABLE advertisements ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'draft', 'inactive'));
+
+CREATE INDEX IF NOT EXISTS advertisements_author_id_idx ON advertisements (author_id, created_at DESC);
@@ -0,0 +1,7 @@
+ALTER TABLE users
+  DROP COLUMN IF EXISTS phone_visibility,
+  DROP COLUMN IF EXISTS phone,
+  DROP COLUMN IF EXISTS city,
+  DROP COLUMN IF EXISTS avatar_url,
+  DROP COLUMN IF EXISTS bio,
+  DROP COLUMN IF EXISTS display_name;
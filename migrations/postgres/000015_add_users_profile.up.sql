@@ -0,0 +1,7 @@
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS display_name VARCHAR(64) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS bio VARCHAR(512) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS avatar_url VARCHAR(1024) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS city VARCHAR(64) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS phone VARCHAR(16) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS phone_visibility VARCHAR(16) NOT NULL DEFAULT 'hidden' CHECK (phone_visibility IN ('hidden', 'registered', 'public'));
@@ -0,0 +1,5 @@
+ALTER TABLE users
+  DROP COLUMN IF EXISTS rating_sum,
+  DROP COLUMN IF EXISTS reviews_count;
+
+DROP TABLE IF EXISTS reviews;
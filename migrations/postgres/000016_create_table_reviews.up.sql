@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS reviews (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  ad_id UUID,
+  seller_id UUID NOT NULL,
+  author_id UUID NOT NULL,
+  rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+  body VARCHAR(2000) NOT NULL DEFAULT '',
+  reply VARCHAR(2000),
+  replied_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  removed_at TIMESTAMPTZ,
+  removed_reason VARCHAR(256),
+  UNIQUE (ad_id, author_id),
+  CHECK (seller_id <> author_id),
+  FOREIGN KEY (ad_id) REFERENCES advertisements(id) ON DELETE SET NULL,
+  FOREIGN KEY (seller_id) REFERENCES users(id) ON DELETE CASCADE,
+  FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS reviews_seller_id_idx ON reviews (seller_id, created_at DESC) WHERE removed_at IS NULL;
+
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS reviews_count INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS rating_sum INTEGER NOT NULL DEFAULT 0;
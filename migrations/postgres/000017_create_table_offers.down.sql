@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS offers;
+
+UPDATE advertisements SET status = 'inactive' WHERE status = 'sold';
+ALTER TABLE advertisements DROP CONSTRAINT IF EXISTS advertisements_status_check;
+ALTER TABLE advertisements ADD CONSTRAINT advertisements_status_check CHECK (status IN ('active', 'draft', 'inactive'));
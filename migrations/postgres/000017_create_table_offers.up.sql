@@ -0,0 +1,24 @@
+ALTER TABLE advertisements DROP CONSTRAINT IF EXISTS advertisements_status_check;
+ALTER TABLE advertisements ADD CONSTRAINT advertisements_status_check CHECK (status IN ('active', 'draft', 'inactive', 'sold'));
+
+CREATE TABLE IF NOT EXISTS offers (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  ad_id UUID,
+  buyer_id UUID NOT NULL,
+  seller_id UUID NOT NULL,
+  amount INTEGER NOT NULL CHECK (amount > 0),
+  counter_amount INTEGER CHECK (counter_amount > 0),
+  status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'countered', 'accepted', 'rejected', 'expired')),
+  expires_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CHECK (buyer_id <> seller_id),
+  FOREIGN KEY (ad_id) REFERENCES advertisements(id) ON DELETE SET NULL,
+  FOREIGN KEY (buyer_id) REFERENCES users(id) ON DELETE CASCADE,
+  FOREIGN KEY (seller_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS offers_open_idx ON offers (ad_id, buyer_id) WHERE status IN ('pending', 'countered');
+CREATE INDEX IF NOT EXISTS offers_expires_at_idx ON offers (expires_at) WHERE status IN ('pending', 'countered');
+CREATE INDEX IF NOT EXISTS offers_buyer_id_idx ON offers (buyer_id, updated_at DESC);
+CREATE INDEX IF NOT EXISTS offers_seller_id_idx ON offers (seller_id, updated_at DESC);
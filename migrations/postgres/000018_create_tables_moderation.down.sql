@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS moderation_actions;
+DROP TABLE IF EXISTS ad_reports;
+DROP TABLE IF EXISTS moderation_cases;
+
+UPDATE advertisements SET status = 'inactive' WHERE status = 'hidden';
+ALTER TABLE advertisements DROP CONSTRAINT IF EXISTS advertisements_status_check;
+ALTER TABLE advertisements ADD CONSTRAINT advertisements_status_check CHECK (status IN ('active', 'draft', 'inactive', 'sold'));
+
+ALTER TABLE users DROP COLUMN IF EXISTS moderator;
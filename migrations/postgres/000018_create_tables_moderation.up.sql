@@ -0,0 +1,56 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS moderator BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE advertisements DROP CONSTRAINT IF EXISTS advertisements_status_check;
+ALTER TABLE advertisements ADD CONSTRAINT advertisements_status_check CHECK (status IN ('active', 'draft', 'inactive', 'sold', 'hidden'));
+
+CREATE TABLE IF NOT EXISTS moderation_cases (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  ad_id UUID,
+  status VARCHAR(16) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'claimed', 'resolved')),
+  reports_count INTEGER NOT NULL DEFAULT 0,
+  auto_hidden BOOLEAN NOT NULL DEFAULT FALSE,
+  moderator_id UUID,
+  claimed_at TIMESTAMPTZ,
+  resolution VARCHAR(16) CHECK (resolution IN ('hide', 'delete', 'dismiss')),
+  note VARCHAR(1024),
+  resolved_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (ad_id) REFERENCES advertisements(id) ON DELETE SET NULL,
+  FOREIGN KEY (moderator_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS moderation_cases_ad_id_idx ON moderation_cases (ad_id) WHERE status <> 'resolved';
+CREATE INDEX IF NOT EXISTS moderation_cases_status_idx ON moderation_cases (status, reports_count DESC, created_at);
+
+CREATE TABLE IF NOT EXISTS ad_reports (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  case_id UUID NOT NULL,
+  ad_id UUID,
+  reporter_id UUID NOT NULL,
+  reason VARCHAR(32) NOT NULL CHECK (reason IN ('scam', 'prohibited', 'spam', 'offensive', 'duplicate', 'other')),
+  comment VARCHAR(1024) NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (case_id, reporter_id),
+  FOREIGN KEY (case_id) REFERENCES moderation_cases(id) ON DELETE CASCADE,
+  FOREIGN KEY (ad_id) REFERENCES advertisements(id) ON DELETE SET NULL,
+  FOREIGN KEY (reporter_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS moderation_actions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  case_id UUID,
+  ad_id UUID,
+  review_id UUID,
+  moderator_id UUID,
+  action VARCHAR(32) NOT NULL,
+  note VARCHAR(1024) NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (case_id) REFERENCES moderation_cases(id) ON DELETE CASCADE,
+  FOREIGN KEY (ad_id) REFERENCES advertisements(id) ON DELETE SET NULL,
+  FOREIGN KEY (review_id) REFERENCES reviews(id) ON DELETE SET NULL,
+  FOREIGN KEY (moderator_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS moderation_actions_created_at_idx ON moderation_actions (created_at DESC);
+CREATE INDEX IF NOT EXISTS moderation_actions_case_id_idx ON moderation_actions (case_id, created_at);